@@ -0,0 +1,27 @@
+#!/bin/bash
+# Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+
+# Runs the decoding benchmarks of the driver, and compares them with the
+# baseline using benchstat, installed with:
+#
+#     go install golang.org/x/perf/cmd/benchstat@latest
+#
+# Pass -update to replace the baseline with the results of the run.
+
+cd "$(dirname "${BASH_SOURCE[0]}")" || exit 1
+
+PKG=../trino
+BASELINE=$PKG/testdata/benchmarks/baseline.txt
+COUNT=${COUNT:-10}
+
+out=$(mktemp)
+trap 'rm -f "$out"' EXIT
+
+go test -run '^$' -bench Decode -benchmem -count "$COUNT" $PKG | grep -v '^ok' > "$out" || exit 1
+
+if [ "$1" = "-update" ]; then
+    cp "$out" "$BASELINE"
+    exit 0
+fi
+
+benchstat "$BASELINE" "$out"
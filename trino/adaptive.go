@@ -0,0 +1,96 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"math"
+	"time"
+)
+
+// Bounds of the number of pages fetched ahead of the application with
+// adaptive_prefetch, on top of the buffer limits.
+const (
+	minPagesAhead = 1
+	maxPagesAhead = 64
+)
+
+// prefetchRateAlpha is the weight of the latest measure in the moving
+// averages of the fetch and consumption times of the pages.
+const prefetchRateAlpha = 0.3
+
+// prefetchRate measures how long pages take to be fetched, and to be
+// scanned by the application, to fetch ahead just enough pages for the
+// application not to wait, rather than filling the buffer.
+type prefetchRate struct {
+	fetch    float64 // moving average of the fetch time of a page, in ns
+	consume  float64 // moving average of the scan time of a page, in ns
+	lastNext time.Time
+}
+
+func movingAverage(avg, v float64) float64 {
+	if avg == 0 {
+		return v
+	}
+	return avg + prefetchRateAlpha*(v-avg)
+}
+
+// observeFetch records the time taken to fetch a page.
+func (r *prefetchRate) observeFetch(d time.Duration) {
+	if r != nil {
+		r.fetch = movingAverage(r.fetch, float64(d))
+	}
+}
+
+// observeNext records that the application requested the next page, at
+// now, once done with the previous one, and returns once the page is
+// available.
+func (r *prefetchRate) observeNext(now time.Time) {
+	if r == nil {
+		return
+	}
+	if !r.lastNext.IsZero() {
+		r.consume = movingAverage(r.consume, float64(now.Sub(r.lastNext)))
+	}
+}
+
+// returned records that the application got the next page at now, and
+// starts scanning it.
+func (r *prefetchRate) returned(now time.Time) {
+	if r != nil {
+		r.lastNext = now
+	}
+}
+
+// pagesAhead returns the number of pages to fetch ahead, enough to fetch
+// the next page while the application scans the ones already fetched.
+func (r *prefetchRate) pagesAhead() int {
+	if r.fetch == 0 || r.consume == 0 {
+		return minPagesAhead
+	}
+	n := int(math.Ceil(r.fetch/r.consume)) + 1
+	if n < minPagesAhead {
+		return minPagesAhead
+	}
+	if n > maxPagesAhead {
+		return maxPagesAhead
+	}
+	return n
+}
+
+// ahead returns whether enough pages are fetched ahead of the application,
+// given the buffered ones, always false if not adaptive.
+func (r *prefetchRate) ahead(buffered int) bool {
+	return r != nil && buffered >= r.pagesAhead()
+}
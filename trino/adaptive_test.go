@@ -0,0 +1,77 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefetchRatePagesAhead(t *testing.T) {
+	r := &prefetchRate{}
+	assert.Equal(t, minPagesAhead, r.pagesAhead())
+	assert.False(t, (*prefetchRate)(nil).ahead(100))
+
+	// pages are scanned faster than fetched
+	r.observeFetch(40 * time.Millisecond)
+	now := time.Now()
+	r.returned(now)
+	r.observeNext(now.Add(10 * time.Millisecond))
+	assert.Equal(t, 5, r.pagesAhead())
+	assert.True(t, r.ahead(5))
+	assert.False(t, r.ahead(4))
+
+	// pages are fetched faster than scanned
+	r = &prefetchRate{fetch: float64(time.Millisecond), consume: float64(time.Second)}
+	assert.Equal(t, 2, r.pagesAhead())
+
+	r = &prefetchRate{fetch: float64(time.Hour), consume: float64(time.Millisecond)}
+	assert.Equal(t, maxPagesAhead, r.pagesAhead())
+}
+
+func TestAdaptivePrefetch(t *testing.T) {
+	const pages = 20
+	var requested int32
+	ts := newPagedResultTestServer(t, integerPages(pages), nil, countPages(&requested))
+
+	db, err := sql.Open("trino", ts.URL+"?max_buffered_rows=1000&adaptive_prefetch=true")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	rows, err := db.Query("SELECT 1")
+	require.NoError(t, err)
+	var values []int
+	maxAhead := int32(0)
+	for rows.Next() {
+		var n int
+		require.NoError(t, rows.Scan(&n))
+		values = append(values, n)
+		// scanning a page is much slower than fetching one
+		time.Sleep(10 * time.Millisecond)
+		if ahead := atomic.LoadInt32(&requested) - int32(len(values)/2); ahead > maxAhead {
+			maxAhead = ahead
+		}
+	}
+	require.NoError(t, rows.Err())
+	assert.Len(t, values, 2*pages)
+	assert.LessOrEqual(t, maxAhead, int32(3), "fetched too many pages ahead")
+}
@@ -0,0 +1,254 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package admin lists and kills the queries, and lists the nodes and tasks
+// of a Trino cluster, from the tables of the system.runtime schema, for
+// building operations dashboards.
+//
+// The client runs queries with a database opened with the trino driver, so
+// it uses the same server, authentication and transport:
+//
+//	db, err := sql.Open("trino", "https://admin@localhost:8443")
+//	...
+//	client := admin.New(db)
+//	queries, err := client.ListQueries(ctx, admin.QueryFilter{State: "RUNNING"})
+//
+// Users only see their own queries unless allowed to view the queries of
+// others by the access control of Trino.
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/trinodb/trino-go-client/trino"
+)
+
+// ErrNotFound is returned when a query is not found.
+var ErrNotFound = errors.New("trino: query not found")
+
+// Client runs the queries of the admin API.
+type Client struct {
+	q trino.Queryer
+}
+
+// New returns a client running queries with q, usually a *sql.DB opened
+// with the trino driver.
+func New(q trino.Queryer) *Client {
+	return &Client{q: q}
+}
+
+// Query describes a query, as listed in system.runtime.queries.
+type Query struct {
+	QueryID       string
+	State         string
+	User          string
+	Source        string
+	Query         string
+	QueuedTime    time.Duration
+	AnalysisTime  time.Duration
+	PlanningTime  time.Duration
+	Created       time.Time
+	Started       time.Time // Zero if not started
+	LastHeartbeat time.Time
+	End           time.Time // Zero if not finished
+	ErrorType     string    // Empty unless failed
+	ErrorCode     string    // Empty unless failed
+}
+
+// QueryFilter selects the queries listed by ListQueries. Empty fields
+// select all queries.
+type QueryFilter struct {
+	State  string // State of the queries, e.g. RUNNING or QUEUED
+	User   string
+	Source string
+	Limit  int // Max number of queries, the most recently created first
+}
+
+const queryColumns = "query_id, state, user, source, query," +
+	" queued_time_ms, analysis_time_ms, planning_time_ms," +
+	" created, started, last_heartbeat, \"end\", error_type, error_code"
+
+// ListQueries returns the queries selected by the filter, the most
+// recently created first.
+func (c *Client) ListQueries(ctx context.Context, filter QueryFilter) ([]Query, error) {
+	var conditions []string
+	for column, value := range map[string]string{
+		"state":  filter.State,
+		"user":   filter.User,
+		"source": filter.Source,
+	} {
+		if value != "" {
+			conditions = append(conditions, column+" = "+trino.QuoteLiteral(value))
+		}
+	}
+	query := "SELECT " + queryColumns + " FROM system.runtime.queries"
+	if len(conditions) > 0 {
+		// sorted for the statements to be identical for identical filters
+		sort.Strings(conditions)
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT " + strconv.Itoa(filter.Limit)
+	}
+	return c.queries(ctx, query)
+}
+
+// Query returns the query with the ID, or ErrNotFound.
+func (c *Client) Query(ctx context.Context, queryID string) (*Query, error) {
+	queries, err := c.queries(ctx, "SELECT "+queryColumns+" FROM system.runtime.queries"+
+		" WHERE query_id = "+trino.QuoteLiteral(queryID))
+	if err != nil {
+		return nil, err
+	}
+	if len(queries) == 0 {
+		return nil, ErrNotFound
+	}
+	return &queries[0], nil
+}
+
+func (c *Client) queries(ctx context.Context, query string) ([]Query, error) {
+	rows, err := c.q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var queries []Query
+	for rows.Next() {
+		var q Query
+		var source, errorType, errorCode sql.NullString
+		var queued, analysis, planning sql.NullInt64
+		var started, end sql.NullTime
+		if err := rows.Scan(&q.QueryID, &q.State, &q.User, &source, &q.Query,
+			&queued, &analysis, &planning,
+			&q.Created, &started, &q.LastHeartbeat, &end, &errorType, &errorCode); err != nil {
+			return nil, err
+		}
+		q.Source = source.String
+		q.QueuedTime = time.Duration(queued.Int64) * time.Millisecond
+		q.AnalysisTime = time.Duration(analysis.Int64) * time.Millisecond
+		q.PlanningTime = time.Duration(planning.Int64) * time.Millisecond
+		q.Started = started.Time
+		q.End = end.Time
+		q.ErrorType = errorType.String
+		q.ErrorCode = errorCode.String
+		queries = append(queries, q)
+	}
+	return queries, rows.Err()
+}
+
+// KillQuery kills the query with the ID, failing it with the message.
+func (c *Client) KillQuery(ctx context.Context, queryID, message string) error {
+	rows, err := c.q.QueryContext(ctx, "CALL system.runtime.kill_query(query_id => "+trino.QuoteLiteral(queryID)+
+		", message => "+trino.QuoteLiteral(message)+")")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+	}
+	return rows.Err()
+}
+
+// Node describes a node of the cluster, as listed in system.runtime.nodes.
+type Node struct {
+	NodeID      string
+	HTTPURI     string
+	Version     string
+	Coordinator bool
+	State       string // e.g. active or shutting_down
+}
+
+// ListNodes returns the nodes of the cluster, the coordinators first.
+func (c *Client) ListNodes(ctx context.Context) ([]Node, error) {
+	rows, err := c.q.QueryContext(ctx, "SELECT node_id, http_uri, node_version, coordinator, state"+
+		" FROM system.runtime.nodes ORDER BY coordinator DESC, node_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var nodes []Node
+	for rows.Next() {
+		var n Node
+		if err := rows.Scan(&n.NodeID, &n.HTTPURI, &n.Version, &n.Coordinator, &n.State); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, rows.Err()
+}
+
+// Task describes a task of a query, as listed in system.runtime.tasks.
+type Task struct {
+	NodeID          string
+	TaskID          string
+	StageID         string
+	QueryID         string
+	State           string
+	Splits          int64
+	QueuedSplits    int64
+	RunningSplits   int64
+	CompletedSplits int64
+	CPUTime         time.Duration
+	RawInputRows    int64
+	RawInputBytes   int64
+	OutputRows      int64
+	OutputBytes     int64
+	Created         time.Time
+	Start           time.Time // Zero if not started
+	LastHeartbeat   time.Time
+	End             time.Time // Zero if not finished
+}
+
+// ListTasks returns the tasks of the query with the ID, or of all queries
+// if the ID is empty.
+func (c *Client) ListTasks(ctx context.Context, queryID string) ([]Task, error) {
+	query := "SELECT node_id, task_id, stage_id, query_id, state," +
+		" splits, queued_splits, running_splits, completed_splits, split_cpu_time_ms," +
+		" raw_input_rows, raw_input_bytes, output_rows, output_bytes," +
+		" created, start, last_heartbeat, \"end\"" +
+		" FROM system.runtime.tasks"
+	if queryID != "" {
+		query += " WHERE query_id = " + trino.QuoteLiteral(queryID)
+	}
+	query += " ORDER BY task_id"
+	rows, err := c.q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tasks []Task
+	for rows.Next() {
+		var t Task
+		var cpu int64
+		var start, end sql.NullTime
+		if err := rows.Scan(&t.NodeID, &t.TaskID, &t.StageID, &t.QueryID, &t.State,
+			&t.Splits, &t.QueuedSplits, &t.RunningSplits, &t.CompletedSplits, &cpu,
+			&t.RawInputRows, &t.RawInputBytes, &t.OutputRows, &t.OutputBytes,
+			&t.Created, &start, &t.LastHeartbeat, &end); err != nil {
+			return nil, err
+		}
+		t.CPUTime = time.Duration(cpu) * time.Millisecond
+		t.Start = start.Time
+		t.End = end.Time
+		tasks = append(tasks, t)
+	}
+	return tasks, rows.Err()
+}
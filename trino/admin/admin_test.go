@@ -0,0 +1,142 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trinodb/trino-go-client/trino"
+)
+
+// newTestClient returns a client of a server answering every statement
+// with the result, and recording the statements.
+func newTestClient(t *testing.T, result string, statements *[]string) *Client {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		*statements = append(*statements, string(b))
+		w.Write([]byte(`{"id": "20210101_000000_00000_abcde", ` + result + `}`))
+	}))
+	t.Cleanup(ts.Close)
+	db, err := sql.Open("trino", ts.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+	return New(db)
+}
+
+const queriesResult = `
+	"columns": [
+		{"name": "query_id", "type": "varchar"},
+		{"name": "state", "type": "varchar"},
+		{"name": "user", "type": "varchar"},
+		{"name": "source", "type": "varchar"},
+		{"name": "query", "type": "varchar"},
+		{"name": "queued_time_ms", "type": "bigint"},
+		{"name": "analysis_time_ms", "type": "bigint"},
+		{"name": "planning_time_ms", "type": "bigint"},
+		{"name": "created", "type": "timestamp(3) with time zone"},
+		{"name": "started", "type": "timestamp(3) with time zone"},
+		{"name": "last_heartbeat", "type": "timestamp(3) with time zone"},
+		{"name": "end", "type": "timestamp(3) with time zone"},
+		{"name": "error_type", "type": "varchar"},
+		{"name": "error_code", "type": "varchar"}
+	],
+	"data": [[
+		"20210101_000000_00001_abcde", "RUNNING", "alice", null, "SELECT 1",
+		5, 10, 20,
+		"2021-01-01 00:00:00.000 UTC", "2021-01-01 00:00:01.000 UTC", "2021-01-01 00:00:02.000 UTC", null,
+		null, null
+	]]`
+
+func TestListQueries(t *testing.T) {
+	var statements []string
+	client := newTestClient(t, queriesResult, &statements)
+
+	queries, err := client.ListQueries(context.Background(), QueryFilter{State: "RUNNING", User: "o'brien", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SELECT " + queryColumns + " FROM system.runtime.queries" +
+		" WHERE state = 'RUNNING' AND user = 'o''brien' ORDER BY created DESC LIMIT 10"}, statements)
+	require.Len(t, queries, 1)
+	q := queries[0]
+	assert.Equal(t, "20210101_000000_00001_abcde", q.QueryID)
+	assert.Equal(t, "RUNNING", q.State)
+	assert.Equal(t, "alice", q.User)
+	assert.Equal(t, "", q.Source)
+	assert.Equal(t, 10*time.Millisecond, q.AnalysisTime)
+	assert.Equal(t, time.Date(2021, 1, 1, 0, 0, 1, 0, time.UTC), q.Started.UTC())
+	assert.True(t, q.End.IsZero())
+}
+
+func TestQueryNotFound(t *testing.T) {
+	var statements []string
+	client := newTestClient(t, `"columns": [{"name": "query_id", "type": "varchar"}], "data": []`, &statements)
+
+	_, err := client.Query(context.Background(), "20210101_000000_00001_abcde")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestKillQuery(t *testing.T) {
+	var statements []string
+	client := newTestClient(t, `"updateType": "CALL"`, &statements)
+
+	require.NoError(t, client.KillQuery(context.Background(), "20210101_000000_00001_abcde", "too slow"))
+	assert.Equal(t, []string{"CALL system.runtime.kill_query(query_id => '20210101_000000_00001_abcde', message => 'too slow')"}, statements)
+}
+
+func TestListNodes(t *testing.T) {
+	var statements []string
+	client := newTestClient(t, `
+		"columns": [
+			{"name": "node_id", "type": "varchar"},
+			{"name": "http_uri", "type": "varchar"},
+			{"name": "node_version", "type": "varchar"},
+			{"name": "coordinator", "type": "boolean"},
+			{"name": "state", "type": "varchar"}
+		],
+		"data": [["coordinator", "http://10.0.0.1:8080", "400", true, "active"]]`, &statements)
+
+	nodes, err := client.ListNodes(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Node{{
+		NodeID:      "coordinator",
+		HTTPURI:     "http://10.0.0.1:8080",
+		Version:     "400",
+		Coordinator: true,
+		State:       "active",
+	}}, nodes)
+}
+
+func TestListTasksQuoting(t *testing.T) {
+	var statements []string
+	client := newTestClient(t, `"columns": [{"name": "node_id", "type": "varchar"}], "data": []`, &statements)
+
+	_, err := client.ListTasks(context.Background(), "x' OR '1'='1")
+	require.NoError(t, err)
+	require.Len(t, statements, 1)
+	assert.Contains(t, statements[0], " WHERE query_id = "+trino.QuoteLiteral("x' OR '1'='1")+" ORDER BY task_id")
+}
@@ -0,0 +1,159 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// queryStatements are the statements returning rows read from tables, by
+// their first keyword.
+var queryStatements = map[string]bool{
+	"SELECT": true,
+	"WITH":   true,
+	"VALUES": true,
+	"TABLE":  true,
+}
+
+// isQuery returns whether the statement is a query.
+func isQuery(query string) bool {
+	keyword, _ := nextKeyword(query)
+	return queryStatements[keyword]
+}
+
+// applyAutoLimit returns the query with a LIMIT of at most max rows: the
+// top-level LIMIT or FETCH clause of the query is lowered to max, or a
+// LIMIT clause is added if it has none. Limits that cannot be checked, such
+// as the ones set by parameters, are kept as is, and the query is wrapped
+// in a query limiting its rows to max.
+func applyAutoLimit(query string, max int64) string {
+	if !isQuery(query) {
+		return query
+	}
+	tokens := topLevelTokens(query)
+	for i, t := range tokens {
+		var count *sqlToken
+		switch strings.ToUpper(t.text) {
+		case "LIMIT":
+			if i+1 < len(tokens) {
+				count = &tokens[i+1]
+			}
+		case "FETCH":
+			// FETCH { FIRST | NEXT } [ count ] { ROW | ROWS } { ONLY | WITH TIES }
+			if i+2 < len(tokens) {
+				count = &tokens[i+2]
+			}
+		default:
+			continue
+		}
+		if count == nil {
+			return wrapLimit(query, max)
+		}
+		word := strings.ToUpper(count.text)
+		if word == "ROW" || word == "ROWS" {
+			// FETCH FIRST ROW ONLY
+			return query
+		}
+		if word == "ALL" {
+			return query[:count.start] + strconv.FormatInt(max, 10) + query[count.end:]
+		}
+		n, err := strconv.ParseInt(count.text, 10, 64)
+		if err != nil {
+			// e.g. LIMIT ?
+			return wrapLimit(query, max)
+		}
+		if n > max {
+			return query[:count.start] + strconv.FormatInt(max, 10) + query[count.end:]
+		}
+		return query
+	}
+	return strings.TrimRightFunc(query, unicode.IsSpace) + "\nLIMIT " + strconv.FormatInt(max, 10)
+}
+
+// wrapLimit returns a query returning at most max rows of the query.
+func wrapLimit(query string, max int64) string {
+	return "SELECT * FROM (\n" + strings.TrimRightFunc(query, unicode.IsSpace) + "\n)\nLIMIT " + strconv.FormatInt(max, 10)
+}
+
+// sqlToken is a word, number or parameter of a statement.
+type sqlToken struct {
+	text       string
+	start, end int
+}
+
+// topLevelTokens returns the tokens of the query outside of parentheses,
+// skipping literals, quoted identifiers and comments.
+func topLevelTokens(query string) []sqlToken {
+	var tokens []sqlToken
+	depth := 0
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"':
+			i = skipQuoted(query, i, c)
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return tokens
+			}
+			i += end + 1
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 4
+		case c == '(':
+			depth++
+			i++
+		case c == ')':
+			depth--
+			i++
+		case c == '?' || isWordByte(c):
+			start := i
+			i++
+			for c != '?' && i < len(query) && isWordByte(query[i]) {
+				i++
+			}
+			if depth == 0 {
+				tokens = append(tokens, sqlToken{text: query[start:i], start: start, end: i})
+			}
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 0x80 || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// skipQuoted returns the index following the literal or identifier quoted
+// with q starting at i, in which quotes are escaped by doubling them.
+func skipQuoted(query string, i int, q byte) int {
+	for i++; i < len(query); i++ {
+		if query[i] == q {
+			if i+1 < len(query) && query[i+1] == q {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return i
+}
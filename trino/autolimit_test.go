@@ -0,0 +1,77 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyAutoLimit(t *testing.T) {
+	for query, want := range map[string]string{
+		"SELECT * FROM t":                              "SELECT * FROM t\nLIMIT 100",
+		"SELECT * FROM t -- all rows\n":                "SELECT * FROM t -- all rows\nLIMIT 100",
+		"SELECT * FROM t -- all rows":                  "SELECT * FROM t -- all rows\nLIMIT 100",
+		"SELECT * FROM t ORDER BY a OFFSET 10":         "SELECT * FROM t ORDER BY a OFFSET 10\nLIMIT 100",
+		"SELECT * FROM t LIMIT 10":                     "SELECT * FROM t LIMIT 10",
+		"SELECT * FROM t limit 1000":                   "SELECT * FROM t limit 100",
+		"SELECT * FROM t LIMIT ALL":                    "SELECT * FROM t LIMIT 100",
+		"SELECT * FROM t LIMIT ?":                      "SELECT * FROM (\nSELECT * FROM t LIMIT ?\n)\nLIMIT 100",
+		"SELECT * FROM t LIMIT x":                      "SELECT * FROM (\nSELECT * FROM t LIMIT x\n)\nLIMIT 100",
+		"SELECT * FROM t LIMIT 1e9 -- all":             "SELECT * FROM (\nSELECT * FROM t LIMIT 1e9 -- all\n)\nLIMIT 100",
+		"SELECT * FROM t FETCH FIRST ? ROWS ONLY":      "SELECT * FROM (\nSELECT * FROM t FETCH FIRST ? ROWS ONLY\n)\nLIMIT 100",
+		"SELECT * FROM t LIMIT":                        "SELECT * FROM (\nSELECT * FROM t LIMIT\n)\nLIMIT 100",
+		"SELECT * FROM t FETCH FIRST 1000 ROWS ONLY":   "SELECT * FROM t FETCH FIRST 100 ROWS ONLY",
+		"SELECT * FROM t FETCH NEXT ROW ONLY":          "SELECT * FROM t FETCH NEXT ROW ONLY",
+		"SELECT * FROM (SELECT * FROM t LIMIT 10)":     "SELECT * FROM (SELECT * FROM t LIMIT 10)\nLIMIT 100",
+		"SELECT 'LIMIT 10', \"limit\" FROM t":          "SELECT 'LIMIT 10', \"limit\" FROM t\nLIMIT 100",
+		"SELECT 1 /* LIMIT 10 */":                      "SELECT 1 /* LIMIT 10 */\nLIMIT 100",
+		"WITH x AS (SELECT 1 LIMIT 1) SELECT * FROM x": "WITH x AS (SELECT 1 LIMIT 1) SELECT * FROM x\nLIMIT 100",
+		"(SELECT 1) UNION (SELECT 2)":                  "(SELECT 1) UNION (SELECT 2)\nLIMIT 100",
+		"SHOW TABLES":                                  "SHOW TABLES",
+		"INSERT INTO t SELECT * FROM u":                "INSERT INTO t SELECT * FROM u",
+		"EXPLAIN SELECT * FROM t":                      "EXPLAIN SELECT * FROM t",
+	} {
+		assert.Equal(t, want, applyAutoLimit(query, 100), query)
+	}
+}
+
+func TestAutoLimit(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`, &queries)
+	db, err := sql.Open("trino", ts.URL+"?auto_limit=100")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	var n int
+	require.NoError(t, db.QueryRow("SELECT a FROM t").Scan(&n))
+	require.NoError(t, db.QueryRow("SELECT a FROM t LIMIT 1000").Scan(&n))
+	assert.Equal(t, []string{"SELECT a FROM t\nLIMIT 100", "SELECT a FROM t LIMIT 100"}, queries)
+}
+
+func TestAutoLimitDSN(t *testing.T) {
+	c := &Config{ServerURI: "http://foobar@localhost:8080", AutoLimit: 100}
+	dsn, err := c.FormatDSN()
+	require.NoError(t, err)
+	assert.Equal(t, "http://foobar@localhost:8080?auto_limit=100&source=trino-go-client", dsn)
+
+	_, err = sql.Open("trino", "http://foobar@localhost:8080?auto_limit=-1")
+	assert.EqualError(t, err, `trino: invalid auto_limit: "-1"`)
+}
@@ -0,0 +1,215 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// The benchmarks of this file measure the decoding and scanning of
+// results, served from memory by a local server so that the network and
+// Trino do not add noise. Compare runs with benchstat, as described in
+// CONTRIBUTING.md:
+//
+//	go test -run '^$' -bench Decode -benchmem -count 10 ./trino > new.txt
+//	benchstat trino/testdata/benchmarks/baseline.txt new.txt
+
+// newBenchServer returns a server returning a result made of the given
+// pages, encoded once up front, and the total size of the pages.
+func newBenchServer(b *testing.B, columns []queryColumn, pages [][]queryData) (*httptest.Server, int64) {
+	bodies := make([][]byte, len(pages))
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.URL.Path == "/v1/statement" {
+			json.NewEncoder(w).Encode(&stmtResponse{
+				ID:      "20210101_000000_00000_abcde",
+				NextURI: ts.URL + "/v1/statement/20210101_000000_00000_abcde/0",
+			})
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+		w.Write(bodies[page])
+	}))
+	b.Cleanup(ts.Close)
+
+	var size int64
+	for i, data := range pages {
+		resp := &queryResponse{
+			ID:      "20210101_000000_00000_abcde",
+			Columns: columns,
+			Data:    data,
+		}
+		if i < len(pages)-1 {
+			resp.NextURI = fmt.Sprintf("%s/v1/statement/20210101_000000_00000_abcde/%d", ts.URL, i+1)
+		}
+		body, err := json.Marshal(resp)
+		if err != nil {
+			b.Fatal(err)
+		}
+		bodies[i] = body
+		size += int64(len(body))
+	}
+	return ts, size
+}
+
+// benchmarkResult reads the whole result served by ts for each iteration,
+// scanning each row into the destinations returned by dest.
+func benchmarkResult(b *testing.B, ts *httptest.Server, size int64, dest func() []interface{}) {
+	benchmarkResultContext(b, context.Background(), ts, size, dest)
+}
+
+// benchmarkResultContext is like benchmarkResult, executing the query with
+// the given context.
+func benchmarkResultContext(b *testing.B, ctx context.Context, ts *httptest.Server, size int64, dest func() []interface{}) {
+	db, err := sql.Open("trino", ts.URL)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() {
+		db.Close()
+	})
+	values := dest()
+
+	b.SetBytes(size)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rows, err := db.QueryContext(ctx, "SELECT * FROM t")
+		if err != nil {
+			b.Fatal(err)
+		}
+		for rows.Next() {
+			if err := rows.Scan(values...); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			b.Fatal(err)
+		}
+		rows.Close()
+	}
+}
+
+func interfaceDest(n int) func() []interface{} {
+	return func() []interface{} {
+		dest := make([]interface{}, n)
+		for i := range dest {
+			dest[i] = new(interface{})
+		}
+		return dest
+	}
+}
+
+// newWideRowsServer returns a server returning rows of 40 columns of
+// various types, and the names of the columns.
+func newWideRowsServer(b *testing.B) (*httptest.Server, int64, []string) {
+	const groups, rows = 8, 1000
+	var columns []queryColumn
+	for g := 0; g < groups; g++ {
+		columns = append(columns,
+			queryColumn{Name: fmt.Sprintf("id%d", g), Type: "bigint"},
+			queryColumn{Name: fmt.Sprintf("score%d", g), Type: "double"},
+			queryColumn{Name: fmt.Sprintf("name%d", g), Type: "varchar"},
+			queryColumn{Name: fmt.Sprintf("active%d", g), Type: "boolean"},
+			queryColumn{Name: fmt.Sprintf("created%d", g), Type: "timestamp(3)"},
+		)
+	}
+	data := make([]queryData, rows)
+	for i := range data {
+		for g := 0; g < groups; g++ {
+			data[i] = append(data[i], i, float64(i)/3, "name-"+strconv.Itoa(i), i%2 == 0, "2021-01-01 12:34:56.789")
+		}
+	}
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.Name
+	}
+	ts, size := newBenchServer(b, columns, [][]queryData{data})
+	return ts, size, names
+}
+
+func BenchmarkDecodeWideRows(b *testing.B) {
+	ts, size, names := newWideRowsServer(b)
+	benchmarkResult(b, ts, size, interfaceDest(len(names)))
+}
+
+func BenchmarkDecodeWideRowsScannedColumns(b *testing.B) {
+	ts, size, names := newWideRowsServer(b)
+	for _, n := range []int{1, len(names) / 2, len(names)} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			ctx := WithScannedColumns(context.Background(), names[:n]...)
+			benchmarkResultContext(b, ctx, ts, size, interfaceDest(len(names)))
+		})
+	}
+}
+
+func BenchmarkDecodeNestedTypes(b *testing.B) {
+	const rows = 1000
+	columns := []queryColumn{
+		{Name: "ids", Type: "array(bigint)"},
+		{Name: "tags", Type: "map(varchar, bigint)"},
+		{Name: "owner", Type: "row(id bigint, name varchar)"},
+		{Name: "matrix", Type: "array(array(double))"},
+	}
+	data := make([]queryData, rows)
+	for i := range data {
+		data[i] = queryData{
+			[]interface{}{i, i + 1, i + 2, i + 3},
+			map[string]interface{}{"a": i, "b": i * 2},
+			[]interface{}{i, "owner-" + strconv.Itoa(i)},
+			[]interface{}{[]interface{}{1.5, 2.5}, []interface{}{3.5, 4.5}},
+		}
+	}
+	ts, size := newBenchServer(b, columns, [][]queryData{data})
+	benchmarkResult(b, ts, size, interfaceDest(len(columns)))
+}
+
+func BenchmarkDecodeLargeStrings(b *testing.B) {
+	const rows = 100
+	columns := []queryColumn{{Name: "doc", Type: "varchar"}}
+	data := make([]queryData, rows)
+	for i := range data {
+		data[i] = queryData{strings.Repeat(strconv.Itoa(i%10), 64<<10)}
+	}
+	ts, size := newBenchServer(b, columns, [][]queryData{data})
+	benchmarkResult(b, ts, size, func() []interface{} {
+		return []interface{}{new(string)}
+	})
+}
+
+func BenchmarkDecodeManySmallPages(b *testing.B) {
+	const pages = 200
+	columns := []queryColumn{{Name: "n", Type: "bigint"}}
+	data := make([][]queryData, pages)
+	for i := range data {
+		data[i] = []queryData{{i}}
+	}
+	ts, size := newBenchServer(b, columns, data)
+	benchmarkResult(b, ts, size, func() []interface{} {
+		return []interface{}{new(int64)}
+	})
+}
@@ -0,0 +1,96 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrCancelNotConfirmed is returned when confirm_cancel is set, and Trino
+// could not be confirmed to have stopped a cancelled query before
+// DefaultCancelQueryTimeout, so that the query may still be running.
+type ErrCancelNotConfirmed struct {
+	QueryID string // ID of the cancelled query
+	State   string // Last state of the query reported by Trino, if any
+	Err     error  // Failure of the cancellation or of its confirmation
+}
+
+// Error implements the error interface.
+func (e *ErrCancelNotConfirmed) Error() string {
+	if e.State != "" {
+		return fmt.Sprintf("trino: cancellation of query %s not confirmed, last state %s: %v", e.QueryID, e.State, e.Err)
+	}
+	return fmt.Sprintf("trino: cancellation of query %s not confirmed: %v", e.QueryID, e.Err)
+}
+
+// Unwrap returns the failure of the cancellation or of its confirmation.
+func (e *ErrCancelNotConfirmed) Unwrap() error {
+	return e.Err
+}
+
+// Wait between requests for the state of a cancelled query, doubled after
+// each request.
+const (
+	minCancelPollInterval = 50 * time.Millisecond
+	maxCancelPollInterval = time.Second
+)
+
+// confirmCancel waits for Trino to report a cancelled query as completed,
+// failing with ErrCancelNotConfirmed if the request to cancel it failed
+// with err, or if it is still running when the context is done.
+func (qr *driverRows) confirmCancel(ctx context.Context, err error) error {
+	if err != nil {
+		return &ErrCancelNotConfirmed{QueryID: qr.queryID, Err: err}
+	}
+	var state string
+	delay := minCancelPollInterval
+	for {
+		info, err := qr.stmt.conn.queryInfo(ctx, qr.queryID, qr.stmt.user)
+		switch {
+		case err == nil:
+			state = info.State
+			if state == "FINISHED" || state == "FAILED" {
+				return nil
+			}
+		case isQueryGone(err):
+			return nil
+		default:
+			return &ErrCancelNotConfirmed{QueryID: qr.queryID, State: state, Err: err}
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return &ErrCancelNotConfirmed{QueryID: qr.queryID, State: state, Err: ctx.Err()}
+		case <-timer.C:
+		}
+		delay *= 2
+		if delay > maxCancelPollInterval {
+			delay = maxCancelPollInterval
+		}
+	}
+}
+
+// isQueryGone returns whether the request for the state of a query failed
+// because the coordinator no longer knows it, which happens once completed
+// queries are purged.
+func isQueryGone(err error) bool {
+	var qerr *ErrQueryFailed
+	return errors.As(err, &qerr) && (qerr.StatusCode == http.StatusNotFound || qerr.StatusCode == http.StatusGone)
+}
@@ -0,0 +1,134 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCancelTestServer returns a server running a query that never returns
+// data, and reporting it in the given states, the last one repeatedly,
+// once it is cancelled.
+func newCancelTestServer(t *testing.T, states ...string) (*httptest.Server, *[]string) {
+	var mu sync.Mutex
+	var requests []string
+	record := func(w http.ResponseWriter, r *http.Request) bool {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		return false
+	}
+	queryInfo := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method != "GET" || r.URL.Path != "/v1/query/"+testQueryID {
+			return false
+		}
+		mu.Lock()
+		state := states[0]
+		if len(states) > 1 {
+			states = states[1:]
+		}
+		mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]string{
+			"queryId": testQueryID,
+			"state":   state,
+		})
+		return true
+	}
+	ts := newPagedResultTestServer(t, nil, nil, record, queryInfo, blockPage(1, nil))
+	return ts, &requests
+}
+
+func openCancelTestDB(t *testing.T, ts *httptest.Server) *sql.DB {
+	db, err := sql.Open("trino", ts.URL+"?confirm_cancel=true")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+	return db
+}
+
+func TestConfirmCancel(t *testing.T) {
+	ts, requests := newCancelTestServer(t, "RUNNING", "FAILED")
+	db := openCancelTestDB(t, ts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err := db.QueryContext(ctx, "SELECT 1")
+	assert.True(t, errors.Is(err, context.Canceled), "unexpected error: %v", err)
+	assert.Equal(t, []string{
+		"POST /v1/statement",
+		"GET /v1/statement/20210101_000000_00000_abcde/1",
+		"DELETE /v1/query/20210101_000000_00000_abcde",
+		"GET /v1/query/20210101_000000_00000_abcde",
+		"GET /v1/query/20210101_000000_00000_abcde",
+	}, *requests)
+}
+
+func TestConfirmCancelTimeout(t *testing.T) {
+	timeout := DefaultCancelQueryTimeout
+	DefaultCancelQueryTimeout = 100 * time.Millisecond
+	t.Cleanup(func() {
+		DefaultCancelQueryTimeout = timeout
+	})
+	ts, _ := newCancelTestServer(t, "RUNNING")
+	db := openCancelTestDB(t, ts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := db.QueryContext(ctx, "SELECT 1")
+	var nerr *ErrCancelNotConfirmed
+	require.True(t, errors.As(err, &nerr), "unexpected error: %v", err)
+	assert.Equal(t, "20210101_000000_00000_abcde", nerr.QueryID)
+	assert.Equal(t, "RUNNING", nerr.State)
+	assert.Equal(t, context.DeadlineExceeded, nerr.Err)
+	assert.EqualError(t, nerr, "trino: cancellation of query 20210101_000000_00000_abcde not confirmed, last state RUNNING: context deadline exceeded")
+}
+
+func TestConfirmCancelGone(t *testing.T) {
+	result := `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`
+	ts := newPagedResultTestServer(t, []string{result, result}, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method != "GET" || r.URL.Path != "/v1/query/"+testQueryID {
+			return false
+		}
+		w.WriteHeader(http.StatusGone)
+		return true
+	})
+	db := openCancelTestDB(t, ts)
+
+	rows, err := db.Query("SELECT 1")
+	require.NoError(t, err)
+	require.True(t, rows.Next())
+	assert.NoError(t, rows.Close())
+}
+
+func TestConfirmCancelDSN(t *testing.T) {
+	dsn, err := (&Config{ServerURI: "http://foobar@localhost:8080", ConfirmCancel: true}).FormatDSN()
+	require.NoError(t, err)
+	assert.Equal(t, "http://foobar@localhost:8080?confirm_cancel=true&source=trino-go-client", dsn)
+
+	_, err = newConn("http://foobar@localhost:8080?confirm_cancel=maybe")
+	assert.EqualError(t, err, `trino: invalid confirm_cancel: "maybe"`)
+}
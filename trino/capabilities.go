@@ -0,0 +1,79 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import "strings"
+
+// Client capabilities, declared to Trino so that it only uses the features
+// of the protocol the client supports.
+const (
+	// CapabilityPath allows Trino to change the SQL path of the session.
+	CapabilityPath = "PATH"
+	// CapabilityParametricDatetime allows Trino to return temporal values
+	// with their full precision, and types with their precision, such as
+	// timestamp(6), instead of rounding them to milliseconds.
+	CapabilityParametricDatetime = "PARAMETRIC_DATETIME"
+	// CapabilitySessionAuthorization allows Trino to change the user of
+	// the session, with SET SESSION AUTHORIZATION.
+	CapabilitySessionAuthorization = "SESSION_AUTHORIZATION"
+)
+
+// DefaultClientCapabilities are the capabilities the driver supports, and
+// declares unless Config.ClientCapabilities is set.
+var DefaultClientCapabilities = []string{
+	CapabilityParametricDatetime,
+}
+
+var knownClientCapabilities = map[string]bool{
+	CapabilityPath:                 true,
+	CapabilityParametricDatetime:   true,
+	CapabilitySessionAuthorization: true,
+}
+
+// noClientCapabilities is the value of the client_capabilities parameter
+// declaring no capabilities.
+const noClientCapabilities = "none"
+
+// formatClientCapabilities returns the value of the client_capabilities
+// parameter, or an empty string to use the defaults.
+func formatClientCapabilities(capabilities []string) string {
+	if capabilities == nil {
+		return ""
+	}
+	if len(capabilities) == 0 {
+		return noClientCapabilities
+	}
+	return strings.Join(capabilities, ",")
+}
+
+// parseClientCapabilities returns the value of the client capabilities
+// header from the client_capabilities parameter.
+func parseClientCapabilities(v string) (string, error) {
+	if v == "" {
+		return strings.Join(DefaultClientCapabilities, ","), nil
+	}
+	if v == noClientCapabilities {
+		return "", nil
+	}
+	capabilities := strings.Split(v, ",")
+	for i, capability := range capabilities {
+		capability = strings.ToUpper(strings.TrimSpace(capability))
+		if !knownClientCapabilities[capability] {
+			return "", invalidParameter("client_capabilities", v)
+		}
+		capabilities[i] = capability
+	}
+	return strings.Join(capabilities, ","), nil
+}
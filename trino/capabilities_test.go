@@ -0,0 +1,57 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientCapabilities(t *testing.T) {
+	for _, tc := range []struct {
+		Name         string
+		Capabilities []string
+		Expected     string
+	}{
+		{Name: "default", Capabilities: nil, Expected: "PARAMETRIC_DATETIME"},
+		{Name: "none", Capabilities: []string{}, Expected: ""},
+		{Name: "custom", Capabilities: []string{CapabilityPath, CapabilitySessionAuthorization}, Expected: "PATH,SESSION_AUTHORIZATION"},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			var headers http.Header
+			ts := newHeaderTestServer(t, `
+				"columns": [{"name": "_col0", "type": "integer"}],
+				"data": [[1]]`, &headers)
+			dsn, err := (&Config{ServerURI: ts.URL, ClientCapabilities: tc.Capabilities}).FormatDSN()
+			require.NoError(t, err)
+			db, err := sql.Open("trino", dsn)
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				assert.NoError(t, db.Close())
+			})
+
+			var v int
+			require.NoError(t, db.QueryRow("SELECT 1").Scan(&v))
+			assert.Equal(t, tc.Expected, headers.Get(trinoClientCapabilitiesHeader))
+		})
+	}
+
+	_, err := newConn("http://foobar@localhost:8080?client_capabilities=PATH,TIME_TRAVEL")
+	assert.EqualError(t, err, `trino: invalid client_capabilities: "PATH,TIME_TRAVEL"`)
+}
@@ -0,0 +1,173 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// These tests scan rows the way libraries such as sqlx and scany do, to
+// make sure they work with the driver without depending on them.
+
+const compatResult = `
+	"columns": [
+		{"name": "id", "type": "bigint"},
+		{"name": "name", "type": "varchar(10)"},
+		{"name": "score", "type": "double"},
+		{"name": "active", "type": "boolean"},
+		{"name": "created", "type": "timestamp(3)"},
+		{"name": "price", "type": "decimal(10,2)"},
+		{"name": "tags", "type": "array(varchar)"}
+	],
+	"data": [
+		[1, "a", 1.5, true, "2021-01-02 03:04:05.000", "1.23", ["x", "y"]],
+		[2, null, null, null, null, null, null]
+	]`
+
+// scanMaps scans rows into maps, allocating values of the scan types of
+// the columns, like sqlx's MapScan with typed destinations.
+func scanMaps(t *testing.T, rows *sql.Rows) []map[string]interface{} {
+	types, err := rows.ColumnTypes()
+	require.NoError(t, err)
+	var result []map[string]interface{}
+	for rows.Next() {
+		dest := make([]interface{}, len(types))
+		for i, ct := range types {
+			require.NotNil(t, ct.ScanType(), "column %s", ct.Name())
+			dest[i] = reflect.New(ct.ScanType()).Interface()
+		}
+		require.NoError(t, rows.Scan(dest...))
+		row := make(map[string]interface{})
+		for i, ct := range types {
+			row[ct.Name()] = reflect.ValueOf(dest[i]).Elem().Interface()
+		}
+		result = append(result, row)
+	}
+	require.NoError(t, rows.Err())
+	return result
+}
+
+// scanStructs scans rows into a slice of structs, matching columns to the
+// fields with the same db tag, like sqlx's Select and scany's ScanAll.
+func scanStructs(t *testing.T, rows *sql.Rows, dest interface{}) {
+	columns, err := rows.Columns()
+	require.NoError(t, err)
+	slice := reflect.ValueOf(dest).Elem()
+	elem := slice.Type().Elem()
+	for rows.Next() {
+		v := reflect.New(elem).Elem()
+		fields := make([]interface{}, len(columns))
+		for i, column := range columns {
+			for j := 0; j < elem.NumField(); j++ {
+				if elem.Field(j).Tag.Get("db") == column {
+					fields[i] = v.Field(j).Addr().Interface()
+				}
+			}
+			require.NotNil(t, fields[i], "no field for column %s", column)
+		}
+		require.NoError(t, rows.Scan(fields...))
+		slice.Set(reflect.Append(slice, v))
+	}
+	require.NoError(t, rows.Err())
+}
+
+func TestCompatColumnTypes(t *testing.T) {
+	ts := newResultTestServer(t, compatResult, nil)
+	db := openTestDB(t, ts)
+
+	rows, err := db.Query("SELECT *")
+	require.NoError(t, err)
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	require.NoError(t, err)
+	for _, ct := range types {
+		nullable, ok := ct.Nullable()
+		assert.True(t, ok)
+		assert.True(t, nullable)
+	}
+	assert.Equal(t, "varchar", types[1].DatabaseTypeName())
+	assert.Equal(t, reflect.TypeOf(sql.NullInt64{}), types[0].ScanType())
+	assert.Equal(t, reflect.TypeOf(sql.NullTime{}), types[4].ScanType())
+	assert.Equal(t, reflect.TypeOf((*interface{})(nil)).Elem(), types[6].ScanType())
+
+	result := scanMaps(t, rows)
+	require.Len(t, result, 2)
+	assert.Equal(t, sql.NullInt64{Int64: 1, Valid: true}, result[0]["id"])
+	assert.Equal(t, sql.NullString{String: "a", Valid: true}, result[0]["name"])
+	assert.Equal(t, sql.NullFloat64{Float64: 1.5, Valid: true}, result[0]["score"])
+	assert.Equal(t, sql.NullBool{Bool: true, Valid: true}, result[0]["active"])
+	assert.Equal(t, sql.NullString{String: "1.23", Valid: true}, result[0]["price"])
+	assert.Equal(t, []interface{}{"x", "y"}, result[0]["tags"])
+	created := result[0]["created"].(sql.NullTime)
+	assert.True(t, created.Valid)
+	assert.Equal(t, 2021, created.Time.Year())
+	for name, v := range result[1] {
+		if name == "id" {
+			continue
+		}
+		if valuer, ok := v.(driver.Valuer); ok {
+			v, err = valuer.Value()
+			require.NoError(t, err)
+		}
+		assert.Nil(t, v, "column %s", name)
+	}
+}
+
+func TestCompatStructScan(t *testing.T) {
+	ts := newResultTestServer(t, compatResult, nil)
+	db := openTestDB(t, ts)
+
+	type item struct {
+		ID      int64           `db:"id"`
+		Name    *string         `db:"name"`
+		Score   sql.NullFloat64 `db:"score"`
+		Active  *bool           `db:"active"`
+		Created *time.Time      `db:"created"`
+		Price   sql.NullString  `db:"price"`
+		Tags    NullSliceString `db:"tags"`
+	}
+
+	rows, err := db.Query("SELECT *")
+	require.NoError(t, err)
+	defer rows.Close()
+	var items []item
+	scanStructs(t, rows, &items)
+
+	require.Len(t, items, 2)
+	assert.Equal(t, int64(1), items[0].ID)
+	require.NotNil(t, items[0].Name)
+	assert.Equal(t, "a", *items[0].Name)
+	assert.Equal(t, 1.5, items[0].Score.Float64)
+	require.NotNil(t, items[0].Active)
+	assert.True(t, *items[0].Active)
+	require.NotNil(t, items[0].Created)
+	assert.Equal(t, "1.23", items[0].Price.String)
+	assert.Equal(t, []sql.NullString{{String: "x", Valid: true}, {String: "y", Valid: true}}, items[0].Tags.SliceString)
+
+	assert.Equal(t, int64(2), items[1].ID)
+	assert.Nil(t, items[1].Name)
+	assert.False(t, items[1].Score.Valid)
+	assert.Nil(t, items[1].Active)
+	assert.Nil(t, items[1].Created)
+	assert.False(t, items[1].Price.Valid)
+	assert.False(t, items[1].Tags.Valid)
+}
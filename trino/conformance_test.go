@@ -0,0 +1,279 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conformanceFixture is a recorded conversation between a client and a
+// Trino server of a given version, replayed by TestConformance to check
+// that the driver handles the shapes of the responses of that version.
+type conformanceFixture struct {
+	Description  string                 `json:"description"`
+	TrinoVersion string                 `json:"trinoVersion"`
+	Statements   []conformanceStatement `json:"statements"`
+}
+
+// conformanceStatement is a statement run by the driver, the exchanges it
+// is expected to make, in order, and what it is expected to return.
+type conformanceStatement struct {
+	Query     string                `json:"query"`
+	Exec      bool                  `json:"exec"`
+	Exchanges []conformanceExchange `json:"exchanges"`
+	Expect    conformanceExpect     `json:"expect"`
+}
+
+// conformanceExchange is a request of the driver, and the response of the
+// server. ${SERVER} in the body is replaced with the URL of the server.
+type conformanceExchange struct {
+	Method         string            `json:"method"`
+	Path           string            `json:"path"`
+	RequestHeaders map[string]string `json:"requestHeaders"`
+	Status         int               `json:"status"`
+	Headers        map[string]string `json:"headers"`
+	Body           json.RawMessage   `json:"body"`
+}
+
+type conformanceExpect struct {
+	Columns      []string         `json:"columns"`
+	Types        []string         `json:"types"`
+	Rows         json.RawMessage  `json:"rows"`
+	RowsAffected *int64           `json:"rowsAffected"`
+	Error        *conformanceFail `json:"error"`
+}
+
+type conformanceFail struct {
+	Sentinel string `json:"sentinel"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+var conformanceSentinels = map[string]error{
+	"ErrQueryCancelled":    ErrQueryCancelled,
+	"ErrQueryKilled":       ErrQueryKilled,
+	"ErrQueryTimeout":      ErrQueryTimeout,
+	"ErrUnsupportedHeader": ErrUnsupportedHeader,
+}
+
+func TestConformance(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "conformance", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, file := range files {
+		file := file
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".json"), func(t *testing.T) {
+			b, err := ioutil.ReadFile(file)
+			require.NoError(t, err)
+			var fixture conformanceFixture
+			require.NoError(t, json.Unmarshal(b, &fixture))
+			require.NotEmpty(t, fixture.TrinoVersion, "fixture must record the Trino version")
+			runConformanceFixture(t, fixture)
+		})
+	}
+}
+
+// conformanceServer replays the exchanges of the current statement.
+// Cancellation requests are answered without consuming an exchange, as
+// when the driver sends them depends on how the result is read.
+type conformanceServer struct {
+	t         *testing.T
+	mu        sync.Mutex
+	url       string
+	exchanges []conformanceExchange
+}
+
+func (s *conformanceServer) expect(exchanges []conformanceExchange) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exchanges = exchanges
+}
+
+func (s *conformanceServer) remaining() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.exchanges)
+}
+
+func (s *conformanceServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "DELETE" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	s.mu.Lock()
+	if len(s.exchanges) == 0 {
+		s.mu.Unlock()
+		s.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	e := s.exchanges[0]
+	s.exchanges = s.exchanges[1:]
+	serverURL := s.url
+	s.mu.Unlock()
+
+	assert.Equal(s.t, e.Method, r.Method)
+	assert.Equal(s.t, e.Path, r.URL.Path)
+	for k, v := range e.RequestHeaders {
+		assert.Equal(s.t, v, r.Header.Get(k), "request header %s of %s %s", k, r.Method, r.URL.Path)
+	}
+	for k, v := range e.Headers {
+		w.Header().Set(k, v)
+	}
+	status := e.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	w.Write([]byte(strings.Replace(string(e.Body), "${SERVER}", serverURL, -1)))
+}
+
+func runConformanceFixture(t *testing.T, fixture conformanceFixture) {
+	s := &conformanceServer{t: t}
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	s.mu.Lock()
+	s.url = ts.URL
+	s.mu.Unlock()
+
+	db, err := sql.Open("trino", ts.URL+"?catalog=memory&schema=default")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+	// session changes only apply to the connection that received them
+	db.SetMaxOpenConns(1)
+
+	for i, stmt := range fixture.Statements {
+		s.expect(stmt.Exchanges)
+		err := runConformanceStatement(t, db, stmt)
+		checkConformanceError(t, stmt.Expect.Error, err)
+		assert.Zero(t, s.remaining(), "statement %d (%s): exchanges not replayed", i, stmt.Query)
+	}
+}
+
+func runConformanceStatement(t *testing.T, db *sql.DB, stmt conformanceStatement) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if stmt.Exec {
+		res, err := db.ExecContext(ctx, stmt.Query)
+		if err != nil {
+			return err
+		}
+		if stmt.Expect.RowsAffected != nil {
+			n, err := res.RowsAffected()
+			require.NoError(t, err)
+			assert.Equal(t, *stmt.Expect.RowsAffected, n, stmt.Query)
+		}
+		return nil
+	}
+
+	rows, err := db.QueryContext(ctx, stmt.Query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if stmt.Expect.Columns != nil {
+		columns, err := rows.Columns()
+		require.NoError(t, err)
+		assert.Equal(t, stmt.Expect.Columns, columns, stmt.Query)
+	}
+	if stmt.Expect.Types != nil {
+		types, err := rows.ColumnTypes()
+		require.NoError(t, err)
+		names := make([]string, len(types))
+		for i, ct := range types {
+			names[i] = ct.DatabaseTypeName()
+		}
+		assert.Equal(t, stmt.Expect.Types, names, stmt.Query)
+	}
+	columns, err := rows.Columns()
+	require.NoError(t, err)
+	result := [][]interface{}{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		require.NoError(t, rows.Scan(dest...))
+		for i, v := range values {
+			values[i] = normalizeConformanceValue(v)
+		}
+		result = append(result, values)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if stmt.Expect.Rows != nil {
+		b, err := json.Marshal(result)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(stmt.Expect.Rows), string(b), stmt.Query)
+	}
+	return nil
+}
+
+// normalizeConformanceValue returns the value in a form that compares
+// equal to its JSON representation in a fixture.
+func normalizeConformanceValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return v
+}
+
+func checkConformanceError(t *testing.T, expect *conformanceFail, err error) {
+	if expect == nil {
+		assert.NoError(t, err)
+		return
+	}
+	require.Error(t, err)
+	if expect.Sentinel != "" {
+		sentinel, ok := conformanceSentinels[expect.Sentinel]
+		require.True(t, ok, "unknown sentinel %s", expect.Sentinel)
+		assert.True(t, errors.Is(err, sentinel), "expected %s, got %v", expect.Sentinel, err)
+	}
+	if expect.Name == "" {
+		return
+	}
+	var qf *ErrQueryFailed
+	require.True(t, errors.As(err, &qf), "unexpected error: %v", err)
+	assert.Equal(t, expect.Name, qf.ErrorName)
+	assert.Equal(t, expect.Type, qf.ErrorType)
+	if expect.Line != 0 {
+		require.NotNil(t, qf.Location, "error location")
+		assert.Equal(t, expect.Line, qf.Location.LineNumber)
+		assert.Equal(t, expect.Column, qf.Location.ColumnNumber)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrConversion is returned when scanning a value that cannot be converted
+// to the Go type of its column.
+type ErrConversion struct {
+	Column    string      // Name of the column
+	Index     int         // Index of the column
+	TrinoType string      // Type of the column, e.g. bigint or array(varchar)
+	GoType    string      // Go type the value is converted to, e.g. int64
+	Value     interface{} // Value as decoded from the response
+	Err       error
+}
+
+// Error implements the error interface.
+func (e *ErrConversion) Error() string {
+	return fmt.Sprintf("trino: cannot convert column %q (index %d) of type %s to %s: %v",
+		e.Column, e.Index, e.TrinoType, e.GoType, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ErrConversion) Unwrap() error {
+	return e.Err
+}
+
+// goType returns the name of the Go type values of the column are
+// converted to.
+func (c *typeConverter) goType() string {
+	switch c.parsedType[0] {
+	case "boolean":
+		return "bool"
+	case "json", "char", "varchar", "varbinary", "interval year to month", "interval day to second", "decimal", "ipaddress", "uuid", "unknown":
+		return "string"
+	case "tinyint", "smallint", "integer", "bigint":
+		return "int64"
+	case "real", "double":
+		return "float64"
+	case "date", "time", "time with time zone", "timestamp", "timestamp with time zone":
+		return "time.Time"
+	case "map":
+		return "map[string]interface{}"
+	case "array":
+		return "[]interface{}"
+	case "row":
+		return "[]interface{}"
+	default:
+		return "interface{}"
+	}
+}
+
+var (
+	scanTypeNullBool    = reflect.TypeOf(sql.NullBool{})
+	scanTypeNullString  = reflect.TypeOf(sql.NullString{})
+	scanTypeNullInt64   = reflect.TypeOf(sql.NullInt64{})
+	scanTypeNullFloat64 = reflect.TypeOf(sql.NullFloat64{})
+	scanTypeNullTime    = reflect.TypeOf(sql.NullTime{})
+	scanTypeInterface   = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// scanType returns the type values of the column can be scanned into,
+// which is nullable, as any Trino column can hold NULL.
+func (c *typeConverter) scanType() reflect.Type {
+	switch c.parsedType[0] {
+	case "boolean":
+		return scanTypeNullBool
+	case "json", "char", "varchar", "varbinary", "interval year to month", "interval day to second", "decimal", "ipaddress", "uuid", "unknown":
+		return scanTypeNullString
+	case "tinyint", "smallint", "integer", "bigint":
+		return scanTypeNullInt64
+	case "real", "double":
+		return scanTypeNullFloat64
+	case "date", "time", "time with time zone", "timestamp", "timestamp with time zone":
+		return scanTypeNullTime
+	default:
+		return scanTypeInterface
+	}
+}
+
+var _ driver.RowsColumnTypeScanType = &driverRows{}
+var _ driver.RowsColumnTypeNullable = &driverRows{}
+
+// ColumnTypeScanType implements the driver.RowsColumnTypeScanType
+// interface, returning sql.NullBool, sql.NullString, sql.NullInt64,
+// sql.NullFloat64 or sql.NullTime for scalar columns, and interface{} for
+// the others, such as arrays, maps and rows. It allows libraries scanning
+// rows into values they allocate, such as sqlx and scany, to use the types
+// the driver returns.
+func (qr *driverRows) ColumnTypeScanType(index int) reflect.Type {
+	return qr.coltype[index].scanType()
+}
+
+// ColumnTypeNullable implements the driver.RowsColumnTypeNullable
+// interface. Trino does not report the nullability of the columns of a
+// result, so all of them are nullable.
+func (qr *driverRows) ColumnTypeNullable(index int) (bool, bool) {
+	return true, true
+}
+
+// convertLenient converts values that are rejected by ConvertValue, when
+// lenient_conversions is enabled: numeric strings for integer and floating
+// point columns, 0 and 1 for boolean columns, and strings that cannot be
+// parsed as temporal values, which are passed as is.
+func (c *typeConverter) convertLenient(v interface{}) (interface{}, bool) {
+	switch c.parsedType[0] {
+	case "boolean":
+		n, ok := v.(json.Number)
+		if !ok {
+			return nil, false
+		}
+		switch n.String() {
+		case "0":
+			return false, true
+		case "1":
+			return true, true
+		}
+	case "tinyint", "smallint", "integer", "bigint":
+		if s, ok := v.(string); ok {
+			if n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64); err == nil {
+				return n, true
+			}
+		}
+	case "real", "double":
+		if s, ok := v.(string); ok {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+				return f, true
+			}
+		}
+	case "date", "time", "time with time zone", "timestamp", "timestamp with time zone":
+		if s, ok := v.(string); ok {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// convertColumn converts the value of the column at index i, returning an
+// ErrConversion on failure.
+func (qr *driverRows) convertColumn(i int, v interface{}) (interface{}, error) {
+	c := qr.coltype[i]
+	vv, err := c.ConvertValue(v)
+	if err == nil {
+		return vv, nil
+	}
+	if qr.stmt.conn.lenientConversions {
+		if lv, ok := c.convertLenient(v); ok {
+			return lv, nil
+		}
+	}
+	return nil, &ErrConversion{
+		Column:    qr.columns[i],
+		Index:     i,
+		TrinoType: c.typeName,
+		GoType:    c.goType(),
+		Value:     v,
+		Err:       err,
+	}
+}
+
+// ErrScan is returned by ScanRow when a value cannot be stored in its
+// destination.
+type ErrScan struct {
+	Column    string // Name of the column
+	Index     int    // Index of the column
+	TrinoType string // Type of the column, e.g. bigint or array(varchar)
+	GoType    string // Type of the destination, e.g. *int32
+	Err       error
+}
+
+// Error implements the error interface.
+func (e *ErrScan) Error() string {
+	return fmt.Sprintf("trino: cannot scan column %q (index %d) of type %s into %s: %v",
+		e.Column, e.Index, e.TrinoType, e.GoType, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ErrScan) Unwrap() error {
+	return e.Err
+}
+
+// ScanRow calls rows.Scan, and reports failures to store a value in its
+// destination as an *ErrScan, which names the column, its index, its Trino
+// type and the type of the destination, to help debugging scans of wide
+// rows.
+func ScanRow(rows *sql.Rows, dest ...interface{}) error {
+	err := rows.Scan(dest...)
+	if err == nil {
+		return nil
+	}
+	types, terr := rows.ColumnTypes()
+	if terr != nil || len(types) != len(dest) {
+		return err
+	}
+	// scan the row again one column at a time to find the failing one,
+	// rather than parsing the error message of database/sql
+	discard := make([]interface{}, len(dest))
+	for i := range discard {
+		discard[i] = new(interface{})
+	}
+	for i := range dest {
+		single := append([]interface{}(nil), discard...)
+		single[i] = dest[i]
+		if colErr := rows.Scan(single...); colErr != nil {
+			return &ErrScan{
+				Column:    types[i].Name(),
+				Index:     i,
+				TrinoType: types[i].DatabaseTypeName(),
+				GoType:    fmt.Sprintf("%T", dest[i]),
+				Err:       colErr,
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,111 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const implicitConversionsResult = `
+	"columns": [
+		{"name": "i", "type": "bigint"},
+		{"name": "b", "type": "boolean"},
+		{"name": "ts", "type": "timestamp(3)"}
+	],
+	"data": [["42", 1, "+10000-01-01 00:00:00.000"]]`
+
+func TestStrictConversions(t *testing.T) {
+	ts := newResultTestServer(t, implicitConversionsResult, nil)
+	db := openTestDB(t, ts)
+
+	var i int64
+	var b bool
+	var s string
+	err := db.QueryRow("SELECT *").Scan(&i, &b, &s)
+	var convErr *ErrConversion
+	require.True(t, errors.As(err, &convErr), "unexpected error: %v", err)
+	assert.Equal(t, "i", convErr.Column)
+	assert.Equal(t, 0, convErr.Index)
+	assert.Equal(t, "bigint", convErr.TrinoType)
+	assert.Equal(t, "int64", convErr.GoType)
+	assert.Equal(t, "42", convErr.Value)
+	assert.Contains(t, err.Error(), `column "i" (index 0) of type bigint to int64`)
+}
+
+func TestLenientConversions(t *testing.T) {
+	ts := newResultTestServer(t, implicitConversionsResult, nil)
+	db, err := sql.Open("trino", ts.URL+"?lenient_conversions=true")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	var i int64
+	var b bool
+	var s string
+	require.NoError(t, db.QueryRow("SELECT *").Scan(&i, &b, &s))
+	assert.Equal(t, int64(42), i)
+	assert.True(t, b)
+	assert.Equal(t, "+10000-01-01 00:00:00.000", s)
+}
+
+func TestLenientConversionsDSN(t *testing.T) {
+	c := &Config{ServerURI: "http://foobar@localhost:8080", LenientConversions: true}
+	dsn, err := c.FormatDSN()
+	require.NoError(t, err)
+	assert.Equal(t, "http://foobar@localhost:8080?lenient_conversions=true&source=trino-go-client", dsn)
+
+	_, err = newConn("http://foobar@localhost:8080?lenient_conversions=maybe")
+	assert.EqualError(t, err, `trino: invalid lenient_conversions: "maybe"`)
+}
+
+func TestScanRow(t *testing.T) {
+	ts := newResultTestServer(t, `
+		"columns": [
+			{"name": "name", "type": "varchar"},
+			{"name": "total", "type": "bigint"}
+		],
+		"data": [["a", 3000000000]]`, nil)
+	db := openTestDB(t, ts)
+
+	rows, err := db.Query("SELECT *")
+	require.NoError(t, err)
+	defer rows.Close()
+	require.True(t, rows.Next())
+
+	var name string
+	var total int32
+	err = ScanRow(rows, &name, &total)
+	var scanErr *ErrScan
+	require.True(t, errors.As(err, &scanErr), "unexpected error: %v", err)
+	assert.Equal(t, "total", scanErr.Column)
+	assert.Equal(t, 1, scanErr.Index)
+	assert.Equal(t, "bigint", scanErr.TrinoType)
+	assert.Equal(t, "*int32", scanErr.GoType)
+	// the cause is the failure to scan the column
+	cause := errors.Unwrap(err)
+	require.NotNil(t, cause)
+	assert.Contains(t, cause.Error(), `column index 1, name "total"`)
+	assert.Contains(t, cause.Error(), "out of range")
+
+	var total64 int64
+	require.NoError(t, ScanRow(rows, &name, &total64))
+	assert.Equal(t, int64(3000000000), total64)
+}
@@ -0,0 +1,86 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"time"
+)
+
+// QueryCost holds the resources used by a query, as reported by Trino, to
+// attribute the cost of queries, e.g. for chargeback.
+type QueryCost struct {
+	QueryID            string
+	User               string
+	ProcessedRows      int64 // Number of rows read from the sources
+	ProcessedBytes     int64 // Size of the rows read from the sources
+	PhysicalInputBytes int64 // Size of the data read from storage
+	PeakMemoryBytes    int64
+	CPUTime            time.Duration
+	WallTime           time.Duration
+}
+
+// CostReporter is implemented by the driver.Rows and driver.Result of the
+// driver, for applications using it directly rather than with database/sql.
+type CostReporter interface {
+	// QueryCost returns the resources used by the query so far.
+	QueryCost() QueryCost
+}
+
+var (
+	_ CostReporter = &driverRows{}
+)
+
+// QueryCostHandler receives the resources used by a query, once it
+// completed or was closed.
+type QueryCostHandler func(cost QueryCost)
+
+type queryCostHandlerKey struct{}
+
+// WithQueryCost returns a context that makes queries executed with it pass
+// the resources they used to fn. For queries closed before completing, the
+// resources are the ones reported with the last page read.
+func WithQueryCost(ctx context.Context, fn QueryCostHandler) context.Context {
+	return context.WithValue(ctx, queryCostHandlerKey{}, fn)
+}
+
+func queryCostHandlerFromContext(ctx context.Context) QueryCostHandler {
+	fn, _ := ctx.Value(queryCostHandlerKey{}).(QueryCostHandler)
+	return fn
+}
+
+// QueryCost implements the CostReporter interface.
+func (qr *driverRows) QueryCost() QueryCost {
+	user := qr.stmt.user
+	if user == "" {
+		user = qr.stmt.conn.httpHeaders.Get(trinoUserHeader)
+	}
+	return QueryCost{
+		QueryID:            qr.queryID,
+		User:               user,
+		ProcessedRows:      int64(qr.stats.ProcessedRows),
+		ProcessedBytes:     int64(qr.stats.ProcessedBytes),
+		PhysicalInputBytes: qr.stats.PhysicalInputBytes,
+		PeakMemoryBytes:    qr.stats.PeakMemoryBytes,
+		CPUTime:            time.Duration(qr.stats.CPUTimeMillis) * time.Millisecond,
+		WallTime:           time.Duration(qr.stats.WallTimeMillis) * time.Millisecond,
+	}
+}
+
+func (qr *driverRows) reportQueryCost() {
+	if fn := queryCostHandlerFromContext(qr.ctx); fn != nil {
+		fn(qr.QueryCost())
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCost(t *testing.T) {
+	ts := newResultTestServer(t, `
+		"columns": [{"name": "n", "type": "bigint"}],
+		"data": [[1]],
+		"stats": {
+			"state": "FINISHED",
+			"processedRows": 1000,
+			"processedBytes": 8000,
+			"physicalInputBytes": 4096,
+			"peakMemoryBytes": 1048576,
+			"cpuTimeMillis": 1500,
+			"wallTimeMillis": 3000
+		}`, nil)
+	db := openTestDB(t, ts)
+
+	var costs []QueryCost
+	ctx := WithQueryCost(context.Background(), func(cost QueryCost) {
+		costs = append(costs, cost)
+	})
+	var n int64
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT n FROM t", sql.Named("X-Trino-User", "alice")).Scan(&n))
+	_, err := db.ExecContext(ctx, "INSERT INTO t VALUES (1)")
+	require.NoError(t, err)
+
+	require.Len(t, costs, 2)
+	assert.Equal(t, QueryCost{
+		QueryID:            "20210101_000000_00000_abcde",
+		User:               "alice",
+		ProcessedRows:      1000,
+		ProcessedBytes:     8000,
+		PhysicalInputBytes: 4096,
+		PeakMemoryBytes:    1048576,
+		CPUTime:            1500 * time.Millisecond,
+		WallTime:           3 * time.Second,
+	}, costs[0])
+	assert.Equal(t, int64(8000), costs[1].ProcessedBytes)
+}
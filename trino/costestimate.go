@@ -0,0 +1,136 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// CostEstimate is the cost of a query estimated by the cost based optimizer
+// of Trino, before running it. Estimates that are unknown, e.g. for tables
+// without statistics, are NaN.
+type CostEstimate struct {
+	OutputRows  float64 // Number of rows returned
+	OutputBytes float64 // Size of the rows returned
+	CPUCost     float64 // CPU cost, in bytes processed
+	MemoryCost  float64 // Peak memory, in bytes
+	NetworkCost float64 // Data exchanged between nodes, in bytes
+	Plan        *Plan
+}
+
+// CostLimits are the thresholds of CostEstimate.Check. Zero limits are not
+// checked.
+type CostLimits struct {
+	OutputRows    float64
+	CPUCost       float64
+	MemoryCost    float64
+	NetworkCost   float64
+	RejectUnknown bool // Reject queries whose checked estimates are unknown
+}
+
+// ErrCostExceeded is returned by CostEstimate.Check for queries whose
+// estimated cost exceeds a limit, or is unknown with RejectUnknown.
+type ErrCostExceeded struct {
+	Estimate string  // e.g. "cpu" or "memory"
+	Value    float64 // Estimated value, NaN when unknown
+	Limit    float64
+}
+
+// Error implements the error interface.
+func (e *ErrCostExceeded) Error() string {
+	if math.IsNaN(e.Value) {
+		return fmt.Sprintf("trino: estimated %s cost is unknown", e.Estimate)
+	}
+	return fmt.Sprintf("trino: estimated %s cost %.0f exceeds the limit of %.0f", e.Estimate, e.Value, e.Limit)
+}
+
+// EstimateCost returns the estimated cost of a query, from its plan returned
+// by EXPLAIN (FORMAT JSON), without running it. Check the estimate against
+// limits to refuse to run expensive queries:
+//
+//	estimate, err := trino.EstimateCost(ctx, db, query)
+//	if err != nil {
+//		return err
+//	}
+//	if err := estimate.Check(trino.CostLimits{MemoryCost: 10 << 30}); err != nil {
+//		return err
+//	}
+//	rows, err := db.QueryContext(ctx, query)
+//
+// Estimates depend on the statistics of the tables, and may be far from
+// the actual cost.
+func EstimateCost(ctx context.Context, q Queryer, query string) (*CostEstimate, error) {
+	plan, err := Explain(ctx, q, query, ExplainJSON)
+	if err != nil {
+		return nil, err
+	}
+	estimate := &CostEstimate{
+		OutputRows:  math.NaN(),
+		OutputBytes: math.NaN(),
+		CPUCost:     math.NaN(),
+		MemoryCost:  math.NaN(),
+		NetworkCost: math.NaN(),
+		Plan:        plan,
+	}
+	if plan.Root == nil {
+		return estimate, nil
+	}
+	if len(plan.Root.Estimates) > 0 {
+		estimate.OutputRows = plan.Root.Estimates[0].OutputRowCount
+		estimate.OutputBytes = plan.Root.Estimates[0].OutputSizeInBytes
+	}
+	// costs accumulate from the leaves to the root, but the root may lack
+	// estimates that nodes below it have, so the largest ones are used
+	plan.Root.Walk(func(n *PlanNode) bool {
+		for _, e := range n.Estimates {
+			estimate.CPUCost = maxEstimate(estimate.CPUCost, e.CPUCost)
+			estimate.MemoryCost = maxEstimate(estimate.MemoryCost, e.MemoryCost)
+			estimate.NetworkCost = maxEstimate(estimate.NetworkCost, e.NetworkCost)
+		}
+		return true
+	})
+	return estimate, nil
+}
+
+// maxEstimate returns the largest of two estimates, ignoring unknown ones.
+func maxEstimate(a, b float64) float64 {
+	if math.IsNaN(a) || b > a {
+		return b
+	}
+	return a
+}
+
+// Check returns an *ErrCostExceeded if an estimate exceeds its limit.
+func (e *CostEstimate) Check(limits CostLimits) error {
+	for _, c := range []struct {
+		estimate     string
+		value, limit float64
+	}{
+		{"output rows", e.OutputRows, limits.OutputRows},
+		{"cpu", e.CPUCost, limits.CPUCost},
+		{"memory", e.MemoryCost, limits.MemoryCost},
+		{"network", e.NetworkCost, limits.NetworkCost},
+	} {
+		if c.limit <= 0 {
+			continue
+		}
+		if c.value > c.limit || math.IsNaN(c.value) && limits.RejectUnknown {
+			return &ErrCostExceeded{Estimate: c.estimate, Value: c.value, Limit: c.limit}
+		}
+	}
+	return nil
+}
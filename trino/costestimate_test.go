@@ -0,0 +1,80 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateCost(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, explainResult(t, `{
+		"id": "6",
+		"name": "Output",
+		"descriptor": {},
+		"outputs": [],
+		"details": [],
+		"estimates": [{"outputRowCount": 25.0, "outputSizeInBytes": 575.0, "cpuCost": "NaN", "memoryCost": 0.0, "networkCost": 575.0}],
+		"children": [{
+			"id": "5",
+			"name": "Aggregate",
+			"descriptor": {},
+			"outputs": [],
+			"details": [],
+			"estimates": [{"outputRowCount": 25.0, "outputSizeInBytes": 575.0, "cpuCost": 9000.0, "memoryCost": 4096.0, "networkCost": 575.0}],
+			"children": [{
+				"id": "0",
+				"name": "TableScan",
+				"descriptor": {},
+				"outputs": [],
+				"details": [],
+				"estimates": [{"outputRowCount": 1500.0, "outputSizeInBytes": 13500.0, "cpuCost": 13500.0, "memoryCost": 0.0, "networkCost": 0.0}],
+				"children": []
+			}]
+		}]
+	}`), &queries)
+	db := openTestDB(t, ts)
+
+	estimate, err := EstimateCost(context.Background(), db, "SELECT nationkey, count(*) FROM customer GROUP BY 1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"EXPLAIN (FORMAT JSON) SELECT nationkey, count(*) FROM customer GROUP BY 1"}, queries)
+	assert.Equal(t, 25.0, estimate.OutputRows)
+	assert.Equal(t, 575.0, estimate.OutputBytes)
+	assert.Equal(t, 13500.0, estimate.CPUCost)
+	assert.Equal(t, 4096.0, estimate.MemoryCost)
+	assert.Equal(t, 575.0, estimate.NetworkCost)
+	require.NotNil(t, estimate.Plan.Root)
+	assert.True(t, math.IsNaN(estimate.Plan.Root.Estimates[0].CPUCost))
+
+	assert.NoError(t, estimate.Check(CostLimits{CPUCost: 20000, MemoryCost: 8192}))
+	err = estimate.Check(CostLimits{CPUCost: 20000, MemoryCost: 1024})
+	var cerr *ErrCostExceeded
+	require.True(t, errors.As(err, &cerr))
+	assert.Equal(t, "memory", cerr.Estimate)
+	assert.EqualError(t, err, "trino: estimated memory cost 4096 exceeds the limit of 1024")
+}
+
+func TestCheckUnknownCost(t *testing.T) {
+	estimate := &CostEstimate{OutputRows: math.NaN(), CPUCost: math.NaN(), MemoryCost: 10, NetworkCost: math.NaN()}
+	assert.NoError(t, estimate.Check(CostLimits{CPUCost: 100}))
+	assert.EqualError(t, estimate.Check(CostLimits{CPUCost: 100, RejectUnknown: true}), "trino: estimated cpu cost is unknown")
+	assert.NoError(t, estimate.Check(CostLimits{MemoryCost: 100, RejectUnknown: true}))
+}
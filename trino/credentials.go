@@ -0,0 +1,62 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// errInvalidExtraCredentials does not include the value of the parameter,
+// which holds secrets.
+var errInvalidExtraCredentials = errors.New("trino: invalid extra_credentials")
+
+// parseExtraCredentials returns the value of the extra credential header
+// from the extra_credentials parameter, a comma separated list of
+// name=value, escaped like session_properties. Values are URL encoded in
+// the header, as Trino decodes them, so that they may contain any
+// character.
+func parseExtraCredentials(v string) (string, error) {
+	names, values, ok := splitSessionProperties(v)
+	if !ok {
+		return "", errInvalidExtraCredentials
+	}
+	credentials := make([]string, len(names))
+	for i, name := range names {
+		credentials[i] = name + "=" + url.QueryEscape(values[i])
+	}
+	return strings.Join(credentials, ","), nil
+}
+
+// formatExtraCredentials returns the value of the extra_credentials
+// parameter, with the credentials sorted by name.
+func formatExtraCredentials(credentials map[string]string) (string, error) {
+	names := make([]string, 0, len(credentials))
+	for name := range credentials {
+		if name == "" || strings.ContainsAny(name, "=,\\") {
+			return "", fmt.Errorf("trino: invalid extra credential name %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	entries := make([]string, len(names))
+	for i, name := range names {
+		entries[i] = name + "=" + sessionPropertyEscaper.Replace(credentials[name])
+	}
+	return strings.Join(entries, ","), nil
+}
@@ -0,0 +1,61 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtraCredentialsHeader(t *testing.T) {
+	var headers http.Header
+	ts := newHeaderTestServer(t, `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`, &headers)
+
+	dsn, err := (&Config{
+		ServerURI: ts.URL,
+		ExtraCredentials: map[string]string{
+			"token":  "a+b/c=",
+			"region": `eu,west\1`,
+		},
+	}).FormatDSN()
+	require.NoError(t, err)
+	u, err := url.Parse(dsn)
+	require.NoError(t, err)
+	assert.Equal(t, `region=eu\,west\\1,token=a+b/c=`, u.Query().Get("extra_credentials"))
+
+	db, err := sql.Open("trino", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+	require.NoError(t, db.QueryRow("SELECT 1").Scan(new(int)))
+	assert.Equal(t, []string{`region=eu%2Cwest%5C1,token=a%2Bb%2Fc%3D`}, headers[trinoExtraCredentialHeader])
+}
+
+func TestExtraCredentialsInvalid(t *testing.T) {
+	_, err := newConn("http://foobar@localhost:8080?extra_credentials=secret")
+	assert.EqualError(t, err, "trino: invalid extra_credentials")
+
+	_, err = (&Config{
+		ServerURI:        "http://foobar@localhost:8080",
+		ExtraCredentials: map[string]string{"a,b": "secret"},
+	}).FormatDSN()
+	assert.EqualError(t, err, `trino: invalid extra credential name "a,b"`)
+}
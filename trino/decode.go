@@ -0,0 +1,231 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// JSONDecoder decodes the JSON documents sent by Trino, such as result pages.
+//
+// Implementations must decode JSON numbers into json.Number when decoding
+// into an interface{}, like json.Decoder does after calling UseNumber, so
+// that no precision is lost before values are converted.
+// This allows using faster JSON libraries than encoding/json, for example:
+//
+//	type jsoniterDecoder struct{}
+//
+//	var api = jsoniter.Config{UseNumber: true}.Froze()
+//
+//	func (jsoniterDecoder) Decode(r io.Reader, v interface{}) error {
+//		return api.NewDecoder(r).Decode(v)
+//	}
+type JSONDecoder interface {
+	Decode(r io.Reader, v interface{}) error
+}
+
+// stdJSONDecoder decodes JSON documents using encoding/json.
+type stdJSONDecoder struct{}
+
+func (stdJSONDecoder) Decode(r io.Reader, v interface{}) error {
+	d := json.NewDecoder(r)
+	d.UseNumber()
+	return d.Decode(v)
+}
+
+type scannedColumnsKey struct{}
+
+// WithScannedColumns returns a context that makes queries executed with it
+// only decode the values of the given columns. The values of all other
+// columns are returned as NULL, saving the cost of decoding them on wide
+// tables when only a few fields are needed.
+func WithScannedColumns(ctx context.Context, columns ...string) context.Context {
+	return context.WithValue(ctx, scannedColumnsKey{}, columns)
+}
+
+func scannedColumnsFromContext(ctx context.Context) ([]string, bool) {
+	columns, ok := ctx.Value(scannedColumnsKey{}).([]string)
+	return columns, ok
+}
+
+type columnMode int
+
+const (
+	decodeColumn columnMode = iota
+	rawColumn
+	skipColumn
+)
+
+// columnModes returns how the values of each column should be decoded,
+// or nil if all of them should be decoded.
+func (qr *driverRows) columnModes(names []string) []columnMode {
+	rawColumns, raw := rawValuesFromContext(qr.ctx)
+	scannedColumns, scanned := scannedColumnsFromContext(qr.ctx)
+	if !raw && !scanned {
+		return nil
+	}
+	modes := make([]columnMode, len(names))
+	for i, name := range names {
+		switch {
+		case scanned && !containsString(scannedColumns, name):
+			modes[i] = skipColumn
+		case raw && (len(rawColumns) == 0 || containsString(rawColumns, name)):
+			modes[i] = rawColumn
+		}
+	}
+	return modes
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeQueryResponse decodes a query response, keeping the values of the
+// columns requested with WithRawValues as json.RawMessage, skipping the
+// values of the columns not requested with WithScannedColumns, and keeping
+// strings in the data of the page with WithZeroCopyStrings. The names of
+// the columns are used when the response does not include them.
+//
+// The rows are then decoded in a single pass over the data of the page by a
+// valueScanner, which skips the values of the columns that are not scanned
+// without allocating them.
+func (qr *driverRows) decodeQueryResponse(r io.Reader, qresp *queryResponse, names []string) error {
+	decoder := qr.stmt.conn.jsonDecoder
+	_, raw := rawValuesFromContext(qr.ctx)
+	_, scanned := scannedColumnsFromContext(qr.ctx)
+	if !raw && !scanned && !qr.zeroCopyStringsEnabled() {
+		return decoder.Decode(r, qresp)
+	}
+	var rresp struct {
+		queryResponse
+		Data json.RawMessage `json:"data"`
+	}
+	if err := decoder.Decode(r, &rresp); err != nil {
+		return err
+	}
+	*qresp = rresp.queryResponse
+	if len(qresp.Columns) > 0 {
+		names = make([]string, len(qresp.Columns))
+		for i, col := range qresp.Columns {
+			names[i] = col.Name
+		}
+	}
+	data, err := qr.decodeRows(rresp.Data, names)
+	if err != nil {
+		return err
+	}
+	qresp.Data = data
+	return nil
+}
+
+// decodeRows decodes the data of a page, a JSON array of rows, applying the
+// modes of the columns with the given names.
+func (qr *driverRows) decodeRows(page json.RawMessage, names []string) ([]queryData, error) {
+	s := &valueScanner{buf: page, aliasStrings: qr.zeroCopyStringsEnabled()}
+	if s.next() == 0 || string(page) == "null" {
+		return nil, nil
+	}
+	if !s.consume('[') {
+		return nil, s.syntaxError()
+	}
+	modes := qr.columnModes(names)
+	var result []queryData
+	if s.consume(']') {
+		return result, nil
+	}
+	for {
+		if !s.consume('[') {
+			return nil, s.syntaxError()
+		}
+		data := make(queryData, 0, len(names))
+		if !s.consume(']') {
+			for j := 0; ; j++ {
+				v, err := s.column(modes, j)
+				if err != nil {
+					return nil, err
+				}
+				data = append(data, v)
+				if s.consume(']') {
+					break
+				}
+				if !s.consume(',') {
+					return nil, s.syntaxError()
+				}
+			}
+		}
+		result = append(result, data)
+		if s.consume(']') {
+			return result, nil
+		}
+		if !s.consume(',') {
+			return nil, s.syntaxError()
+		}
+	}
+}
+
+// column decodes the next value, of the column at index j.
+func (s *valueScanner) column(modes []columnMode, j int) (interface{}, error) {
+	mode := decodeColumn
+	if j < len(modes) {
+		mode = modes[j]
+	}
+	switch mode {
+	case rawColumn:
+		s.skipSpace()
+		start := s.pos
+		if err := s.skip(); err != nil {
+			return nil, err
+		}
+		return json.RawMessage(s.buf[start:s.pos]), nil
+	case skipColumn:
+		return nil, s.skip()
+	}
+	return s.value()
+}
+
+// decodeData decodes the rows of the response to the submission of a
+// query, applying the modes of the columns with the given names.
+func (qr *driverRows) decodeData(rows [][]json.RawMessage, names []string) ([]queryData, error) {
+	decoder := qr.stmt.conn.jsonDecoder
+	_, std := decoder.(stdJSONDecoder)
+	modes := qr.columnModes(names)
+	s := valueScanner{aliasStrings: qr.zeroCopyStringsEnabled()}
+	result := make([]queryData, len(rows))
+	for i, row := range rows {
+		data := make(queryData, len(row))
+		for j, value := range row {
+			var err error
+			s.reset(value)
+			if std || s.aliasStrings || (j < len(modes) && modes[j] != decodeColumn) {
+				data[j], err = s.column(modes, j)
+			} else {
+				err = decoder.Decode(bytes.NewReader(value), &data[j])
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		result[i] = data
+	}
+	return result, nil
+}
@@ -0,0 +1,97 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeQueryResponseScannedColumns(t *testing.T) {
+	body := `{
+		"columns": [
+			{"name": "a", "type": "integer"},
+			{"name": "b", "type": "varchar"},
+			{"name": "c", "type": "double"}
+		],
+		"data": [[1, "skipped", 1.5], [2, "skipped", 2.5]]
+	}`
+
+	testcases := []struct {
+		Name string
+		Ctx  context.Context
+		Want []queryData
+	}{
+		{
+			Name: "scanned",
+			Ctx:  WithScannedColumns(context.Background(), "a", "c"),
+			Want: []queryData{{json.Number("1"), nil, json.Number("1.5")}, {json.Number("2"), nil, json.Number("2.5")}},
+		},
+		{
+			Name: "scanned_raw",
+			Ctx:  WithRawValues(WithScannedColumns(context.Background(), "c"), "c"),
+			Want: []queryData{{nil, nil, json.RawMessage("1.5")}, {nil, nil, json.RawMessage("2.5")}},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.Name, func(t *testing.T) {
+			qr := &driverRows{ctx: tc.Ctx, stmt: &driverStmt{conn: &Conn{jsonDecoder: stdJSONDecoder{}}}}
+			var qresp queryResponse
+			require.NoError(t, qr.decodeQueryResponse(strings.NewReader(body), &qresp, nil))
+			assert.Equal(t, tc.Want, qresp.Data)
+		})
+	}
+}
+
+type countingJSONDecoder struct {
+	calls int
+}
+
+func (d *countingJSONDecoder) Decode(r io.Reader, v interface{}) error {
+	d.calls++
+	return stdJSONDecoder{}.Decode(r, v)
+}
+
+func TestCustomJSONDecoder(t *testing.T) {
+	ts := newResultTestServer(t, rawValuesTestResult, nil)
+
+	decoder := &countingJSONDecoder{}
+	connector, err := NewConnector(&Config{
+		ServerURI:   ts.URL,
+		JSONDecoder: decoder,
+	})
+	require.NoError(t, err)
+
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	var d float64
+	var m NullMap
+	var n sql.NullInt64
+	require.NoError(t, db.QueryRow("SELECT 1").Scan(&d, &m, &n))
+	assert.Equal(t, 1.1, d)
+	assert.Equal(t, map[string]interface{}{"a": json.Number("1")}, m.Map)
+	assert.Equal(t, 2, decoder.calls, "statement and page responses should use the custom decoder")
+}
@@ -0,0 +1,159 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// nullTypeNames are the Trino types of the nullable types of database/sql.
+var nullTypeNames = map[reflect.Type]string{
+	reflect.TypeOf(sql.NullBool{}):    "boolean",
+	reflect.TypeOf(sql.NullInt32{}):   "integer",
+	reflect.TypeOf(sql.NullInt64{}):   "bigint",
+	reflect.TypeOf(sql.NullFloat64{}): "double",
+	reflect.TypeOf(sql.NullString{}):  "varchar",
+	reflect.TypeOf(sql.NullTime{}):    "timestamp(6)",
+}
+
+// kindTypeNames are the Trino types of the Go types of each kind.
+var kindTypeNames = map[reflect.Kind]string{
+	reflect.Bool:    "boolean",
+	reflect.Int8:    "tinyint",
+	reflect.Int16:   "smallint",
+	reflect.Int32:   "integer",
+	reflect.Int64:   "bigint",
+	reflect.Int:     "bigint",
+	reflect.Uint8:   "smallint",
+	reflect.Uint16:  "integer",
+	reflect.Uint32:  "bigint",
+	reflect.Uint64:  "decimal(20,0)",
+	reflect.Uint:    "decimal(20,0)",
+	reflect.Float32: "real",
+	reflect.Float64: "double",
+	reflect.String:  "varchar",
+}
+
+// TypeName returns the Trino type storing the values of a Go type, such as
+// bigint for int64, varchar for sql.NullString or array(varchar) for
+// []string, e.g. for ORM dialects creating the table of a model. Pointers
+// are stored as their element type.
+func TypeName(t reflect.Type) (string, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return "timestamp(6)", nil
+	case t == rawMessageType:
+		return "json", nil
+	}
+	if name, ok := nullTypeNames[t]; ok {
+		return name, nil
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "varbinary", nil
+		}
+		elem, err := TypeName(t.Elem())
+		if err != nil {
+			return "", err
+		}
+		return "array(" + elem + ")", nil
+	case reflect.Map:
+		key, err := TypeName(t.Key())
+		if err != nil {
+			return "", err
+		}
+		elem, err := TypeName(t.Elem())
+		if err != nil {
+			return "", err
+		}
+		return "map(" + key + ", " + elem + ")", nil
+	}
+	if name, ok := kindTypeNames[t.Kind()]; ok {
+		return name, nil
+	}
+	return "", fmt.Errorf("trino: no Trino type for %s", t)
+}
+
+// ScanTypeOf returns the type the values of a Trino type are scanned into,
+// the same as the ScanType of the columns of this type.
+func ScanTypeOf(trinoType string) reflect.Type {
+	return newTypeConverter(trinoType).scanType()
+}
+
+// LimitOffset returns the clauses skipping offset rows and returning at most
+// limit rows, to append to a query, in the order Trino requires. Negative
+// values omit the clause.
+func LimitOffset(limit, offset int64) string {
+	var clause string
+	if offset > 0 {
+		clause = " OFFSET " + strconv.FormatInt(offset, 10)
+	}
+	if limit >= 0 {
+		clause += " LIMIT " + strconv.FormatInt(limit, 10)
+	}
+	return clause
+}
+
+// queryErrorName returns the name of the error of a failed query, e.g.
+// TABLE_NOT_FOUND, or an empty string for other errors.
+func queryErrorName(err error) string {
+	var qerr *ErrQueryFailed
+	if errors.As(err, &qerr) {
+		return qerr.ErrorName
+	}
+	return ""
+}
+
+// IsNotFound reports whether a query failed because an object it refers
+// to, such as a catalog, schema, table, column or function, does not exist.
+func IsNotFound(err error) bool {
+	switch queryErrorName(err) {
+	case "NOT_FOUND", "CATALOG_NOT_FOUND", "SCHEMA_NOT_FOUND", "TABLE_NOT_FOUND", "COLUMN_NOT_FOUND", "FUNCTION_NOT_FOUND":
+		return true
+	}
+	return false
+}
+
+// IsAlreadyExists reports whether a query failed because the object it
+// creates, such as a schema, table or column, already exists.
+func IsAlreadyExists(err error) bool {
+	switch queryErrorName(err) {
+	case "ALREADY_EXISTS", "SCHEMA_ALREADY_EXISTS", "TABLE_ALREADY_EXISTS", "COLUMN_ALREADY_EXISTS":
+		return true
+	}
+	return false
+}
+
+// IsSyntaxError reports whether a query failed to be parsed.
+func IsSyntaxError(err error) bool {
+	return queryErrorName(err) == "SYNTAX_ERROR"
+}
+
+// IsPermissionDenied reports whether a query failed because the user is not
+// allowed to run it.
+func IsPermissionDenied(err error) bool {
+	return queryErrorName(err) == "PERMISSION_DENIED"
+}
@@ -0,0 +1,84 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeName(t *testing.T) {
+	for _, tc := range []struct {
+		Value    interface{}
+		Expected string
+	}{
+		{true, "boolean"},
+		{int8(1), "tinyint"},
+		{int32(1), "integer"},
+		{1, "bigint"},
+		{uint64(1), "decimal(20,0)"},
+		{float32(1), "real"},
+		{"s", "varchar"},
+		{new(string), "varchar"},
+		{[]byte{}, "varbinary"},
+		{time.Time{}, "timestamp(6)"},
+		{sql.NullInt64{}, "bigint"},
+		{sql.NullTime{}, "timestamp(6)"},
+		{json.RawMessage{}, "json"},
+		{[]string{}, "array(varchar)"},
+		{map[string][]float64{}, "map(varchar, array(double))"},
+	} {
+		t.Run(fmt.Sprintf("%T", tc.Value), func(t *testing.T) {
+			name, err := TypeName(reflect.TypeOf(tc.Value))
+			require.NoError(t, err)
+			assert.Equal(t, tc.Expected, name)
+		})
+	}
+
+	_, err := TypeName(reflect.TypeOf(struct{}{}))
+	assert.EqualError(t, err, "trino: no Trino type for struct {}")
+}
+
+func TestScanTypeOf(t *testing.T) {
+	assert.Equal(t, reflect.TypeOf(sql.NullString{}), ScanTypeOf("varchar(10)"))
+	assert.Equal(t, reflect.TypeOf(sql.NullTime{}), ScanTypeOf("timestamp(3) with time zone"))
+	assert.Equal(t, reflect.TypeOf((*interface{})(nil)).Elem(), ScanTypeOf("array(bigint)"))
+}
+
+func TestLimitOffset(t *testing.T) {
+	assert.Equal(t, " OFFSET 20 LIMIT 10", LimitOffset(10, 20))
+	assert.Equal(t, " LIMIT 0", LimitOffset(0, 0))
+	assert.Equal(t, " OFFSET 5", LimitOffset(-1, 5))
+	assert.Equal(t, "", LimitOffset(-1, -1))
+}
+
+func TestErrorPredicates(t *testing.T) {
+	failed := func(name string) error {
+		return fmt.Errorf("wrapped: %w", &ErrQueryFailed{ErrorName: name})
+	}
+	assert.True(t, IsNotFound(failed("TABLE_NOT_FOUND")))
+	assert.False(t, IsNotFound(failed("SYNTAX_ERROR")))
+	assert.True(t, IsAlreadyExists(failed("TABLE_ALREADY_EXISTS")))
+	assert.True(t, IsSyntaxError(failed("SYNTAX_ERROR")))
+	assert.True(t, IsPermissionDenied(failed("PERMISSION_DENIED")))
+	assert.False(t, IsNotFound(sql.ErrNoRows))
+}
@@ -0,0 +1,129 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// dialFunc is the type of http.Transport.DialContext.
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// dnsCache caches the addresses of the hosts dialed by a transport for a
+// fixed time, whatever the TTL of their DNS records, and rotates through
+// them, so that the connections to a coordinator behind a Kubernetes
+// service, or any name with several addresses, are spread over all of its
+// endpoints. The addresses of a host are resolved again as soon as one of
+// them cannot be dialed, rather than waiting for the TTL to expire, so
+// that stale endpoints are dropped.
+type dnsCache struct {
+	ttl    time.Duration
+	dial   dialFunc
+	lookup func(ctx context.Context, host string) ([]string, error)
+
+	mu      sync.Mutex
+	entries map[string]*dnsEntry
+}
+
+type dnsEntry struct {
+	addrs   []string
+	expires time.Time
+	next    int // index of the address dialed first by the next connection
+}
+
+func newDNSCache(ttl time.Duration, dial dialFunc) *dnsCache {
+	return &dnsCache{
+		ttl:     ttl,
+		dial:    dial,
+		lookup:  net.DefaultResolver.LookupHost,
+		entries: make(map[string]*dnsEntry),
+	}
+}
+
+// addrs returns the addresses of the host, in the order they should be
+// dialed.
+func (c *dnsCache) addrs(ctx context.Context, host string) ([]string, error) {
+	c.mu.Lock()
+	e, ok := c.entries[host]
+	if ok && time.Now().Before(e.expires) {
+		addrs := rotate(e.addrs, e.next)
+		e.next = (e.next + 1) % len(e.addrs)
+		c.mu.Unlock()
+		return addrs, nil
+	}
+	c.mu.Unlock()
+
+	addrs, err := c.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	c.mu.Lock()
+	c.entries[host] = &dnsEntry{
+		addrs:   addrs,
+		expires: time.Now().Add(c.ttl),
+		next:    1 % len(addrs),
+	}
+	c.mu.Unlock()
+	return addrs, nil
+}
+
+// evict forces the addresses of the host to be resolved again.
+func (c *dnsCache) evict(host string) {
+	c.mu.Lock()
+	delete(c.entries, host)
+	c.mu.Unlock()
+}
+
+// dialContext dials the addresses of the host of addr in turn, until a
+// connection is established.
+func (c *dnsCache) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || net.ParseIP(host) != nil {
+		return c.dial(ctx, network, addr)
+	}
+	addrs, err := c.addrs(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	err = &net.DNSError{Err: "no addresses for " + network, Name: host, IsNotFound: true}
+	for _, ip := range addrs {
+		if !matchesNetwork(ip, network) {
+			continue
+		}
+		var conn net.Conn
+		conn, err = c.dial(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+		c.evict(host)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, err
+}
+
+// rotate returns a copy of addrs starting at index i.
+func rotate(addrs []string, i int) []string {
+	rotated := make([]string, 0, len(addrs))
+	rotated = append(rotated, addrs[i:]...)
+	return append(rotated, addrs[:i]...)
+}
@@ -0,0 +1,119 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDNSCache returns a cache resolving coordinator to addrs, and
+// recording the lookups and the addresses dialed, failing for the ones in
+// down.
+func newTestDNSCache(addrs []string, down map[string]bool, lookups *int, dialed *[]string) *dnsCache {
+	c := newDNSCache(time.Hour, func(ctx context.Context, network, addr string) (net.Conn, error) {
+		*dialed = append(*dialed, addr)
+		if down[addr] {
+			return nil, errors.New("connection refused")
+		}
+		client, server := net.Pipe()
+		server.Close()
+		return client, nil
+	})
+	c.lookup = func(ctx context.Context, host string) ([]string, error) {
+		*lookups++
+		if host != "coordinator" {
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		return addrs, nil
+	}
+	return c
+}
+
+func TestDNSCacheRotatesAddresses(t *testing.T) {
+	var lookups int
+	var dialed []string
+	c := newTestDNSCache([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, nil, &lookups, &dialed)
+	for i := 0; i < 4; i++ {
+		conn, err := c.dialContext(context.Background(), "tcp", "coordinator:8080")
+		require.NoError(t, err)
+		conn.Close()
+	}
+	assert.Equal(t, []string{"10.0.0.1:8080", "10.0.0.2:8080", "10.0.0.3:8080", "10.0.0.1:8080"}, dialed)
+	assert.Equal(t, 1, lookups, "addresses are cached")
+
+	c.entries["coordinator"].expires = time.Now().Add(-time.Second)
+	conn, err := c.dialContext(context.Background(), "tcp", "coordinator:8080")
+	require.NoError(t, err)
+	conn.Close()
+	assert.Equal(t, 2, lookups, "addresses are resolved again once expired")
+}
+
+func TestDNSCacheResolvesAgainOnFailure(t *testing.T) {
+	var lookups int
+	var dialed []string
+	down := map[string]bool{"10.0.0.1:8080": true}
+	c := newTestDNSCache([]string{"10.0.0.1", "10.0.0.2"}, down, &lookups, &dialed)
+
+	conn, err := c.dialContext(context.Background(), "tcp", "coordinator:8080")
+	require.NoError(t, err)
+	conn.Close()
+	assert.Equal(t, []string{"10.0.0.1:8080", "10.0.0.2:8080"}, dialed)
+
+	_, err = c.dialContext(context.Background(), "tcp", "coordinator:8080")
+	require.NoError(t, err)
+	assert.Equal(t, 2, lookups, "a failed address evicts the host")
+
+	down["10.0.0.2:8080"] = true
+	_, err = c.dialContext(context.Background(), "tcp", "coordinator:8080")
+	assert.EqualError(t, err, "connection refused")
+
+	_, err = c.dialContext(context.Background(), "tcp", "worker:8080")
+	var dnsErr *net.DNSError
+	assert.True(t, errors.As(err, &dnsErr))
+}
+
+func TestDNSCacheDialsIPsDirectly(t *testing.T) {
+	var lookups int
+	var dialed []string
+	c := newTestDNSCache(nil, nil, &lookups, &dialed)
+	conn, err := c.dialContext(context.Background(), "tcp", "127.0.0.1:8080")
+	require.NoError(t, err)
+	conn.Close()
+	assert.Equal(t, []string{"127.0.0.1:8080"}, dialed)
+	assert.Equal(t, 0, lookups)
+}
+
+func TestDNSCacheTTLConfig(t *testing.T) {
+	dsn, err := (&Config{ServerURI: "http://foobar@localhost:8080", DNSCacheTTL: time.Minute}).FormatDSN()
+	require.NoError(t, err)
+	assert.Equal(t, "http://foobar@localhost:8080?dns_cache_ttl=1m0s&source=trino-go-client", dsn)
+
+	conn, err := newConn(dsn)
+	require.NoError(t, err)
+	transport, ok := conn.httpClient.Transport.(*http.Transport)
+	require.True(t, ok)
+	assert.NotNil(t, transport.DialContext)
+
+	_, err = newConn("http://localhost:8080?dns_cache_ttl=0s")
+	assert.EqualError(t, err, `trino: invalid dns_cache_ttl: "0s"`)
+}
@@ -0,0 +1,371 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidParameter is returned when a DSN parameter has an invalid value.
+type ErrInvalidParameter struct {
+	Parameter string
+	Value     string
+}
+
+// Error implements the error interface.
+func (e *ErrInvalidParameter) Error() string {
+	return fmt.Sprintf("trino: invalid %s: %q", e.Parameter, e.Value)
+}
+
+func invalidParameter(name, value string) error {
+	return &ErrInvalidParameter{Parameter: name, Value: value}
+}
+
+// ErrUnknownParameter is returned by DSNs with strict_dsn enabled, when
+// they have a parameter the driver does not support.
+type ErrUnknownParameter struct {
+	Parameter string
+	Valid     []string // Parameters the driver supports
+}
+
+// Error implements the error interface.
+func (e *ErrUnknownParameter) Error() string {
+	return fmt.Sprintf("trino: unknown parameter %q, valid parameters are: %s", e.Parameter, strings.Join(e.Valid, ", "))
+}
+
+// dsnParameters are the parameters of the DSN the driver supports.
+var dsnParameters = []string{
+	KerberosEnabledConfig,
+	SSLCertPathConfig,
+	"adaptive_prefetch",
+	"auto_limit",
+	"catalog",
+	"client_capabilities",
+	"confirm_cancel",
+	"custom_client",
+	"dial_timeout",
+	"disable_typed_readers",
+	"dns_cache_ttl",
+	"extra_credentials",
+	"fallback_delay",
+	"force_original_host",
+	"gzip_statement_bytes",
+	"idempotent_retries",
+	"ip_family",
+	kerberosConfigPathConfig,
+	kerberosKeytabPathConfig,
+	kerberosPrincipalConfig,
+	kerberosRealmConfig,
+	"language",
+	"lenient_conversions",
+	"location",
+	"max_buffered_bytes",
+	"max_buffered_rows",
+	"max_header_bytes",
+	"max_poll_interval",
+	"max_requests_per_second",
+	"max_response_bytes",
+	"max_statement_bytes",
+	"max_value_bytes",
+	"max_wait",
+	"partial_results",
+	"poll_interval",
+	"read_only",
+	"reconnect_timeout",
+	"request_burst",
+	"response_header_timeout",
+	"routing_group",
+	"safe_parameters",
+	"schema",
+	"session_properties",
+	"slow_query_threshold",
+	"source",
+	"spill_dir",
+	"stmt_cache_size",
+	"strict_dsn",
+	"strict_numbers",
+	"target_result_size",
+	"time_zone",
+	"tls_handshake_timeout",
+	"verify_coordinator",
+}
+
+// checkParameters returns an *ErrUnknownParameter for the first parameter
+// the driver does not support, in alphabetical order, if strict_dsn is
+// enabled.
+func checkParameters(query url.Values) error {
+	v := query.Get("strict_dsn")
+	if v == "" {
+		return nil
+	}
+	strict, err := strconv.ParseBool(v)
+	if err != nil {
+		return invalidParameter("strict_dsn", v)
+	}
+	if !strict {
+		return nil
+	}
+	known := make(map[string]bool, len(dsnParameters))
+	for _, name := range dsnParameters {
+		known[name] = true
+	}
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !known[name] {
+			valid := append([]string(nil), dsnParameters...)
+			sort.Strings(valid)
+			return &ErrUnknownParameter{Parameter: name, Valid: valid}
+		}
+	}
+	return nil
+}
+
+// validateDSN returns the error newConn would return for the parameters of
+// the DSN, without connecting or loading files such as keytabs, so that
+// invalid DSNs fail when opening the database rather than on its first
+// query. The filesystem is only accessed to check that spill_dir is an
+// existing directory.
+func validateDSN(dsn string) error {
+	serverURL, err := url.Parse(dsn)
+	if err != nil {
+		return fmt.Errorf("trino: malformed dsn: %v", redactError(err))
+	}
+	query := serverURL.Query()
+	if err := checkParameters(query); err != nil {
+		return err
+	}
+	if _, err := withTransportTimeouts(http.DefaultClient, query); err != nil {
+		return err
+	}
+	if _, err := parseClientCapabilities(query.Get("client_capabilities")); err != nil {
+		return err
+	}
+	if _, err := parseSessionProperties(query.Get("session_properties")); err != nil {
+		return err
+	}
+	if _, err := parseExtraCredentials(query.Get("extra_credentials")); err != nil {
+		return err
+	}
+	return (&Conn{location: time.Local}).parseParameters(query)
+}
+
+// parseParameters sets the options of the connection from the parameters
+// of the DSN, failing with an *ErrInvalidParameter on invalid values.
+func (c *Conn) parseParameters(query url.Values) error {
+	var err error
+	if v := query.Get("slow_query_threshold"); v != "" {
+		c.slowQuery, err = time.ParseDuration(v)
+		if err != nil {
+			return invalidParameter("slow_query_threshold", v)
+		}
+	}
+	if v := query.Get("auto_limit"); v != "" {
+		c.autoLimit, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || c.autoLimit < 0 {
+			return invalidParameter("auto_limit", v)
+		}
+	}
+	if v := query.Get("max_buffered_rows"); v != "" {
+		c.maxBufferedRows, err = strconv.Atoi(v)
+		if err != nil || c.maxBufferedRows < 0 {
+			return invalidParameter("max_buffered_rows", v)
+		}
+	}
+	if v := query.Get("adaptive_prefetch"); v != "" {
+		c.adaptivePrefetch, err = strconv.ParseBool(v)
+		if err != nil {
+			return invalidParameter("adaptive_prefetch", v)
+		}
+	}
+	if v := query.Get("spill_dir"); v != "" {
+		if fi, err := os.Stat(v); err != nil || !fi.IsDir() {
+			return invalidParameter("spill_dir", v)
+		}
+		c.spillDir = v
+	}
+	if v := query.Get("max_buffered_bytes"); v != "" {
+		c.maxBufferedBytes, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || c.maxBufferedBytes < 0 {
+			return invalidParameter("max_buffered_bytes", v)
+		}
+	}
+	if v := query.Get("max_response_bytes"); v != "" {
+		c.maxResponseBytes, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || c.maxResponseBytes < 0 {
+			return invalidParameter("max_response_bytes", v)
+		}
+	}
+	if v := query.Get("max_value_bytes"); v != "" {
+		c.maxValueBytes, err = strconv.Atoi(v)
+		if err != nil || c.maxValueBytes < 0 {
+			return invalidParameter("max_value_bytes", v)
+		}
+	}
+	if v := query.Get("gzip_statement_bytes"); v != "" {
+		c.gzipStatementBytes, err = strconv.Atoi(v)
+		if err != nil || c.gzipStatementBytes < 0 {
+			return invalidParameter("gzip_statement_bytes", v)
+		}
+	}
+	if v := query.Get("max_statement_bytes"); v != "" {
+		c.maxStatementBytes, err = strconv.Atoi(v)
+		if err != nil || c.maxStatementBytes < 0 {
+			return invalidParameter("max_statement_bytes", v)
+		}
+	}
+	if v := query.Get("max_header_bytes"); v != "" {
+		c.maxHeaderBytes, err = strconv.Atoi(v)
+		if err != nil || c.maxHeaderBytes < 0 {
+			return invalidParameter("max_header_bytes", v)
+		}
+	}
+	if v := query.Get("force_original_host"); v != "" {
+		c.forceOriginalHost, err = strconv.ParseBool(v)
+		if err != nil {
+			return invalidParameter("force_original_host", v)
+		}
+	}
+	if v := query.Get("stmt_cache_size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < 0 {
+			return invalidParameter("stmt_cache_size", v)
+		}
+		if size > 0 {
+			c.stmtCache = newStmtCache(size)
+		}
+	}
+	c.idempotentRetries = defaultIdempotentRetries
+	if v := query.Get("idempotent_retries"); v != "" {
+		c.idempotentRetries, err = strconv.Atoi(v)
+		if err != nil || c.idempotentRetries < 0 {
+			return invalidParameter("idempotent_retries", v)
+		}
+	}
+	if v := query.Get("reconnect_timeout"); v != "" {
+		c.reconnectTimeout, err = time.ParseDuration(v)
+		if err != nil || c.reconnectTimeout < 0 {
+			return invalidParameter("reconnect_timeout", v)
+		}
+	}
+	if v := query.Get("partial_results"); v != "" {
+		c.partialResults, err = strconv.ParseBool(v)
+		if err != nil {
+			return invalidParameter("partial_results", v)
+		}
+	}
+	if v := query.Get("poll_interval"); v != "" {
+		c.pollInterval, err = time.ParseDuration(v)
+		if err != nil || c.pollInterval < 0 {
+			return invalidParameter("poll_interval", v)
+		}
+		c.maxPollInterval = defaultMaxPollInterval
+	}
+	if v := query.Get("max_poll_interval"); v != "" {
+		c.maxPollInterval, err = time.ParseDuration(v)
+		if err != nil || c.maxPollInterval <= 0 {
+			return invalidParameter("max_poll_interval", v)
+		}
+	}
+	if v := query.Get("max_wait"); v != "" {
+		if c.maxWait, err = parseMaxWait(v); err != nil {
+			return err
+		}
+	}
+	if v := query.Get("target_result_size"); v != "" {
+		if c.targetResultSize, err = parseTargetResultSize(v); err != nil {
+			return err
+		}
+	}
+	if v := query.Get("strict_numbers"); v != "" {
+		c.strictNumbers, err = strconv.ParseBool(v)
+		if err != nil {
+			return invalidParameter("strict_numbers", v)
+		}
+	}
+	if v := query.Get("lenient_conversions"); v != "" {
+		c.lenientConversions, err = strconv.ParseBool(v)
+		if err != nil {
+			return invalidParameter("lenient_conversions", v)
+		}
+	}
+	if v := query.Get("disable_typed_readers"); v != "" {
+		c.noTypedReaders, err = strconv.ParseBool(v)
+		if err != nil {
+			return invalidParameter("disable_typed_readers", v)
+		}
+	}
+	if v := query.Get("verify_coordinator"); v != "" {
+		c.checkCoordinator, err = strconv.ParseBool(v)
+		if err != nil {
+			return invalidParameter("verify_coordinator", v)
+		}
+	}
+	if v := query.Get("confirm_cancel"); v != "" {
+		c.confirmCancel, err = strconv.ParseBool(v)
+		if err != nil {
+			return invalidParameter("confirm_cancel", v)
+		}
+	}
+	if v := query.Get("read_only"); v != "" {
+		c.readOnly, err = strconv.ParseBool(v)
+		if err != nil {
+			return invalidParameter("read_only", v)
+		}
+	}
+	if v := query.Get("safe_parameters"); v != "" {
+		c.safeParameters, err = strconv.ParseBool(v)
+		if err != nil {
+			return invalidParameter("safe_parameters", v)
+		}
+	}
+	if v := query.Get("time_zone"); v != "" {
+		c.location, err = loadLocation(v)
+		if err != nil {
+			return invalidParameter("time_zone", v)
+		}
+	}
+	if v := query.Get("location"); v != "" {
+		c.location, err = time.LoadLocation(v)
+		if err != nil {
+			return invalidParameter("location", v)
+		}
+	}
+	if v := query.Get("max_requests_per_second"); v != "" {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil || rate <= 0 {
+			return invalidParameter("max_requests_per_second", v)
+		}
+		burst := 1
+		if v := query.Get("request_burst"); v != "" {
+			burst, err = strconv.Atoi(v)
+			if err != nil || burst < 1 {
+				return invalidParameter("request_burst", v)
+			}
+		}
+		c.limiter = newRateLimiter(rate, burst)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvalidParameter(t *testing.T) {
+	for _, tc := range []struct {
+		DSN       string
+		Parameter string
+		Value     string
+	}{
+		{DSN: "http://localhost:8080?poll_interval=soon", Parameter: "poll_interval", Value: "soon"},
+		{DSN: "http://localhost:8080?dial_timeout=-1s", Parameter: "dial_timeout", Value: "-1s"},
+		{DSN: "http://localhost:8080?client_capabilities=FOO", Parameter: "client_capabilities", Value: "FOO"},
+		{DSN: "http://localhost:8080?slow_query_threshold=1", Parameter: "slow_query_threshold", Value: "1"},
+		{DSN: "http://localhost:8080?strict_dsn=yes", Parameter: "strict_dsn", Value: "yes"},
+	} {
+		t.Run(tc.Parameter, func(t *testing.T) {
+			// invalid parameters fail when opening the database
+			_, err := sql.Open("trino", tc.DSN)
+			var perr *ErrInvalidParameter
+			require.True(t, errors.As(err, &perr), "unexpected error: %v", err)
+			assert.Equal(t, tc.Parameter, perr.Parameter)
+			assert.Equal(t, tc.Value, perr.Value)
+
+			_, err = newConn(tc.DSN)
+			assert.True(t, errors.As(err, &perr), "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestStrictDSN(t *testing.T) {
+	_, err := sql.Open("trino", "http://localhost:8080?catalog=hive&shema=default")
+	assert.NoError(t, err)
+
+	_, err = sql.Open("trino", "http://localhost:8080?catalog=hive&shema=default&strict_dsn=true")
+	var uerr *ErrUnknownParameter
+	require.True(t, errors.As(err, &uerr), "unexpected error: %v", err)
+	assert.Equal(t, "shema", uerr.Parameter)
+	assert.Contains(t, uerr.Valid, "schema")
+	assert.Contains(t, err.Error(), `trino: unknown parameter "shema", valid parameters are: `)
+
+	c := &Config{
+		ServerURI:         "http://foobar@localhost:8080",
+		StrictDSN:         true,
+		SessionProperties: map[string]string{"query_priority": "1"},
+		PollInterval:      1,
+		TimeZone:          "UTC",
+	}
+	dsn, err := c.FormatDSN()
+	require.NoError(t, err)
+	assert.Contains(t, dsn, "strict_dsn=true")
+	_, err = NewConnector(c)
+	assert.NoError(t, err)
+}
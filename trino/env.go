@@ -0,0 +1,109 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// defaultServer is the server used by ConfigFromEnv when TRINO_SERVER and
+// TRINO_HOST are not set, like the Trino CLI.
+const defaultServer = "localhost:8080"
+
+// ConfigFromEnv returns a configuration from environment variables, named
+// like the options of the Trino CLI:
+//
+//	TRINO_SERVER              URI of the server, e.g. https://trino.example.com, or host:port
+//	TRINO_HOST                Alias of TRINO_SERVER, default is localhost:8080
+//	TRINO_USER                User
+//	TRINO_PASSWORD            Password, only sent over HTTPS
+//	TRINO_SOURCE              Source of the connections
+//	TRINO_CATALOG             Catalog
+//	TRINO_SCHEMA              Schema
+//	TRINO_SESSION_PROPERTIES  Session properties, as in the session_properties parameter
+//	TRINO_EXTRA_CREDENTIALS   Extra credentials, as comma separated name=value
+//	TRINO_TIME_ZONE           Session time zone
+//	TRINO_LANGUAGE            Session language
+//	TRINO_ROUTING_GROUP       Trino Gateway routing group
+//
+// Servers without a scheme use HTTP, or HTTPS on port 443. The returned
+// configuration can be changed before calling FormatDSN or NewConnector.
+func ConfigFromEnv() (*Config, error) {
+	return configFromEnv(os.Getenv)
+}
+
+func configFromEnv(getenv func(string) string) (*Config, error) {
+	server := getenv("TRINO_SERVER")
+	if server == "" {
+		server = getenv("TRINO_HOST")
+	}
+	if server == "" {
+		server = defaultServer
+	}
+	if !strings.Contains(server, "://") {
+		scheme := "http"
+		if strings.HasSuffix(server, ":443") {
+			scheme = "https"
+		}
+		server = scheme + "://" + server
+	}
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, fmt.Errorf("trino: invalid TRINO_SERVER: %v", redactError(err))
+	}
+	if user := getenv("TRINO_USER"); user != "" {
+		if password := getenv("TRINO_PASSWORD"); password != "" {
+			serverURL.User = url.UserPassword(user, password)
+		} else {
+			serverURL.User = url.User(user)
+		}
+	}
+
+	c := &Config{
+		ServerURI:    serverURL.String(),
+		Source:       getenv("TRINO_SOURCE"),
+		Catalog:      getenv("TRINO_CATALOG"),
+		Schema:       getenv("TRINO_SCHEMA"),
+		TimeZone:     getenv("TRINO_TIME_ZONE"),
+		Language:     getenv("TRINO_LANGUAGE"),
+		RoutingGroup: getenv("TRINO_ROUTING_GROUP"),
+	}
+	if v := getenv("TRINO_SESSION_PROPERTIES"); v != "" {
+		names, values, ok := splitSessionProperties(v)
+		if !ok {
+			return nil, fmt.Errorf("trino: invalid TRINO_SESSION_PROPERTIES: %q", v)
+		}
+		c.SessionProperties = make(map[string]string, len(names))
+		for i, name := range names {
+			c.SessionProperties[name] = values[i]
+		}
+		c.SessionPropertyOrder = names
+	}
+	if v := getenv("TRINO_EXTRA_CREDENTIALS"); v != "" {
+		c.ExtraCredentials = make(map[string]string)
+		for _, entry := range strings.Split(v, ",") {
+			name, value, ok := cutString(entry, "=")
+			if !ok || name == "" {
+				// do not include the value, which holds secrets
+				return nil, fmt.Errorf("trino: invalid TRINO_EXTRA_CREDENTIALS")
+			}
+			c.ExtraCredentials[name] = value
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,214 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ExplainFormat is the output format of Explain.
+type ExplainFormat string
+
+const (
+	// ExplainText returns the plan as text, in Plan.Text.
+	ExplainText ExplainFormat = "TEXT"
+	// ExplainJSON returns the logical plan parsed in Plan.Root.
+	ExplainJSON ExplainFormat = "JSON"
+	// ExplainGraphviz returns the plan in the DOT language, in Plan.Text.
+	ExplainGraphviz ExplainFormat = "GRAPHVIZ"
+	// ExplainIO returns the tables read and written, parsed in Plan.IO.
+	ExplainIO ExplainFormat = "IO"
+)
+
+// Plan is the result of Explain. Text always holds the raw output, and
+// Root or IO hold the parsed plan for the JSON and IO formats.
+type Plan struct {
+	Text string
+	Root *PlanNode
+	IO   *IOPlan
+}
+
+// PlanNode is a node of a logical plan.
+type PlanNode struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Descriptor map[string]string `json:"descriptor"`
+	Outputs    []PlanSymbol      `json:"outputs"`
+	Details    []string          `json:"details"`
+	Estimates  []PlanEstimate    `json:"estimates"`
+	Children   []*PlanNode       `json:"children"`
+}
+
+// PlanEstimate holds the estimates of the cost based optimizer for a plan
+// node. The costs include the ones of the children of the node. Estimates
+// that are unknown, e.g. for tables without statistics, are NaN.
+type PlanEstimate struct {
+	OutputRowCount    float64
+	OutputSizeInBytes float64
+	CPUCost           float64
+	MemoryCost        float64
+	NetworkCost       float64
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. Trino encodes
+// unknown estimates as the string "NaN".
+func (e *PlanEstimate) UnmarshalJSON(b []byte) error {
+	var v struct {
+		OutputRowCount    estimateValue `json:"outputRowCount"`
+		OutputSizeInBytes estimateValue `json:"outputSizeInBytes"`
+		CPUCost           estimateValue `json:"cpuCost"`
+		MemoryCost        estimateValue `json:"memoryCost"`
+		NetworkCost       estimateValue `json:"networkCost"`
+	}
+	v.OutputRowCount, v.OutputSizeInBytes, v.CPUCost, v.MemoryCost, v.NetworkCost =
+		estimateValue(math.NaN()), estimateValue(math.NaN()), estimateValue(math.NaN()), estimateValue(math.NaN()), estimateValue(math.NaN())
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*e = PlanEstimate{
+		OutputRowCount:    float64(v.OutputRowCount),
+		OutputSizeInBytes: float64(v.OutputSizeInBytes),
+		CPUCost:           float64(v.CPUCost),
+		MemoryCost:        float64(v.MemoryCost),
+		NetworkCost:       float64(v.NetworkCost),
+	}
+	return nil
+}
+
+// estimateValue is an estimate encoded as a number, or as a string for the
+// values JSON numbers cannot represent, such as NaN and Infinity.
+type estimateValue float64
+
+func (v *estimateValue) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		*v = estimateValue(math.NaN())
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid estimate %s", b)
+	}
+	*v = estimateValue(f)
+	return nil
+}
+
+// PlanSymbol is a symbol produced by a plan node.
+type PlanSymbol struct {
+	Symbol string `json:"symbol"`
+	Type   string `json:"type"`
+}
+
+// Walk calls fn for the node and its descendants, depth first, until fn
+// returns false.
+func (n *PlanNode) Walk(fn func(*PlanNode) bool) bool {
+	if !fn(n) {
+		return false
+	}
+	for _, child := range n.Children {
+		if !child.Walk(fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// IOPlan lists the tables read and written by a query.
+type IOPlan struct {
+	InputTables []IOTable
+	OutputTable *IOTable
+}
+
+// IOTable identifies a table in an IOPlan.
+type IOTable struct {
+	Catalog string
+	Schema  string
+	Table   string
+}
+
+type ioPlanResponse struct {
+	InputTableColumnInfos []struct {
+		Table ioTableResponse `json:"table"`
+	} `json:"inputTableColumnInfos"`
+	OutputTable *ioTableResponse `json:"outputTable"`
+}
+
+type ioTableResponse struct {
+	Catalog     string `json:"catalog"`
+	SchemaTable struct {
+		Schema string `json:"schema"`
+		Table  string `json:"table"`
+	} `json:"schemaTable"`
+}
+
+func (t ioTableResponse) ioTable() IOTable {
+	return IOTable{Catalog: t.Catalog, Schema: t.SchemaTable.Schema, Table: t.SchemaTable.Table}
+}
+
+// Explain returns the plan of a query in the given format, without running it.
+func Explain(ctx context.Context, q Queryer, query string, format ExplainFormat) (*Plan, error) {
+	var options string
+	switch format {
+	case ExplainText, ExplainJSON, ExplainGraphviz:
+		options = "FORMAT " + string(format)
+	case ExplainIO:
+		options = "TYPE IO, FORMAT JSON"
+	default:
+		return nil, fmt.Errorf("trino: unsupported explain format: %q", format)
+	}
+	rows, err := q.QueryContext(ctx, "EXPLAIN ("+options+") "+query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	plan := &Plan{}
+	for rows.Next() {
+		var text string
+		if err := rows.Scan(&text); err != nil {
+			return nil, err
+		}
+		plan.Text += text
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	switch format {
+	case ExplainJSON:
+		if err := json.Unmarshal([]byte(plan.Text), &plan.Root); err != nil {
+			return nil, fmt.Errorf("trino: cannot parse plan: %v", err)
+		}
+	case ExplainIO:
+		var r ioPlanResponse
+		if err := json.Unmarshal([]byte(plan.Text), &r); err != nil {
+			return nil, fmt.Errorf("trino: cannot parse plan: %v", err)
+		}
+		plan.IO = &IOPlan{}
+		for _, info := range r.InputTableColumnInfos {
+			plan.IO.InputTables = append(plan.IO.InputTables, info.Table.ioTable())
+		}
+		if r.OutputTable != nil {
+			t := r.OutputTable.ioTable()
+			plan.IO.OutputTable = &t
+		}
+	}
+	return plan, nil
+}
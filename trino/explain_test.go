@@ -0,0 +1,98 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func explainResult(t *testing.T, plan string) string {
+	b, err := json.Marshal(plan)
+	require.NoError(t, err)
+	return `"columns": [{"name": "Query Plan", "type": "varchar"}], "data": [[` + string(b) + `]]`
+}
+
+func TestExplainJSON(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, explainResult(t, `{
+		"id": "6",
+		"name": "Output",
+		"descriptor": {"columnNames": "[name]"},
+		"outputs": [{"symbol": "name", "type": "varchar(25)"}],
+		"details": [],
+		"children": [{
+			"id": "0",
+			"name": "TableScan",
+			"descriptor": {"table": "tpch:tiny:nation"},
+			"outputs": [{"symbol": "name", "type": "varchar(25)"}],
+			"details": ["name := tpch:name"],
+			"children": []
+		}]
+	}`), &queries)
+	db := openTestDB(t, ts)
+
+	plan, err := Explain(context.Background(), db, "SELECT name FROM nation", ExplainJSON)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"EXPLAIN (FORMAT JSON) SELECT name FROM nation"}, queries)
+	require.NotNil(t, plan.Root)
+	assert.Equal(t, "Output", plan.Root.Name)
+
+	var scans []string
+	plan.Root.Walk(func(n *PlanNode) bool {
+		if n.Name == "TableScan" {
+			scans = append(scans, n.Descriptor["table"])
+		}
+		return true
+	})
+	assert.Equal(t, []string{"tpch:tiny:nation"}, scans)
+}
+
+func TestExplainIO(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, explainResult(t, `{
+		"inputTableColumnInfos": [{
+			"table": {"catalog": "tpch", "schemaTable": {"schema": "tiny", "table": "nation"}},
+			"columnConstraints": []
+		}],
+		"outputTable": {"catalog": "hive", "schemaTable": {"schema": "default", "table": "copy"}}
+	}`), &queries)
+	db := openTestDB(t, ts)
+
+	plan, err := Explain(context.Background(), db, "INSERT INTO copy SELECT * FROM nation", ExplainIO)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"EXPLAIN (TYPE IO, FORMAT JSON) INSERT INTO copy SELECT * FROM nation"}, queries)
+	assert.Equal(t, &IOPlan{
+		InputTables: []IOTable{{Catalog: "tpch", Schema: "tiny", Table: "nation"}},
+		OutputTable: &IOTable{Catalog: "hive", Schema: "default", Table: "copy"},
+	}, plan.IO)
+}
+
+func TestExplainText(t *testing.T) {
+	ts := newResultTestServer(t, explainResult(t, "Fragment 0 [SINGLE]"), nil)
+	db := openTestDB(t, ts)
+
+	plan, err := Explain(context.Background(), db, "SELECT 1", ExplainText)
+	require.NoError(t, err)
+	assert.Equal(t, "Fragment 0 [SINGLE]", plan.Text)
+	assert.Nil(t, plan.Root)
+
+	_, err = Explain(context.Background(), db, "SELECT 1", ExplainFormat("XML"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,69 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import "context"
+
+// FetchStats holds the counters of the pages of results transferred for a
+// query, to verify how much is saved by closing rows early, e.g. once
+// enough rows were read.
+//
+// Closing rows before the last page cancels the query in Trino, and stops
+// the request for the page in flight, if any, rather than downloading it.
+type FetchStats struct {
+	Pages          int   // Number of pages read, including the response to the submission
+	Bytes          int64 // Size of the pages read
+	Rows           int64 // Number of rows in the pages read
+	RowsReturned   int64 // Number of rows returned to the application
+	DiscardedPages int   // Number of prefetched pages discarded when closing the rows
+	DiscardedBytes int64 // Size of the prefetched pages discarded when closing the rows
+	Cancelled      bool  // Whether the rows were closed before the last page
+}
+
+// FetchStatsHandler receives the fetch statistics of a query, once its
+// rows are closed.
+type FetchStatsHandler func(stats FetchStats)
+
+type fetchStatsHandlerKey struct{}
+
+// WithFetchStats returns a context that makes queries executed with it pass
+// their fetch statistics to fn.
+func WithFetchStats(ctx context.Context, fn FetchStatsHandler) context.Context {
+	return context.WithValue(ctx, fetchStatsHandlerKey{}, fn)
+}
+
+func fetchStatsHandlerFromContext(ctx context.Context) FetchStatsHandler {
+	fn, _ := ctx.Value(fetchStatsHandlerKey{}).(FetchStatsHandler)
+	return fn
+}
+
+// countPage adds a page read to the fetch statistics.
+func (qr *driverRows) countPage(rows int, bytes int64) {
+	qr.fetchStats.Pages++
+	qr.fetchStats.Bytes += bytes
+	qr.fetchStats.Rows += int64(rows)
+}
+
+// reportFetchStats passes the fetch statistics to the handler of the
+// context, if any, once.
+func (qr *driverRows) reportFetchStats() {
+	if qr.reported {
+		return
+	}
+	qr.reported = true
+	if fn := fetchStatsHandlerFromContext(qr.ctx); fn != nil {
+		fn(qr.fetchStats)
+	}
+}
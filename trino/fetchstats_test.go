@@ -0,0 +1,84 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchStats(t *testing.T) {
+	ts := newPagedResultTestServer(t, integerPages(2), nil)
+	db := openTestDB(t, ts)
+
+	var stats FetchStats
+	ctx := WithFetchStats(context.Background(), func(s FetchStats) {
+		stats = s
+	})
+	rows, err := db.QueryContext(ctx, "SELECT n FROM t")
+	require.NoError(t, err)
+	var n int
+	for rows.Next() {
+		require.NoError(t, rows.Scan(&n))
+	}
+	require.NoError(t, rows.Err())
+	require.NoError(t, rows.Close())
+	assert.Equal(t, 3, stats.Pages)
+	assert.Equal(t, int64(4), stats.Rows)
+	assert.Equal(t, int64(4), stats.RowsReturned)
+	assert.True(t, stats.Bytes > 0)
+	assert.False(t, stats.Cancelled)
+}
+
+func TestFetchStatsEarlyClose(t *testing.T) {
+	for _, dsn := range []string{"", "?max_buffered_rows=1000"} {
+		t.Run(dsn, func(t *testing.T) {
+			ts := newPagedResultTestServer(t, integerPages(3), nil, blockPage(3, nil))
+			db, err := sql.Open("trino", ts.URL+dsn)
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				assert.NoError(t, db.Close())
+			})
+
+			var stats FetchStats
+			ctx := WithFetchStats(context.Background(), func(s FetchStats) {
+				stats = s
+			})
+			rows, err := db.QueryContext(ctx, "SELECT n FROM t")
+			require.NoError(t, err)
+			require.True(t, rows.Next())
+
+			// the last page is never returned, so closing must not wait for it
+			start := time.Now()
+			require.NoError(t, rows.Close())
+			assert.True(t, time.Since(start) < 5*time.Second)
+			assert.True(t, stats.Cancelled)
+			assert.Equal(t, int64(1), stats.RowsReturned)
+			assert.Equal(t, 2, stats.Pages)
+			assert.Equal(t, int64(2), stats.Rows)
+			if strings.Contains(dsn, "max_buffered_rows") {
+				assert.True(t, stats.DiscardedPages <= 1)
+			} else {
+				assert.Equal(t, 0, stats.DiscardedPages)
+			}
+		})
+	}
+}
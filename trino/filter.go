@@ -0,0 +1,25 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+// StatementFilter is called with each statement before it is sent to Trino,
+// to enforce policies centrally, e.g. banning cross joins or queries of
+// some catalogs. A non-nil error rejects the statement, and is returned
+// as is by the driver.
+//
+// The statement is the one passed to database/sql, as rewritten by the
+// QueryRewriter and with the limit added by auto_limit, if any, and before
+// its arguments are bound.
+type StatementFilter func(query string) error
@@ -0,0 +1,56 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementFilter(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`, &queries)
+	errCrossJoin := errors.New("cross joins are not allowed")
+	var filtered []string
+	connector, err := NewConnector(&Config{
+		ServerURI: ts.URL,
+		StatementFilter: func(query string) error {
+			filtered = append(filtered, query)
+			if strings.Contains(strings.ToUpper(query), "CROSS JOIN") {
+				return errCrossJoin
+			}
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	var n int
+	err = db.QueryRow("SELECT 1 FROM a CROSS JOIN b").Scan(&n)
+	assert.True(t, errors.Is(err, errCrossJoin), "unexpected error: %v", err)
+	assert.Empty(t, queries)
+
+	require.NoError(t, db.QueryRow("SELECT ?", 1).Scan(&n))
+	assert.Equal(t, []string{"EXECUTE _trino_go USING 1"}, queries)
+	assert.Equal(t, []string{"SELECT 1 FROM a CROSS JOIN b", "SELECT ?"}, filtered)
+}
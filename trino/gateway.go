@@ -0,0 +1,74 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"net/http"
+)
+
+type routingGroupKey struct{}
+
+// WithRoutingGroup returns a context that makes queries executed with it
+// routed to the given Trino Gateway routing group, overriding the
+// routing_group of the connection.
+func WithRoutingGroup(ctx context.Context, group string) context.Context {
+	return context.WithValue(ctx, routingGroupKey{}, group)
+}
+
+func routingGroupFromContext(ctx context.Context) string {
+	group, _ := ctx.Value(routingGroupKey{}).(string)
+	return group
+}
+
+// addCookies adds the cookies set by the responses of a query, such as the
+// routing cookies of Trino Gateway, to a request of the same query.
+// They are sent regardless of the host of the request, as the nextUri may
+// point to a different host than the one that set them.
+func addCookies(req *http.Request, cookies []*http.Cookie) {
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+}
+
+// responseCookies returns the cookies set by the headers of a response.
+func responseCookies(h http.Header) []*http.Cookie {
+	return (&http.Response{Header: h}).Cookies()
+}
+
+// mergeCookies updates cookies with the ones set by a response, removing
+// the expired ones.
+func mergeCookies(cookies, set []*http.Cookie) []*http.Cookie {
+	for _, cookie := range set {
+		found := false
+		for i, c := range cookies {
+			if c.Name == cookie.Name {
+				cookies[i] = cookie
+				found = true
+				break
+			}
+		}
+		if !found {
+			cookies = append(cookies, cookie)
+		}
+	}
+	merged := cookies[:0]
+	for _, c := range cookies {
+		if c.MaxAge >= 0 {
+			merged = append(merged, c)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,69 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGatewayRouting(t *testing.T) {
+	var routingGroups, cookies []string
+	result := `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`
+	coordinator := newPagedResultTestServer(t, []string{result}, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if c, err := r.Cookie("trinoClientHost"); err == nil {
+			cookies = append(cookies, c.Value)
+		}
+		return false
+	})
+	gateway := newPagedResultTestServer(t, nil, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path != "/v1/statement" {
+			return false
+		}
+		routingGroups = append(routingGroups, r.Header.Get(trinoRoutingGroupHeader))
+		http.SetCookie(w, &http.Cookie{Name: "trinoClientHost", Value: "coordinator-1"})
+		json.NewEncoder(w).Encode(&stmtResponse{
+			ID:      testQueryID,
+			NextURI: testPageURI(coordinator.URL, 1),
+		})
+		return true
+	})
+
+	dsn, err := (&Config{ServerURI: gateway.URL, RoutingGroup: "etl"}).FormatDSN()
+	require.NoError(t, err)
+	db, err := sql.Open("trino", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	var v int
+	require.NoError(t, db.QueryRow("SELECT 1").Scan(&v))
+	require.NoError(t, db.QueryRowContext(WithRoutingGroup(context.Background(), "adhoc"), "SELECT 1").Scan(&v))
+	assert.Equal(t, []string{"etl", "adhoc"}, routingGroups)
+	assert.Equal(t, []string{"coordinator-1", "coordinator-1"}, cookies)
+}
+
+func TestMergeCookies(t *testing.T) {
+	cookies := []*http.Cookie{{Name: "a", Value: "1"}, {Name: "b", Value: "1"}}
+	cookies = mergeCookies(cookies, []*http.Cookie{{Name: "a", Value: "2"}, {Name: "b", MaxAge: -1}, {Name: "c", Value: "1"}})
+	assert.Equal(t, []*http.Cookie{{Name: "a", Value: "2"}, {Name: "c", Value: "1"}}, cookies)
+}
@@ -0,0 +1,59 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// gzipStatement returns whether the query is submitted compressed with
+// gzip, when gzip_statement_bytes is set, the query is at least that
+// large, and the server did not reject compressed statements before.
+func (c *Conn) gzipStatement(query string) bool {
+	return c.gzipStatementBytes > 0 && len(query) >= c.gzipStatementBytes && !c.gzipRejected
+}
+
+// newStatementRequest returns the request submitting the query, with its
+// body compressed with gzip if compress is set.
+func (c *Conn) newStatementRequest(query string, hs http.Header, compress bool) (*http.Request, error) {
+	if !compress {
+		return c.newRequest("POST", c.baseURL+"/v1/statement", strings.NewReader(query), hs)
+	}
+	var b bytes.Buffer
+	zw := gzip.NewWriter(&b)
+	zw.Write([]byte(query))
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	req, err := c.newRequest("POST", c.baseURL+"/v1/statement", bytes.NewReader(b.Bytes()), hs)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Encoding", "gzip")
+	return req, nil
+}
+
+// isGzipRejected returns whether the submission of a compressed statement
+// was rejected because the server, or a proxy in front of it, does not
+// accept compressed requests. The statement was not run, so it can be
+// submitted again uncompressed.
+func isGzipRejected(err error) bool {
+	var qerr *ErrQueryFailed
+	return errors.As(err, &qerr) && (qerr.StatusCode == http.StatusUnsupportedMediaType || qerr.StatusCode == http.StatusBadRequest)
+}
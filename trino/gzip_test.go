@@ -0,0 +1,97 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"compress/gzip"
+	"database/sql"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGzipTestServer returns a server recording the encodings and queries
+// of the statements it receives, and decompressing them if accept is set,
+// or rejecting them otherwise.
+func newGzipTestServer(t *testing.T, accept bool) (*httptest.Server, *[]string) {
+	var statements []string
+	result := `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`
+	ts := newPagedResultTestServer(t, []string{result}, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method != "POST" {
+			return false
+		}
+		encoding := r.Header.Get("Content-Encoding")
+		var body io.Reader = r.Body
+		if encoding == "gzip" {
+			if !accept {
+				statements = append(statements, "rejected")
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+				return true
+			}
+			zr, err := gzip.NewReader(r.Body)
+			require.NoError(t, err)
+			body = zr
+		}
+		b, err := ioutil.ReadAll(body)
+		require.NoError(t, err)
+		statements = append(statements, encoding+":"+string(b))
+		return false
+	})
+	return ts, &statements
+}
+
+func TestGzipStatements(t *testing.T) {
+	ts, statements := newGzipTestServer(t, true)
+	db, err := sql.Open("trino", ts.URL+"?gzip_statement_bytes=64")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	long := "SELECT 1 FROM t WHERE c IN ('" + strings.Repeat("x", 64) + "')"
+	require.NoError(t, db.QueryRow("SELECT 1").Scan(new(int)))
+	require.NoError(t, db.QueryRow(long).Scan(new(int)))
+	assert.Equal(t, []string{":SELECT 1", "gzip:" + long}, *statements)
+}
+
+func TestGzipStatementsRejected(t *testing.T) {
+	ts, statements := newGzipTestServer(t, false)
+	db, err := sql.Open("trino", ts.URL+"?gzip_statement_bytes=64")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+	db.SetMaxOpenConns(1)
+
+	long := "SELECT 1 FROM t WHERE c IN ('" + strings.Repeat("x", 64) + "')"
+	require.NoError(t, db.QueryRow(long).Scan(new(int)))
+	require.NoError(t, db.QueryRow(long).Scan(new(int)))
+	assert.Equal(t, []string{"rejected", ":" + long, ":" + long}, *statements)
+}
+
+func TestGzipStatementsDSN(t *testing.T) {
+	dsn, err := (&Config{ServerURI: "http://foobar@localhost:8080", GzipStatementBytes: 4096}).FormatDSN()
+	require.NoError(t, err)
+	assert.Equal(t, "http://foobar@localhost:8080?gzip_statement_bytes=4096&source=trino-go-client", dsn)
+
+	_, err = newConn("http://foobar@localhost:8080?gzip_statement_bytes=big")
+	assert.EqualError(t, err, `trino: invalid gzip_statement_bytes: "big"`)
+}
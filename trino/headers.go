@@ -0,0 +1,47 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"net/http"
+)
+
+// ExtraHeadersFunc returns headers to add to a request sent to Trino, such
+// as the tenant ID or the authentication context required by a proxy.
+//
+// It is called for every request, including the submission of queries,
+// the polling of results and their cancellation. The context is the one of
+// the query, except for cancellations, which use a background context.
+type ExtraHeadersFunc func(ctx context.Context) (http.Header, error)
+
+// addExtraHeaders sets the static extra headers, then the dynamic ones,
+// replacing the headers of the driver with the same names.
+func (c *Conn) addExtraHeaders(ctx context.Context, req *http.Request) error {
+	for k, v := range c.extraHeaders {
+		req.Header[http.CanonicalHeaderKey(k)] = v
+	}
+	if c.extraHeadersFunc == nil {
+		return nil
+	}
+	hs, err := c.extraHeadersFunc(ctx)
+	if err != nil {
+		return err
+	}
+	for k, v := range hs {
+		req.Header[http.CanonicalHeaderKey(k)] = v
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tenantKey struct{}
+
+func TestExtraHeaders(t *testing.T) {
+	var requests []string
+	result := `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`
+	ts := newPagedResultTestServer(t, []string{result}, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		requests = append(requests, r.Method+" "+r.Header.Get("X-Proxy-Auth")+" "+r.Header.Get("X-Tenant-Id"))
+		return false
+	})
+
+	connector, err := NewConnector(&Config{
+		ServerURI:    ts.URL,
+		ExtraHeaders: http.Header{"X-Proxy-Auth": []string{"token"}},
+		ExtraHeadersFunc: func(ctx context.Context) (http.Header, error) {
+			hs := make(http.Header)
+			if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+				hs.Set("X-Tenant-Id", tenant)
+			}
+			return hs, nil
+		},
+	})
+	require.NoError(t, err)
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	var v int
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT 1").Scan(&v))
+	assert.Equal(t, []string{"POST token acme", "GET token acme", "DELETE token "}, requests)
+}
+
+func TestExtraHeadersFuncError(t *testing.T) {
+	connector, err := NewConnector(&Config{
+		ServerURI: "http://localhost:8080",
+		ExtraHeadersFunc: func(ctx context.Context) (http.Header, error) {
+			return nil, errors.New("no tenant")
+		},
+	})
+	require.NoError(t, err)
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	_, err = db.Exec("SELECT 1")
+	assert.EqualError(t, err, "no tenant")
+}
@@ -0,0 +1,42 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import "net/http"
+
+// HostClients are the HTTP clients of the requests to other hosts than the
+// one of the server, by host or host:port, e.g. when the nextUri of the
+// responses points to a gateway or a proxy requiring its own transport,
+// TLS configuration or credentials.
+type HostClients map[string]*http.Client
+
+// clientFor returns the HTTP client of the request: the one of HostClients
+// for its host, if any, or the client of the connection. Requests sent
+// with a client of HostClients do not include the credentials of the
+// connection, as they are meant for the coordinator; the client is
+// responsible for authenticating to its host.
+func (c *Conn) clientFor(req *http.Request) http.Client {
+	if len(c.hostClients) > 0 {
+		client, ok := c.hostClients[req.URL.Host]
+		if !ok {
+			client, ok = c.hostClients[req.URL.Hostname()]
+		}
+		if ok {
+			req.Header.Del("Authorization")
+			return *client
+		}
+	}
+	return c.httpClient
+}
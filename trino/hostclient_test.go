@@ -0,0 +1,77 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type headerTransport struct {
+	name, value string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set(t.name, t.value)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestHostClients(t *testing.T) {
+	var pageHeader, statementHeader http.Header
+	result := `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`
+	pages := newPagedResultTestServer(t, []string{result}, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		pageHeader = r.Header
+		return false
+	})
+	coordinator := newPagedResultTestServer(t, nil, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path != "/v1/statement" {
+			return false
+		}
+		statementHeader = r.Header
+		json.NewEncoder(w).Encode(&stmtResponse{
+			ID:      testQueryID,
+			NextURI: testPageURI(pages.URL, 1),
+		})
+		return true
+	})
+
+	pagesURL, err := url.Parse(pages.URL)
+	require.NoError(t, err)
+	connector, err := NewConnector(&Config{
+		ServerURI:    coordinator.URL,
+		ExtraHeaders: http.Header{"Authorization": {"Bearer coordinator"}},
+		HostClients: HostClients{
+			pagesURL.Host: {Transport: &headerTransport{name: "X-Amz-Security-Token", value: "pages"}},
+		},
+	})
+	require.NoError(t, err)
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	require.NoError(t, db.QueryRow("SELECT 1").Scan(new(int)))
+	assert.Equal(t, "Bearer coordinator", statementHeader.Get("Authorization"))
+	assert.Empty(t, statementHeader.Get("X-Amz-Security-Token"))
+	assert.Empty(t, pageHeader.Get("Authorization"))
+	assert.Equal(t, "pages", pageHeader.Get("X-Amz-Security-Token"))
+}
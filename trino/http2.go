@@ -0,0 +1,147 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+)
+
+// sharedTransports are the transports built from DSN parameters, such as
+// SSLCertPath and the transport timeouts, by key. They are shared by the
+// connections opened with the same parameters, so that concurrent queries
+// reuse the same connections, and are multiplexed over a few HTTP/2
+// connections when the server supports it, rather than each connection of
+// the database pool opening its own.
+//
+// They are reference counted by the connections using them, and dropped,
+// with their idle connections closed, once the last one is closed.
+var sharedTransports = struct {
+	sync.Mutex
+	m map[string]*sharedTransportRef
+}{m: make(map[string]*sharedTransportRef)}
+
+type sharedTransportRef struct {
+	transport *http.Transport
+	refs      int
+}
+
+// sharedTransport returns the transport for the key, built by build when
+// there is none yet, to be released with releaseTransport once unused.
+func sharedTransport(key string, build func() *http.Transport) *http.Transport {
+	sharedTransports.Lock()
+	defer sharedTransports.Unlock()
+	ref, ok := sharedTransports.m[key]
+	if !ok {
+		ref = &sharedTransportRef{transport: build()}
+		sharedTransports.m[key] = ref
+	}
+	ref.refs++
+	return ref.transport
+}
+
+// releaseTransport releases a transport returned by sharedTransport.
+func releaseTransport(t *http.Transport) {
+	sharedTransports.Lock()
+	defer sharedTransports.Unlock()
+	for key, ref := range sharedTransports.m {
+		if ref.transport != t {
+			continue
+		}
+		ref.refs--
+		if ref.refs == 0 {
+			delete(sharedTransports.m, key)
+			t.CloseIdleConnections()
+		}
+		return
+	}
+}
+
+// TransportStats holds the counters of the requests sent by the driver, to
+// verify how well connections are reused, and how many concurrent requests
+// are multiplexed over HTTP/2 connections.
+type TransportStats struct {
+	Requests          int64 // Number of requests sent, including retries
+	HTTP2Requests     int64 // Number of requests answered over HTTP/2, each one being a stream of a shared connection
+	Connections       int64 // Number of connections opened, as opposed to reused
+	ActiveRequests    int64 // Number of requests in flight, until their response is read
+	MaxActiveRequests int64 // Highest number of requests in flight at once
+}
+
+var transportStats struct {
+	sync.Mutex
+	TransportStats
+}
+
+// GetTransportStats returns the counters of the requests sent by the
+// driver, for all databases, since the program started.
+func GetTransportStats() TransportStats {
+	transportStats.Lock()
+	defer transportStats.Unlock()
+	return transportStats.TransportStats
+}
+
+// doCounted sends the request with the client, counting it in the
+// transport statistics until its response body is closed.
+func doCounted(client *http.Client, req *http.Request) (*http.Response, error) {
+	transportStats.Lock()
+	transportStats.Requests++
+	transportStats.ActiveRequests++
+	if transportStats.ActiveRequests > transportStats.MaxActiveRequests {
+		transportStats.MaxActiveRequests = transportStats.ActiveRequests
+	}
+	transportStats.Unlock()
+
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if !info.Reused {
+				transportStats.Lock()
+				transportStats.Connections++
+				transportStats.Unlock()
+			}
+		},
+	}
+	resp, err := client.Do(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+	if err != nil {
+		endRequest()
+		return nil, err
+	}
+	if resp.ProtoMajor == 2 {
+		transportStats.Lock()
+		transportStats.HTTP2Requests++
+		transportStats.Unlock()
+	}
+	resp.Body = &countedBody{ReadCloser: resp.Body}
+	return resp, nil
+}
+
+func endRequest() {
+	transportStats.Lock()
+	transportStats.ActiveRequests--
+	transportStats.Unlock()
+}
+
+// countedBody ends the request in the transport statistics once closed.
+type countedBody struct {
+	io.ReadCloser
+	once sync.Once
+}
+
+func (b *countedBody) Close() error {
+	b.once.Do(endRequest)
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,162 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTP2Multiplexing(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 {
+			http.Error(w, "HTTP/2 required", http.StatusHTTPVersionNotSupported)
+			return
+		}
+		serveResult(w, r, ts.URL, `
+	"columns": [{"name": "n", "type": "bigint"}],
+	"data": [[1]]`, nil)
+	}))
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	cert, err := ioutil.TempFile("", "trino-cert-*.pem")
+	require.NoError(t, err)
+	defer os.Remove(cert.Name())
+	require.NoError(t, pem.Encode(cert, &pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}))
+	require.NoError(t, cert.Close())
+
+	db, err := sql.Open("trino", ts.URL+"?SSLCertPath="+cert.Name())
+	require.NoError(t, err)
+	defer db.Close()
+
+	before := GetTransportStats()
+	const queries = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, queries)
+	for i := 0; i < queries; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var n int64
+			errs <- db.QueryRow("SELECT 1").Scan(&n)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	after := GetTransportStats()
+
+	assert.True(t, after.HTTP2Requests-before.HTTP2Requests >= queries, "requests are sent over HTTP/2")
+	assert.True(t, after.Connections-before.Connections < queries, "connections are shared: %d opened", after.Connections-before.Connections)
+	assert.True(t, after.MaxActiveRequests > 0)
+}
+
+func TestSharedTransports(t *testing.T) {
+	cert, err := ioutil.TempFile("", "trino-cert-*.pem")
+	require.NoError(t, err)
+	defer os.Remove(cert.Name())
+	require.NoError(t, cert.Close())
+
+	dsn := "https://localhost:8443?SSLCertPath=" + cert.Name()
+	c1, err := newConn(dsn)
+	require.NoError(t, err)
+	c2, err := newConn(dsn)
+	require.NoError(t, err)
+	assert.Same(t, c1.httpClient.Transport, c2.httpClient.Transport)
+	assert.True(t, c1.httpClient.Transport.(*http.Transport).ForceAttemptHTTP2)
+
+	c3, err := newConn(dsn + "&dial_timeout=1s")
+	require.NoError(t, err)
+	c4, err := newConn(dsn + "&dial_timeout=1s")
+	require.NoError(t, err)
+	c5, err := newConn(dsn + "&dial_timeout=2s")
+	require.NoError(t, err)
+	assert.Same(t, c3.httpClient.Transport, c4.httpClient.Transport)
+	assert.NotSame(t, c3.httpClient.Transport, c5.httpClient.Transport)
+	assert.NotSame(t, c1.httpClient.Transport, c3.httpClient.Transport)
+}
+
+func TestSharedTransportsReleased(t *testing.T) {
+	ts := newResultTestServer(t, `"columns": [{"name": "n", "type": "bigint"}], "data": [[1]]`, nil)
+	dsn := ts.URL + "?dial_timeout=3s"
+	c1, err := newConn(dsn)
+	require.NoError(t, err)
+	c2, err := newConn(dsn)
+	require.NoError(t, err)
+	transport := c1.httpClient.Transport
+	assert.Same(t, transport, c2.httpClient.Transport)
+
+	// reused tells whether a request to ts reuses an idle connection
+	reused := func() bool {
+		var reused bool
+		trace := &httptrace.ClientTrace{
+			GotConn: func(info httptrace.GotConnInfo) {
+				reused = info.Reused
+			},
+		}
+		req, err := http.NewRequest("GET", ts.URL+"/v1/info", nil)
+		require.NoError(t, err)
+		resp, err := transport.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+		require.NoError(t, err)
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		return reused
+	}
+	reused()
+	require.NoError(t, c1.Close())
+	assert.True(t, reused(), "the transport is still used by c2")
+	c3, err := newConn(dsn)
+	require.NoError(t, err)
+	assert.Same(t, transport, c3.httpClient.Transport)
+
+	require.NoError(t, c2.Close())
+	require.NoError(t, c3.Close())
+	assert.False(t, reused(), "idle connections are closed with the last connection")
+	c4, err := newConn(dsn)
+	require.NoError(t, err)
+	defer c4.Close()
+	assert.NotSame(t, transport, c4.httpClient.Transport)
+}
+
+func TestTransportStatsActiveRequests(t *testing.T) {
+	ts := newResultTestServer(t, `
+	"columns": [{"name": "n", "type": "bigint"}],
+	"data": [[1]]`, nil)
+	db, err := sql.Open("trino", ts.URL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	before := GetTransportStats()
+	var n int64
+	require.NoError(t, db.QueryRow("SELECT 1").Scan(&n))
+	after := GetTransportStats()
+	assert.True(t, after.Requests > before.Requests)
+	assert.Equal(t, before.ActiveRequests, after.ActiveRequests, "the responses of completed queries are closed")
+}
@@ -0,0 +1,97 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrAlreadyExecuted is returned when executing a statement with an
+// idempotency key used by a previous statement that did not fail.
+type ErrAlreadyExecuted struct {
+	Key     string
+	QueryID string // ID of the previous statement
+	State   string // State of the previous statement, e.g. RUNNING or FINISHED
+}
+
+// Error implements the error interface.
+func (e *ErrAlreadyExecuted) Error() string {
+	return fmt.Sprintf("trino: statement with idempotency key %q already executed by query %s (%s)", e.Key, e.QueryID, e.State)
+}
+
+type idempotencyKeyKey struct{}
+
+// WithIdempotencyKey returns a context that makes statements executed with
+// it run only if no statement with the same key is running or finished, in
+// which case they fail with an *ErrAlreadyExecuted. This protects
+// statements that are not idempotent, such as INSERT, from running twice
+// when the application retries them after a failure that does not tell if
+// they ran, e.g. a timeout, using a key generated once per statement.
+//
+// The key is added to the statement as a comment, and looked up in
+// system.runtime.queries before executing it, which only lists the recent
+// queries of the cluster, visible to the user.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyKey{}, key)
+}
+
+func idempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyKey{}).(string)
+	return key
+}
+
+// idempotencyMarker returns the comment prefixing statements executed with
+// the key.
+func idempotencyMarker(key string) string {
+	return "/* trino-go-client idempotency_key=" + key + " */ "
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// checkIdempotencyKey returns an *ErrAlreadyExecuted if a statement with
+// the key is running or finished.
+func (c *Conn) checkIdempotencyKey(ctx context.Context, key, user string) error {
+	if strings.Contains(key, "*/") {
+		return fmt.Errorf("trino: invalid idempotency key %q", key)
+	}
+	pattern := likeEscaper.Replace(idempotencyMarker(key)) + "%"
+	st := c.newInternalStmt("SELECT query_id, state FROM system.runtime.queries" +
+		" WHERE query LIKE " + QuoteLiteral(pattern) + ` ESCAPE '\' AND state <> 'FAILED'`)
+	var args []driver.NamedValue
+	if user != "" {
+		args = append(args, driver.NamedValue{Name: trinoUserHeader, Value: user})
+	}
+	rows, err := st.QueryContext(WithIdempotencyKey(ctx, ""), args)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	dest := make([]driver.Value, 2)
+	err = rows.Next(dest)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	queryID, _ := dest[0].(string)
+	state, _ := dest[1].(string)
+	return &ErrAlreadyExecuted{Key: key, QueryID: queryID, State: state}
+}
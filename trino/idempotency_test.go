@@ -0,0 +1,114 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newIdempotencyTestServer returns a server recording the submitted
+// queries, and the statements executed as listed by system.runtime.queries.
+func newIdempotencyTestServer(t *testing.T, queries, executed *[]string) *httptest.Server {
+	return newPagedResultTestServer(t, nil, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path != "/v1/statement" {
+			return false
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		*queries = append(*queries, string(b))
+		if !strings.HasPrefix(string(b), "SELECT query_id, state FROM system.runtime.queries") {
+			*executed = append(*executed, string(b))
+			w.Write([]byte(`{"id": "` + testQueryID + `", "updateType": "INSERT", "updateCount": 1}`))
+			return true
+		}
+		data := `[]`
+		for _, q := range *executed {
+			if strings.Contains(string(b), strings.Replace(q[:strings.Index(q, "*/")], "_", `\_`, -1)) {
+				data = `[["` + testQueryID + `", "FINISHED"]]`
+			}
+		}
+		w.Write([]byte(`{"id": "check", "columns": [{"name": "query_id", "type": "varchar"}, {"name": "state", "type": "varchar"}], "data": ` + data + `}`))
+		return true
+	})
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	var queries []string
+	var executed []string // statements seen by system.runtime.queries
+	ts := newIdempotencyTestServer(t, &queries, &executed)
+	db := openTestDB(t, ts)
+
+	ctx := WithIdempotencyKey(context.Background(), "a1b2")
+	_, err := db.ExecContext(ctx, "INSERT INTO t VALUES (1)")
+	require.NoError(t, err)
+	require.Len(t, queries, 2)
+	assert.Equal(t, `SELECT query_id, state FROM system.runtime.queries WHERE query LIKE '/* trino-go-client idempotency\_key=a1b2 */ %' ESCAPE '\' AND state <> 'FAILED'`, queries[0])
+	assert.Equal(t, "/* trino-go-client idempotency_key=a1b2 */ INSERT INTO t VALUES (1)", queries[1])
+
+	// retrying with the same key does not run the statement again
+	_, err = db.ExecContext(ctx, "INSERT INTO t VALUES (1)")
+	var aerr *ErrAlreadyExecuted
+	require.True(t, errors.As(err, &aerr), "unexpected error: %v", err)
+	assert.Equal(t, "a1b2", aerr.Key)
+	assert.Equal(t, "FINISHED", aerr.State)
+	assert.Len(t, executed, 1)
+
+	_, err = db.ExecContext(WithIdempotencyKey(context.Background(), "c3d4"), "INSERT INTO t VALUES (1)")
+	require.NoError(t, err)
+	assert.Len(t, executed, 2)
+
+	_, err = db.ExecContext(WithIdempotencyKey(context.Background(), "*/"), "INSERT INTO t VALUES (1)")
+	assert.EqualError(t, err, `trino: invalid idempotency key "*/"`)
+}
+
+func TestIdempotencyKeyInternalLookup(t *testing.T) {
+	var queries, executed []string
+	ts := newIdempotencyTestServer(t, &queries, &executed)
+	connector, err := NewConnector(&Config{
+		ServerURI:   ts.URL,
+		ResultCache: NewMemoryResultCache(10, time.Minute),
+		StatementFilter: func(query string) error {
+			if strings.Contains(query, "system.") {
+				return errors.New("system tables are not allowed")
+			}
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	// the lookup is neither rejected by the filter nor served from the cache
+	ctx := WithIdempotencyKey(context.Background(), "a1b2")
+	_, err = db.ExecContext(ctx, "INSERT INTO t VALUES (1)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "INSERT INTO t VALUES (1)")
+	var aerr *ErrAlreadyExecuted
+	require.True(t, errors.As(err, &aerr), "unexpected error: %v", err)
+	assert.Len(t, executed, 1)
+	assert.Len(t, queries, 3)
+}
@@ -0,0 +1,43 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// InList returns a predicate matching expr against the values of a slice,
+// and the arguments to pass for its placeholders, e.g.
+//
+//	pred, args, err := trino.InList("id", ids)
+//	rows, err := db.Query("SELECT * FROM t WHERE "+pred, args...)
+//
+// Rather than an IN list with a literal or a placeholder per value, which
+// Trino plans and analyzes one expression at a time, the values are passed
+// as a single array parameter, joined with UNNEST. This keeps the query
+// text and its planning time small for lists of thousands of values.
+//
+// An empty slice returns a predicate matching no rows, and no arguments.
+func InList(expr string, values interface{}) (string, []interface{}, error) {
+	v := reflect.ValueOf(values)
+	if _, ok := values.([]byte); ok || v.Kind() != reflect.Slice {
+		return "", nil, fmt.Errorf("trino: InList values must be a slice, got %T", values)
+	}
+	if v.Len() == 0 {
+		return "FALSE", nil, nil
+	}
+	return fmt.Sprintf("%s IN (SELECT v FROM UNNEST(?) AS t(v))", expr), []interface{}{values}, nil
+}
@@ -0,0 +1,52 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInList(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"columns": [{"name": "_col0", "type": "bigint"}], "data": [[1]]`, &queries)
+	db := openTestDB(t, ts)
+
+	ids := make([]int64, 1000)
+	for i := range ids {
+		ids[i] = int64(i)
+	}
+	pred, args, err := InList("id", ids)
+	require.NoError(t, err)
+	assert.Equal(t, "id IN (SELECT v FROM UNNEST(?) AS t(v))", pred)
+
+	var n int64
+	require.NoError(t, db.QueryRow("SELECT count(*) FROM t WHERE "+pred, args...).Scan(&n))
+	require.NotEmpty(t, queries)
+	assert.Contains(t, queries[0], "USING ARRAY[0, 1, 2, ")
+	assert.Contains(t, queries[0], ", 999]")
+
+	pred, args, err = InList("id", []string{})
+	require.NoError(t, err)
+	assert.Equal(t, "FALSE", pred)
+	assert.Empty(t, args)
+
+	_, _, err = InList("id", 42)
+	assert.EqualError(t, err, "trino: InList values must be a slice, got int")
+	_, _, err = InList("id", []byte("ab"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// Values of Config.IPFamily, selecting the IP versions used to connect to
+// Trino.
+const (
+	// IPv4Only only connects over IPv4, ignoring AAAA records.
+	IPv4Only = "ipv4"
+	// IPv6Only only connects over IPv6, ignoring A records.
+	IPv6Only = "ipv6"
+	// PreferIPv4 connects over IPv4, and also tries IPv6 if no connection
+	// is established within the fallback delay.
+	PreferIPv4 = "prefer_ipv4"
+	// PreferIPv6 connects over IPv6, and also tries IPv4 if no connection
+	// is established within the fallback delay.
+	PreferIPv6 = "prefer_ipv6"
+)
+
+// defaultFallbackDelay is the default fallback_delay, the same as the one
+// of net.Dialer.
+const defaultFallbackDelay = 300 * time.Millisecond
+
+func validIPFamily(family string) bool {
+	switch family {
+	case IPv4Only, IPv6Only, PreferIPv4, PreferIPv6:
+		return true
+	}
+	return false
+}
+
+// dialIPFamily returns a dial function connecting over the IP versions of
+// the family. Networks other than tcp, such as unix sockets, or tcp4 and
+// tcp6 explicitly, are dialed as is.
+func dialIPFamily(dial dialFunc, family string, delay time.Duration) dialFunc {
+	switch family {
+	case IPv4Only, IPv6Only:
+		network := "tcp4"
+		if family == IPv6Only {
+			network = "tcp6"
+		}
+		return func(ctx context.Context, n, addr string) (net.Conn, error) {
+			if n == "tcp" {
+				n = network
+			}
+			return dial(ctx, n, addr)
+		}
+	case PreferIPv6:
+		return dialPreferring(dial, "tcp6", "tcp4", delay)
+	default:
+		return dialPreferring(dial, "tcp4", "tcp6", delay)
+	}
+}
+
+// dialPreferring returns a dial function connecting over the primary
+// network, and also over the fallback one if no connection is established
+// within delay, or the primary one fails, as described by RFC 6555. The
+// first connection established is returned, or the error of the primary
+// network if none is.
+func dialPreferring(dial dialFunc, primary, fallback string, delay time.Duration) dialFunc {
+	type result struct {
+		conn    net.Conn
+		err     error
+		primary bool
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if network != "tcp" {
+			return dial(ctx, network, addr)
+		}
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		results := make(chan result, 2)
+		start := func(network string, primary bool) {
+			go func() {
+				conn, err := dial(ctx, network, addr)
+				results <- result{conn: conn, err: err, primary: primary}
+			}()
+		}
+		start(primary, true)
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		pending, fallbackStarted := 1, false
+		var primaryErr, fallbackErr error
+		for {
+			select {
+			case <-timer.C:
+				if !fallbackStarted {
+					start(fallback, false)
+					pending, fallbackStarted = pending+1, true
+				}
+			case r := <-results:
+				pending--
+				if r.err == nil {
+					if pending > 0 {
+						// close the other connection, if it is established
+						// before being cancelled
+						go func() {
+							if other := <-results; other.conn != nil {
+								other.conn.Close()
+							}
+						}()
+					}
+					return r.conn, nil
+				}
+				if r.primary {
+					primaryErr = r.err
+				} else {
+					fallbackErr = r.err
+				}
+				if !fallbackStarted {
+					start(fallback, false)
+					pending, fallbackStarted = pending+1, true
+					continue
+				}
+				if pending == 0 {
+					if primaryErr != nil {
+						return nil, primaryErr
+					}
+					return nil, fallbackErr
+				}
+			}
+		}
+	}
+}
+
+// matchesNetwork returns whether the IP address can be dialed over the
+// network, tcp4 and tcp6 only accepting IPv4 and IPv6 addresses.
+func matchesNetwork(ip, network string) bool {
+	parsed := net.ParseIP(ip)
+	switch network {
+	case "tcp4":
+		return parsed != nil && parsed.To4() != nil
+	case "tcp6":
+		return parsed != nil && parsed.To4() == nil
+	default:
+		return true
+	}
+}
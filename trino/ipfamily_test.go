@@ -0,0 +1,135 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDialer records the networks dialed, hanging until the context is
+// done for the ones in hang, and failing for the ones in fail.
+type fakeDialer struct {
+	mu     sync.Mutex
+	dialed []string
+	hang   map[string]bool
+	fail   map[string]bool
+}
+
+func (d *fakeDialer) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	d.mu.Lock()
+	d.dialed = append(d.dialed, network)
+	d.mu.Unlock()
+	if d.hang[network] {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if d.fail[network] {
+		return nil, errors.New(network + ": connection refused")
+	}
+	client, server := net.Pipe()
+	server.Close()
+	return client, nil
+}
+
+func (d *fakeDialer) networks() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.dialed...)
+}
+
+func TestDialIPFamilyOnly(t *testing.T) {
+	d := &fakeDialer{}
+	conn, err := dialIPFamily(d.dial, IPv6Only, time.Second)(context.Background(), "tcp", "coordinator:8080")
+	require.NoError(t, err)
+	conn.Close()
+	_, err = dialIPFamily(d.dial, IPv4Only, time.Second)(context.Background(), "tcp", "coordinator:8080")
+	require.NoError(t, err)
+	_, err = dialIPFamily(d.dial, IPv4Only, time.Second)(context.Background(), "unix", "/tmp/trino.sock")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tcp6", "tcp4", "unix"}, d.networks())
+}
+
+func TestDialPreferring(t *testing.T) {
+	d := &fakeDialer{}
+	conn, err := dialIPFamily(d.dial, PreferIPv4, time.Hour)(context.Background(), "tcp", "coordinator:8080")
+	require.NoError(t, err)
+	conn.Close()
+	assert.Equal(t, []string{"tcp4"}, d.networks(), "the fallback is not tried when the preferred version connects")
+
+	d = &fakeDialer{hang: map[string]bool{"tcp6": true}}
+	start := time.Now()
+	conn, err = dialIPFamily(d.dial, PreferIPv6, 20*time.Millisecond)(context.Background(), "tcp", "coordinator:8080")
+	require.NoError(t, err)
+	conn.Close()
+	assert.True(t, time.Since(start) >= 20*time.Millisecond)
+	assert.Equal(t, []string{"tcp6", "tcp4"}, d.networks(), "the fallback is tried after the delay")
+
+	d = &fakeDialer{fail: map[string]bool{"tcp4": true}}
+	conn, err = dialIPFamily(d.dial, PreferIPv4, time.Hour)(context.Background(), "tcp", "coordinator:8080")
+	require.NoError(t, err, "the fallback is tried at once when the preferred version fails")
+	conn.Close()
+
+	d = &fakeDialer{fail: map[string]bool{"tcp4": true, "tcp6": true}}
+	_, err = dialIPFamily(d.dial, PreferIPv4, time.Hour)(context.Background(), "tcp", "coordinator:8080")
+	assert.EqualError(t, err, "tcp4: connection refused")
+}
+
+func TestDNSCacheIPFamily(t *testing.T) {
+	var lookups int
+	var dialed []string
+	c := newTestDNSCache([]string{"2001:db8::1", "10.0.0.1"}, nil, &lookups, &dialed)
+	conn, err := c.dialContext(context.Background(), "tcp4", "coordinator:8080")
+	require.NoError(t, err)
+	conn.Close()
+	conn, err = c.dialContext(context.Background(), "tcp6", "coordinator:8080")
+	require.NoError(t, err)
+	conn.Close()
+	assert.Equal(t, []string{"10.0.0.1:8080", "[2001:db8::1]:8080"}, dialed)
+
+	c = newTestDNSCache([]string{"2001:db8::1"}, nil, &lookups, &dialed)
+	_, err = c.dialContext(context.Background(), "tcp4", "coordinator:8080")
+	var dnsErr *net.DNSError
+	assert.True(t, errors.As(err, &dnsErr))
+}
+
+func TestIPFamilyConfig(t *testing.T) {
+	dsn, err := (&Config{
+		ServerURI:     "http://foobar@localhost:8080",
+		IPFamily:      PreferIPv4,
+		FallbackDelay: 50 * time.Millisecond,
+	}).FormatDSN()
+	require.NoError(t, err)
+	assert.Equal(t, "http://foobar@localhost:8080?fallback_delay=50ms&ip_family=prefer_ipv4&source=trino-go-client", dsn)
+
+	conn, err := newConn(dsn)
+	require.NoError(t, err)
+	transport, ok := conn.httpClient.Transport.(*http.Transport)
+	require.True(t, ok)
+	assert.NotNil(t, transport.DialContext)
+
+	_, err = newConn("http://localhost:8080?ip_family=ipv5")
+	assert.EqualError(t, err, `trino: invalid ip_family: "ipv5"`)
+	_, err = newConn("http://localhost:8080?fallback_delay=-1s")
+	assert.EqualError(t, err, `trino: invalid fallback_delay: "-1s"`)
+}
@@ -0,0 +1,337 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// valueScanner decodes the JSON values of result pages one token at a time,
+// like encoding/json decodes them into an interface{} with UseNumber, and
+// skips values without decoding them.
+type valueScanner struct {
+	buf []byte
+	pos int
+
+	// aliasStrings makes strings without escape sequences point into buf
+	// instead of being copied, see WithZeroCopyStrings.
+	aliasStrings bool
+}
+
+func (s *valueScanner) reset(buf []byte) {
+	s.buf = buf
+	s.pos = 0
+}
+
+func (s *valueScanner) skipSpace() {
+	for s.pos < len(s.buf) {
+		switch s.buf[s.pos] {
+		case ' ', '\t', '\n', '\r':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (s *valueScanner) syntaxError() error {
+	if s.pos >= len(s.buf) {
+		return fmt.Errorf("unexpected end of JSON input")
+	}
+	return fmt.Errorf("invalid character %q at offset %d", s.buf[s.pos], s.pos)
+}
+
+// consume skips the spaces before the next byte, and consumes it if it is c.
+func (s *valueScanner) consume(c byte) bool {
+	s.skipSpace()
+	if s.pos < len(s.buf) && s.buf[s.pos] == c {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+// next returns the next byte after spaces, without consuming it, or 0 at
+// the end of the input.
+func (s *valueScanner) next() byte {
+	s.skipSpace()
+	if s.pos < len(s.buf) {
+		return s.buf[s.pos]
+	}
+	return 0
+}
+
+// value decodes the next value.
+func (s *valueScanner) value() (interface{}, error) {
+	switch s.next() {
+	case '"':
+		return s.string()
+	case '[':
+		s.pos++
+		var values []interface{}
+		if s.consume(']') {
+			return []interface{}{}, nil
+		}
+		for {
+			v, err := s.value()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+			if s.consume(']') {
+				return values, nil
+			}
+			if !s.consume(',') {
+				return nil, s.syntaxError()
+			}
+		}
+	case '{':
+		s.pos++
+		values := make(map[string]interface{})
+		if s.consume('}') {
+			return values, nil
+		}
+		for {
+			if s.next() != '"' {
+				return nil, s.syntaxError()
+			}
+			key, err := s.string()
+			if err != nil {
+				return nil, err
+			}
+			if !s.consume(':') {
+				return nil, s.syntaxError()
+			}
+			v, err := s.value()
+			if err != nil {
+				return nil, err
+			}
+			values[key] = v
+			if s.consume('}') {
+				return values, nil
+			}
+			if !s.consume(',') {
+				return nil, s.syntaxError()
+			}
+		}
+	case 't':
+		return true, s.literal("true")
+	case 'f':
+		return false, s.literal("false")
+	case 'n':
+		return nil, s.literal("null")
+	}
+	n, err := s.number()
+	if err != nil {
+		return nil, err
+	}
+	return json.Number(n), nil
+}
+
+func (s *valueScanner) literal(lit string) error {
+	if len(s.buf)-s.pos < len(lit) || string(s.buf[s.pos:s.pos+len(lit)]) != lit {
+		return s.syntaxError()
+	}
+	s.pos += len(lit)
+	return nil
+}
+
+// number returns the bytes of the next number, validating its syntax.
+func (s *valueScanner) number() ([]byte, error) {
+	start := s.pos
+	if s.pos < len(s.buf) && s.buf[s.pos] == '-' {
+		s.pos++
+	}
+	switch {
+	case s.pos < len(s.buf) && s.buf[s.pos] == '0':
+		s.pos++
+	case s.pos < len(s.buf) && '1' <= s.buf[s.pos] && s.buf[s.pos] <= '9':
+		s.digits()
+	default:
+		return nil, s.syntaxError()
+	}
+	if s.pos < len(s.buf) && s.buf[s.pos] == '.' {
+		s.pos++
+		if !s.digits() {
+			return nil, s.syntaxError()
+		}
+	}
+	if s.pos < len(s.buf) && (s.buf[s.pos] == 'e' || s.buf[s.pos] == 'E') {
+		s.pos++
+		if s.pos < len(s.buf) && (s.buf[s.pos] == '+' || s.buf[s.pos] == '-') {
+			s.pos++
+		}
+		if !s.digits() {
+			return nil, s.syntaxError()
+		}
+	}
+	return s.buf[start:s.pos], nil
+}
+
+func (s *valueScanner) digits() bool {
+	start := s.pos
+	for s.pos < len(s.buf) && '0' <= s.buf[s.pos] && s.buf[s.pos] <= '9' {
+		s.pos++
+	}
+	return s.pos > start
+}
+
+// string decodes the next string.
+func (s *valueScanner) string() (string, error) {
+	raw, escaped, err := s.stringBytes()
+	if err != nil {
+		return "", err
+	}
+	if !escaped && utf8.Valid(raw) {
+		if s.aliasStrings {
+			return unsafeString(raw), nil
+		}
+		return string(raw), nil
+	}
+	return unquoteString(raw)
+}
+
+// stringBytes returns the bytes between the quotes of the next string, and
+// whether they hold escape sequences.
+func (s *valueScanner) stringBytes() ([]byte, bool, error) {
+	s.pos++ // opening quote
+	start := s.pos
+	escaped := false
+	for s.pos < len(s.buf) {
+		switch c := s.buf[s.pos]; {
+		case c == '"':
+			s.pos++
+			return s.buf[start : s.pos-1], escaped, nil
+		case c == '\\':
+			escaped = true
+			s.pos += 2
+		case c < 0x20:
+			return nil, false, s.syntaxError()
+		default:
+			s.pos++
+		}
+	}
+	s.pos = len(s.buf)
+	return nil, false, s.syntaxError()
+}
+
+// unquoteString decodes the escape sequences of a string, and replaces
+// invalid UTF-8 with U+FFFD, like encoding/json.
+func unquoteString(raw []byte) (string, error) {
+	b := make([]byte, 0, len(raw))
+	for i := 0; i < len(raw); {
+		c := raw[i]
+		if c != '\\' {
+			r, size := utf8.DecodeRune(raw[i:])
+			b = append(b, raw[i:i+size]...)
+			if r == utf8.RuneError && size == 1 {
+				b = append(b[:len(b)-1], "�"...)
+			}
+			i += size
+			continue
+		}
+		if i+1 >= len(raw) {
+			return "", fmt.Errorf("invalid escape sequence in string")
+		}
+		switch raw[i+1] {
+		case '"', '\\', '/':
+			b = append(b, raw[i+1])
+		case 'b':
+			b = append(b, '\b')
+		case 'f':
+			b = append(b, '\f')
+		case 'n':
+			b = append(b, '\n')
+		case 'r':
+			b = append(b, '\r')
+		case 't':
+			b = append(b, '\t')
+		case 'u':
+			r, ok := hexRune(raw[i+2:])
+			if !ok {
+				return "", fmt.Errorf("invalid escape sequence in string")
+			}
+			i += 6
+			if utf16.IsSurrogate(r) {
+				r2, ok := rune(0), false
+				if i+1 < len(raw) && raw[i] == '\\' && raw[i+1] == 'u' {
+					r2, ok = hexRune(raw[i+2:])
+				}
+				if r = utf16.DecodeRune(r, r2); ok && r != utf8.RuneError {
+					i += 6
+				} else {
+					r = utf8.RuneError
+				}
+			}
+			b = append(b, string(r)...)
+			continue
+		default:
+			return "", fmt.Errorf("invalid escape sequence in string")
+		}
+		i += 2
+	}
+	return string(b), nil
+}
+
+// hexRune decodes the 4 hexadecimal digits at the start of b.
+func hexRune(b []byte) (rune, bool) {
+	if len(b) < 4 {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(string(b[:4]), 16, 32)
+	return rune(n), err == nil
+}
+
+// skip skips the next value without decoding it.
+func (s *valueScanner) skip() error {
+	switch s.next() {
+	case '"':
+		_, _, err := s.stringBytes()
+		return err
+	case '[', '{':
+		s.pos++
+		depth := 1
+		for depth > 0 {
+			if s.pos >= len(s.buf) {
+				return s.syntaxError()
+			}
+			switch s.buf[s.pos] {
+			case '"':
+				if _, _, err := s.stringBytes(); err != nil {
+					return err
+				}
+				continue
+			case '[', '{':
+				depth++
+			case ']', '}':
+				depth--
+			}
+			s.pos++
+		}
+		return nil
+	case 't':
+		return s.literal("true")
+	case 'f':
+		return s.literal("false")
+	case 'n':
+		return s.literal("null")
+	}
+	_, err := s.number()
+	return err
+}
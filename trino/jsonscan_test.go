@@ -0,0 +1,81 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValueScanner(t *testing.T) {
+	for _, doc := range []string{
+		`null`, `true`, `false`, `0`, `-12.5e+3`, `12345678901234567890`,
+		`""`, `"hello"`, `"tab\tquote\"slash\/\\"`, `"é€"`, `"😀"`, `"\ud83d"`, `"\ud83dx"`, "\"caf\xc3\xa9\"", "\"\xff\"",
+		`[]`, `[1, "a", null, [true, {}]]`, ` { "a" : [1, 2], "b": {"c": null}, "a": 3 } `,
+	} {
+		var want interface{}
+		d := json.NewDecoder(bytes.NewReader([]byte(doc)))
+		d.UseNumber()
+		require.NoError(t, d.Decode(&want), doc)
+
+		s := &valueScanner{buf: []byte(doc)}
+		got, err := s.value()
+		require.NoError(t, err, doc)
+		assert.Equal(t, want, got, doc)
+
+		s.reset([]byte(doc))
+		require.NoError(t, s.skip(), doc)
+		assert.Equal(t, len(bytes.TrimRight([]byte(doc), " ")), s.pos, doc)
+	}
+
+	for _, doc := range []string{``, `tru`, `01`, `-`, `1.`, `1e`, `"abc`, "\"a\x01\"", `"\x"`, `"\u12"`, `[1 2]`, `[1,`, `{"a" 1}`, `{1: 2}`, `[`} {
+		s := &valueScanner{buf: []byte(doc)}
+		_, err := s.value()
+		if err == nil && doc == `01` {
+			// the number ends after 0, as the trailing data is checked by
+			// the enclosing array
+			assert.Equal(t, 1, s.pos)
+			continue
+		}
+		assert.Error(t, err, doc)
+	}
+}
+
+func TestDecodeRows(t *testing.T) {
+	qr := &driverRows{
+		ctx:  WithScannedColumns(WithRawValues(context.Background(), "c"), "b", "c"),
+		stmt: &driverStmt{conn: &Conn{}},
+	}
+	data, err := qr.decodeRows(json.RawMessage(` [[1, "x", {"k": [1]}], [2, null, null] ] `), []string{"a", "b", "c"})
+	require.NoError(t, err)
+	assert.Equal(t, []queryData{
+		{nil, "x", json.RawMessage(`{"k": [1]}`)},
+		{nil, nil, json.RawMessage(`null`)},
+	}, data)
+
+	data, err = qr.decodeRows(json.RawMessage(`[]`), []string{"a"})
+	require.NoError(t, err)
+	assert.Empty(t, data)
+	data, err = qr.decodeRows(nil, []string{"a"})
+	require.NoError(t, err)
+	assert.Nil(t, data)
+	_, err = qr.decodeRows(json.RawMessage(`[[1, 2] [3]]`), []string{"a"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,99 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ErrResponseTooLarge indicates that a response from Trino exceeded the
+// max_response_bytes limit.
+type ErrResponseTooLarge struct {
+	Limit int64
+}
+
+// Error implements the error interface.
+func (e *ErrResponseTooLarge) Error() string {
+	return fmt.Sprintf("trino: response exceeds the limit of %d bytes", e.Limit)
+}
+
+// ErrValueTooLarge indicates that a value exceeded the max_value_bytes limit.
+type ErrValueTooLarge struct {
+	Column string
+	Size   int
+	Limit  int
+}
+
+// Error implements the error interface.
+func (e *ErrValueTooLarge) Error() string {
+	return fmt.Sprintf("trino: value of %d bytes in column %q exceeds the limit of %d bytes",
+		e.Size, e.Column, e.Limit)
+}
+
+// ErrStatementTooLarge indicates that a statement exceeded the
+// max_statement_bytes limit, and was not sent to Trino.
+type ErrStatementTooLarge struct {
+	Size  int
+	Limit int
+}
+
+// Error implements the error interface.
+func (e *ErrStatementTooLarge) Error() string {
+	return fmt.Sprintf("trino: statement of %d bytes exceeds the limit of %d bytes", e.Size, e.Limit)
+}
+
+// countingReader counts the bytes read from r, failing with
+// ErrResponseTooLarge once more than limit bytes are read, if set.
+type countingReader struct {
+	r     io.Reader
+	n     int64
+	limit int64
+	err   error
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	if c.limit > 0 && c.n > c.limit {
+		c.err = &ErrResponseTooLarge{Limit: c.limit}
+		return n, c.err
+	}
+	return n, err
+}
+
+func (qr *driverRows) checkValueSize(column int, v interface{}) error {
+	limit := qr.stmt.conn.maxValueBytes
+	if limit <= 0 {
+		return nil
+	}
+	var size int
+	switch x := v.(type) {
+	case string:
+		size = len(x)
+	case json.RawMessage:
+		size = len(x)
+	default:
+		return nil
+	}
+	if size > limit {
+		return &ErrValueTooLarge{Column: qr.columns[column], Size: size, Limit: limit}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stringResult returns the result of a query returning the given values of
+// a varchar column.
+func stringResult(t *testing.T, data ...queryData) string {
+	b, err := json.Marshal(data)
+	require.NoError(t, err)
+	return `"columns": [{"name": "s", "type": "varchar"}], "data": ` + string(b)
+}
+
+func TestMaxResponseBytes(t *testing.T) {
+	ts := newResultTestServer(t, stringResult(t, queryData{strings.Repeat("x", 1024)}), nil)
+
+	db, err := sql.Open("trino", ts.URL+"?max_response_bytes=512")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	_, err = db.Query("SELECT 1")
+	require.Error(t, err)
+	assert.Equal(t, &ErrResponseTooLarge{Limit: 512}, err)
+}
+
+func TestMaxValueBytes(t *testing.T) {
+	ts := newResultTestServer(t, stringResult(t, queryData{"small"}, queryData{strings.Repeat("x", 1024)}), nil)
+
+	db, err := sql.Open("trino", ts.URL+"?max_value_bytes=512")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	rows, err := db.Query("SELECT 1")
+	require.NoError(t, err)
+	for rows.Next() {
+	}
+	assert.Equal(t, &ErrValueTooLarge{Column: "s", Size: 1024, Limit: 512}, rows.Err())
+}
+
+func TestMalformedRow(t *testing.T) {
+	ts := newResultTestServer(t, stringResult(t, queryData{"a", "b"}), nil)
+
+	db, err := sql.Open("trino", ts.URL)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	var s string
+	err = db.QueryRow("SELECT 1").Scan(&s)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "malformed row")
+}
+
+func TestMaxStatementBytes(t *testing.T) {
+	db, err := sql.Open("trino", "http://localhost:9?max_statement_bytes=16")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	_, err = db.Exec("SELECT * FROM foo WHERE bar = ?", strings.Repeat("x", 16))
+	assert.Equal(t, &ErrStatementTooLarge{Size: 42, Limit: 16}, err)
+}
+
+func TestMaxHeaderBytes(t *testing.T) {
+	var header, body string
+	ts := newPagedResultTestServer(t, []string{`"stats": {"state": "FINISHED"}`}, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path == "/v1/statement" {
+			header = r.Header.Get(preparedStatementHeader)
+			b, _ := ioutil.ReadAll(r.Body)
+			body = string(b)
+		}
+		return false
+	})
+
+	db, err := sql.Open("trino", ts.URL+"?max_header_bytes=32")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	_, err = db.Exec("SELECT ?", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "_trino_go=SELECT+%3F", header)
+	assert.Equal(t, "EXECUTE _trino_go USING 1", body)
+
+	_, err = db.Exec("SELECT * FROM foo WHERE bar = 'it''s' AND baz = ?", 1)
+	require.NoError(t, err)
+	assert.Empty(t, header)
+	assert.Equal(t, "EXECUTE IMMEDIATE 'SELECT * FROM foo WHERE bar = ''it''''s'' AND baz = ?' USING 1", body)
+}
@@ -0,0 +1,74 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"log"
+	"time"
+	"unicode/utf8"
+)
+
+// Logger is used by the driver to log diagnostics, such as slow queries.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// stdLogger logs to the standard logger of the log package.
+type stdLogger struct{}
+
+func (stdLogger) Printf(format string, v ...interface{}) {
+	log.Printf(format, v...)
+}
+
+// maxLoggedQueryLength is the length after which logged SQL is truncated.
+const maxLoggedQueryLength = 256
+
+// truncateQuery truncates the query to maxLoggedQueryLength bytes, without
+// splitting a multi-byte character.
+func truncateQuery(query string) string {
+	if len(query) <= maxLoggedQueryLength {
+		return query
+	}
+	n := maxLoggedQueryLength
+	for n > 0 && !utf8.RuneStart(query[n]) {
+		n--
+	}
+	return query[:n] + "..."
+}
+
+func (qr *driverRows) logSlowQuery() {
+	c := qr.stmt.conn
+	if c.slowQuery <= 0 || qr.started.IsZero() {
+		return
+	}
+	elapsed := time.Since(qr.started)
+	if elapsed < c.slowQuery {
+		return
+	}
+	user := qr.stmt.user
+	if user == "" {
+		user = c.httpHeaders.Get(trinoUserHeader)
+	}
+	c.logger.Printf("trino: slow query %s took %v (user=%q state=%s cpu=%v rows=%d bytes=%d): %s",
+		qr.queryID,
+		elapsed.Round(time.Millisecond),
+		user,
+		qr.stats.State,
+		time.Duration(qr.stats.CPUTimeMillis)*time.Millisecond,
+		qr.stats.ProcessedRows,
+		qr.stats.ProcessedBytes,
+		truncateQuery(qr.stmt.query),
+	)
+}
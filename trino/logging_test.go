@@ -0,0 +1,93 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testLogger struct {
+	lines []string
+}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func TestSlowQueryLogging(t *testing.T) {
+	ts := newPagedResultTestServer(t, []string{`"stats": {"state": "FINISHED", "processedRows": 42}`}, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		time.Sleep(10 * time.Millisecond)
+		return false
+	})
+
+	for _, tc := range []struct {
+		Name      string
+		Threshold time.Duration
+		Logged    bool
+	}{
+		{Name: "slow", Threshold: time.Millisecond, Logged: true},
+		{Name: "fast", Threshold: time.Hour, Logged: false},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			logger := &testLogger{}
+			connector, err := NewConnector(&Config{
+				ServerURI:          "http://foobar@" + ts.Listener.Addr().String(),
+				SlowQueryThreshold: tc.Threshold,
+				Logger:             logger,
+			})
+			require.NoError(t, err)
+
+			db := sql.OpenDB(connector)
+			t.Cleanup(func() {
+				assert.NoError(t, db.Close())
+			})
+
+			_, err = db.Exec("SELECT 1")
+			require.NoError(t, err)
+
+			if !tc.Logged {
+				assert.Empty(t, logger.lines)
+				return
+			}
+			require.Len(t, logger.lines, 1)
+			assert.Contains(t, logger.lines[0], "20210101_000000_00000_abcde")
+			assert.Contains(t, logger.lines[0], `user="foobar"`)
+			assert.Contains(t, logger.lines[0], "rows=42")
+			assert.Contains(t, logger.lines[0], "SELECT 1")
+		})
+	}
+}
+
+func TestTruncateQuery(t *testing.T) {
+	query := fmt.Sprintf("SELECT '%0300d'", 0)
+	truncated := truncateQuery(query)
+	assert.Len(t, truncated, maxLoggedQueryLength+len("..."))
+	assert.Equal(t, "SELECT 1", truncateQuery("SELECT 1"))
+
+	// the 256th byte is in the middle of a 3 byte character
+	query = "SELECT '" + strings.Repeat("€", 100) + "'"
+	truncated = truncateQuery(query)
+	assert.True(t, utf8.ValidString(truncated))
+	assert.Equal(t, "SELECT '"+strings.Repeat("€", 82)+"...", truncated)
+}
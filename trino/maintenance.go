@@ -0,0 +1,190 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MaintenanceResult is the outcome of a table maintenance procedure.
+type MaintenanceResult struct {
+	UpdateType string    // e.g. "ALTER TABLE EXECUTE" or "CALL"
+	RowCount   int64     // Number of rows written by the procedure, if reported
+	Cost       QueryCost // Resources used by the procedure
+}
+
+// OptimizeOptions are the options of Optimize.
+type OptimizeOptions struct {
+	FileSizeThreshold string // Only rewrite files smaller than this size, e.g. "128MB" (optional, default is the one of the catalog)
+	Where             string // Condition on partition columns restricting the rewritten files (optional)
+}
+
+// Optimize rewrites the small files of a table of a Hive, Iceberg or Delta
+// Lake catalog into larger ones, using ALTER TABLE EXECUTE optimize.
+//
+// The table name and the condition are used as is, so they must be quoted
+// if needed, using QuoteIdentifier or QuoteQualifiedName.
+func Optimize(ctx context.Context, e Execer, table string, opts OptimizeOptions) (*MaintenanceResult, error) {
+	var args []string
+	if opts.FileSizeThreshold != "" {
+		if !dataSizePattern.MatchString(opts.FileSizeThreshold) {
+			return nil, fmt.Errorf("trino: invalid file size threshold %q", opts.FileSizeThreshold)
+		}
+		args = append(args, "file_size_threshold => "+QuoteLiteral(opts.FileSizeThreshold))
+	}
+	query, err := tableProcedure(table, "optimize", args)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Where != "" {
+		query += " WHERE " + opts.Where
+	}
+	return execMaintenance(ctx, e, query)
+}
+
+// ExpireSnapshots removes the snapshots of an Iceberg table older than the
+// retention, and the files only they reference, using ALTER TABLE EXECUTE
+// expire_snapshots. A zero retention uses the minimum retention of the
+// catalog.
+//
+// The table name is used as is, so it must be quoted if needed.
+func ExpireSnapshots(ctx context.Context, e Execer, table string, retention time.Duration) (*MaintenanceResult, error) {
+	return retentionProcedure(ctx, e, table, "expire_snapshots", retention)
+}
+
+// RemoveOrphanFiles removes the files of an Iceberg table older than the
+// retention that no snapshot references, such as the files of failed
+// writes, using ALTER TABLE EXECUTE remove_orphan_files. A zero retention
+// uses the minimum retention of the catalog.
+//
+// The table name is used as is, so it must be quoted if needed.
+func RemoveOrphanFiles(ctx context.Context, e Execer, table string, retention time.Duration) (*MaintenanceResult, error) {
+	return retentionProcedure(ctx, e, table, "remove_orphan_files", retention)
+}
+
+// ProcedureArg is a named argument of a procedure called by CallProcedure.
+type ProcedureArg struct {
+	Name  string
+	Value interface{} // Any value supported by Serial
+}
+
+// CallProcedure calls a procedure of a connector, such as
+// "hive.system.register_partition", using CALL with named arguments, which
+// are formatted as literals with Serial.
+func CallProcedure(ctx context.Context, e Execer, procedure string, args ...ProcedureArg) (*MaintenanceResult, error) {
+	if !validProcedureName(procedure) {
+		return nil, fmt.Errorf("trino: invalid procedure name %q", procedure)
+	}
+	formatted := make([]string, len(args))
+	seen := make(map[string]bool, len(args))
+	for i, arg := range args {
+		name := strings.ToLower(arg.Name)
+		if !identifierPattern.MatchString(name) {
+			return nil, fmt.Errorf("trino: invalid argument name %q of procedure %s", arg.Name, procedure)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("trino: duplicate argument %q of procedure %s", arg.Name, procedure)
+		}
+		seen[name] = true
+		value, err := Serial(arg.Value)
+		if err != nil {
+			return nil, fmt.Errorf("trino: argument %q of procedure %s: %w", arg.Name, procedure, err)
+		}
+		formatted[i] = name + " => " + value
+	}
+	return execMaintenance(ctx, e, "CALL "+procedure+"("+strings.Join(formatted, ", ")+")")
+}
+
+var identifierPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// validProcedureName reports whether the procedure is a name, optionally
+// qualified by its schema and catalog, of unquoted identifiers.
+func validProcedureName(procedure string) bool {
+	parts := strings.Split(strings.ToLower(procedure), ".")
+	if len(parts) > 3 {
+		return false
+	}
+	for _, part := range parts {
+		if !identifierPattern.MatchString(part) {
+			return false
+		}
+	}
+	return true
+}
+
+func retentionProcedure(ctx context.Context, e Execer, table, procedure string, retention time.Duration) (*MaintenanceResult, error) {
+	if retention < 0 {
+		return nil, fmt.Errorf("trino: invalid retention threshold %v", retention)
+	}
+	var args []string
+	if retention > 0 {
+		args = append(args, "retention_threshold => "+QuoteLiteral(formatTrinoDuration(retention)))
+	}
+	query, err := tableProcedure(table, procedure, args)
+	if err != nil {
+		return nil, err
+	}
+	return execMaintenance(ctx, e, query)
+}
+
+func tableProcedure(table, procedure string, args []string) (string, error) {
+	if strings.TrimSpace(table) == "" {
+		return "", fmt.Errorf("trino: %s requires a table", procedure)
+	}
+	return "ALTER TABLE " + table + " EXECUTE " + procedure + "(" + strings.Join(args, ", ") + ")", nil
+}
+
+// durationUnits are the units of Trino durations, from the largest.
+var durationUnits = []struct {
+	name string
+	size time.Duration
+}{
+	{"d", 24 * time.Hour},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+	{"ms", time.Millisecond},
+	{"us", time.Microsecond},
+	{"ns", time.Nanosecond},
+}
+
+// formatTrinoDuration formats a positive duration as a Trino duration, such
+// as 7d or 90m, in the largest unit it is a whole number of.
+func formatTrinoDuration(d time.Duration) string {
+	for _, unit := range durationUnits {
+		if d%unit.size == 0 {
+			return fmt.Sprintf("%d%s", d/unit.size, unit.name)
+		}
+	}
+	return fmt.Sprintf("%dns", d)
+}
+
+func execMaintenance(ctx context.Context, e Execer, query string) (*MaintenanceResult, error) {
+	result := &MaintenanceResult{}
+	ctx = WithQueryCost(ctx, func(cost QueryCost) {
+		result.Cost = cost
+	})
+	update, err := ExecUpdate(ctx, e, query)
+	if err != nil {
+		return nil, err
+	}
+	result.UpdateType = update.UpdateType
+	result.RowCount = update.RowCount
+	return result, nil
+}
@@ -0,0 +1,131 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableMaintenance(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"updateType": "ALTER TABLE EXECUTE", "updateCount": 120,
+		"stats": {"state": "FINISHED", "cpuTimeMillis": 1500, "wallTimeMillis": 4000, "processedRows": 120}`, &queries)
+	db := openTestDB(t, ts)
+	ctx := context.Background()
+	table := QuoteQualifiedName("iceberg", "web", "events")
+
+	result, err := Optimize(ctx, db, table, OptimizeOptions{FileSizeThreshold: "128MB", Where: "ds >= DATE '2024-01-01'"})
+	require.NoError(t, err)
+	assert.Equal(t, "ALTER TABLE EXECUTE", result.UpdateType)
+	assert.Equal(t, int64(120), result.RowCount)
+	assert.Equal(t, int64(120), result.Cost.ProcessedRows)
+	assert.Equal(t, 1500*time.Millisecond, result.Cost.CPUTime)
+	assert.Equal(t, 4*time.Second, result.Cost.WallTime)
+
+	_, err = Optimize(ctx, db, table, OptimizeOptions{})
+	require.NoError(t, err)
+	_, err = ExpireSnapshots(ctx, db, table, 7*24*time.Hour)
+	require.NoError(t, err)
+	_, err = RemoveOrphanFiles(ctx, db, table, 90*time.Minute)
+	require.NoError(t, err)
+	_, err = RemoveOrphanFiles(ctx, db, table, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`ALTER TABLE "iceberg"."web"."events" EXECUTE optimize(file_size_threshold => '128MB') WHERE ds >= DATE '2024-01-01'`,
+		`ALTER TABLE "iceberg"."web"."events" EXECUTE optimize()`,
+		`ALTER TABLE "iceberg"."web"."events" EXECUTE expire_snapshots(retention_threshold => '7d')`,
+		`ALTER TABLE "iceberg"."web"."events" EXECUTE remove_orphan_files(retention_threshold => '90m')`,
+		`ALTER TABLE "iceberg"."web"."events" EXECUTE remove_orphan_files()`,
+	}, queries)
+}
+
+func TestCallProcedure(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"updateType": "CALL"`, &queries)
+	db := openTestDB(t, ts)
+
+	result, err := CallProcedure(context.Background(), db, "hive.system.register_partition",
+		ProcedureArg{Name: "schema_name", Value: "web"},
+		ProcedureArg{Name: "table_name", Value: "page_views"},
+		ProcedureArg{Name: "partition_columns", Value: []string{"ds"}},
+		ProcedureArg{Name: "partition_values", Value: []string{"2024-01-01"}},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "CALL", result.UpdateType)
+	assert.Equal(t, []string{"CALL hive.system.register_partition(schema_name => 'web', table_name => 'page_views', " +
+		"partition_columns => ARRAY['ds'], partition_values => ARRAY['2024-01-01'])"}, queries)
+}
+
+func TestInvalidMaintenance(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"updateType": "CALL"`, &queries)
+	db := openTestDB(t, ts)
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name string
+		call func() (*MaintenanceResult, error)
+		err  string
+	}{
+		{"threshold", func() (*MaintenanceResult, error) {
+			return Optimize(ctx, db, "events", OptimizeOptions{FileSizeThreshold: "128"})
+		}, `trino: invalid file size threshold "128"`},
+		{"table", func() (*MaintenanceResult, error) {
+			return ExpireSnapshots(ctx, db, " ", time.Hour)
+		}, "trino: expire_snapshots requires a table"},
+		{"retention", func() (*MaintenanceResult, error) {
+			return RemoveOrphanFiles(ctx, db, "events", -time.Hour)
+		}, "trino: invalid retention threshold -1h0m0s"},
+		{"procedure", func() (*MaintenanceResult, error) {
+			return CallProcedure(ctx, db, "system.drop; --")
+		}, `trino: invalid procedure name "system.drop; --"`},
+		{"argument", func() (*MaintenanceResult, error) {
+			return CallProcedure(ctx, db, "system.flush_metadata_cache", ProcedureArg{Name: "schema name", Value: "web"})
+		}, `trino: invalid argument name "schema name" of procedure system.flush_metadata_cache`},
+		{"duplicate", func() (*MaintenanceResult, error) {
+			return CallProcedure(ctx, db, "system.flush_metadata_cache",
+				ProcedureArg{Name: "schema_name", Value: "web"}, ProcedureArg{Name: "SCHEMA_NAME", Value: "web"})
+		}, `trino: duplicate argument "SCHEMA_NAME" of procedure system.flush_metadata_cache`},
+		{"value", func() (*MaintenanceResult, error) {
+			return CallProcedure(ctx, db, "system.flush_metadata_cache", ProcedureArg{Name: "schema_name", Value: struct{}{}})
+		}, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.call()
+			require.Error(t, err)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+			}
+		})
+	}
+	assert.Empty(t, queries)
+}
+
+func TestFormatTrinoDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		7 * 24 * time.Hour:      "7d",
+		36 * time.Hour:          "36h",
+		90 * time.Second:        "90s",
+		1500 * time.Millisecond: "1500ms",
+		time.Microsecond + 1:    "1001ns",
+	} {
+		assert.Equal(t, want, formatTrinoDuration(d))
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// ScanMap returns a scanner decoding a Trino map into dest, a pointer to a
+// Go map with keys of the type of the Trino map keys, such as
+// *map[int64]string for map(bigint, varchar), or *map[time.Time]float64 for
+// map(date, double).
+//
+// Trino sends map keys as strings, which NullMap exposes as is. ScanMap
+// parses them instead, so that keys like 1 and 01 of a map(integer, ...)
+// are equal. Keys can be of any integer, float, bool or string type, time.Time
+// or a type implementing encoding.TextUnmarshaler. Values are decoded like
+// with ScanStruct.
+//
+// A null map sets the destination map to nil.
+func ScanMap(dest interface{}) sql.Scanner {
+	return &mapScanner{dest: dest}
+}
+
+type mapScanner struct {
+	dest interface{}
+}
+
+// Scan implements the sql.Scanner interface.
+func (s *mapScanner) Scan(value interface{}) error {
+	ptr := reflect.ValueOf(s.dest)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() || ptr.Elem().Kind() != reflect.Map {
+		return fmt.Errorf("trino: cannot scan map into %T, a pointer to a map is required", s.dest)
+	}
+	mapValue := ptr.Elem()
+	if value == nil {
+		mapValue.Set(reflect.Zero(mapValue.Type()))
+		return nil
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("trino: cannot convert %v (%T) to map", value, value)
+	}
+	keyType, elemType := mapValue.Type().Key(), mapValue.Type().Elem()
+	result := reflect.MakeMapWithSize(mapValue.Type(), len(m))
+	for k, v := range m {
+		key, err := parseMapKey(k, keyType)
+		if err != nil {
+			return err
+		}
+		elem := reflect.New(elemType).Elem()
+		if err := assignValue(v, elem); err != nil {
+			return err
+		}
+		if result.MapIndex(key).IsValid() {
+			return fmt.Errorf("trino: duplicate map key %q as %s", k, keyType)
+		}
+		result.SetMapIndex(key, elem)
+	}
+	mapValue.Set(result)
+	return nil
+}
+
+func parseMapKey(k string, t reflect.Type) (reflect.Value, error) {
+	key := reflect.New(t).Elem()
+	if t == timeType {
+		v, err := scanNullTime(k)
+		if err != nil {
+			return key, fmt.Errorf("trino: cannot convert map key %q to %s: %v", k, t, err)
+		}
+		key.Set(reflect.ValueOf(v.Time))
+		return key, nil
+	}
+	if u, ok := key.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(k)); err != nil {
+			return key, fmt.Errorf("trino: cannot convert map key %q to %s: %v", k, t, err)
+		}
+		return key, nil
+	}
+	var err error
+	switch t.Kind() {
+	case reflect.String:
+		key.SetString(k)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var v int64
+		if v, err = strconv.ParseInt(k, 10, t.Bits()); err == nil {
+			key.SetInt(v)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var v uint64
+		if v, err = strconv.ParseUint(k, 10, t.Bits()); err == nil {
+			key.SetUint(v)
+		}
+	case reflect.Float32, reflect.Float64:
+		var v float64
+		if v, err = strconv.ParseFloat(k, t.Bits()); err == nil {
+			key.SetFloat(v)
+		}
+	case reflect.Bool:
+		var v bool
+		if v, err = strconv.ParseBool(k); err == nil {
+			key.SetBool(v)
+		}
+	default:
+		return key, fmt.Errorf("trino: unsupported map key type %s", t)
+	}
+	if err != nil {
+		return key, fmt.Errorf("trino: cannot convert map key %q to %s: %v", k, t, err)
+	}
+	return key, nil
+}
@@ -0,0 +1,54 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanMap(t *testing.T) {
+	ts := newResultTestServer(t, `
+		"columns": [
+			{"name": "ints", "type": "map(bigint, varchar)"},
+			{"name": "dates", "type": "map(date, array(double))"},
+			{"name": "bools", "type": "map(boolean, integer)"},
+			{"name": "empty", "type": "map(integer, integer)"}
+		],
+		"data": [[{"1": "a", "9007199254740993": "b"}, {"2021-01-02": [1.5]}, {"true": 1}, null]]`, nil)
+	db := openTestDB(t, ts)
+
+	var ints map[int64]string
+	var dates map[time.Time][]float64
+	var bools map[bool]int32
+	empty := map[int32]int32{1: 1}
+	require.NoError(t, db.QueryRow("SELECT *").Scan(ScanMap(&ints), ScanMap(&dates), ScanMap(&bools), ScanMap(&empty)))
+	assert.Equal(t, map[int64]string{1: "a", 9007199254740993: "b"}, ints)
+	assert.Equal(t, map[time.Time][]float64{time.Date(2021, 1, 2, 0, 0, 0, 0, time.Local): {1.5}}, dates)
+	assert.Equal(t, map[bool]int32{true: 1}, bools)
+	assert.Nil(t, empty)
+}
+
+func TestScanMapErrors(t *testing.T) {
+	var ints map[int8]string
+	assert.Error(t, ScanMap(&ints).Scan(map[string]interface{}{"1000": "a"}), "out of range")
+	assert.Error(t, ScanMap(&ints).Scan(map[string]interface{}{"1": "a", "01": "b"}), "duplicate key")
+	assert.Error(t, ScanMap(ints).Scan(map[string]interface{}{}), "not a pointer")
+	var structs map[struct{}]string
+	assert.Error(t, ScanMap(&structs).Scan(map[string]interface{}{"a": "b"}), "unsupported key")
+}
@@ -0,0 +1,49 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// RefreshOptions are the options of RefreshMaterializedView.
+type RefreshOptions struct {
+	Progress QueryProgressHandler // Receives the progress of the refresh while it runs (optional)
+	Timeout  time.Duration        // Cancels the refresh if it does not complete in time (optional, default is no timeout)
+}
+
+// RefreshMaterializedView refreshes a materialized view, using REFRESH
+// MATERIALIZED VIEW, and waits for the refresh to complete, returning the
+// number of rows written to the storage table of the view.
+//
+// The view name is used as is, so it must be quoted if needed, using
+// QuoteIdentifier or QuoteQualifiedName.
+func RefreshMaterializedView(ctx context.Context, e Execer, name string, opts RefreshOptions) (*MaintenanceResult, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("trino: refreshing a materialized view requires its name")
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	if opts.Progress != nil {
+		ctx = WithQueryProgress(ctx, opts.Progress)
+	}
+	return execMaintenance(ctx, e, "REFRESH MATERIALIZED VIEW "+name)
+}
@@ -0,0 +1,60 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshMaterializedView(t *testing.T) {
+	var queries []string
+	ts := newPagedResultTestServer(t, []string{
+		`"stats": {"state": "RUNNING", "totalSplits": 10, "completedSplits": 4, "processedRows": 200}`,
+		`"updateType": "REFRESH MATERIALIZED VIEW", "updateCount": 500,
+		"stats": {"state": "FINISHED", "totalSplits": 10, "completedSplits": 10, "processedRows": 500, "cpuTimeMillis": 250}`,
+	}, &queries)
+	db := openTestDB(t, ts)
+
+	var progress []QueryProgress
+	result, err := RefreshMaterializedView(context.Background(), db, QuoteQualifiedName("iceberg", "web", "daily_views"), RefreshOptions{
+		Progress: func(p QueryProgress) { progress = append(progress, p) },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "REFRESH MATERIALIZED VIEW", result.UpdateType)
+	assert.Equal(t, int64(500), result.RowCount)
+	assert.Equal(t, 250*time.Millisecond, result.Cost.CPUTime)
+	assert.Equal(t, []string{`REFRESH MATERIALIZED VIEW "iceberg"."web"."daily_views"`}, queries)
+	require.Len(t, progress, 2)
+	assert.Equal(t, "RUNNING", progress[0].State)
+	assert.Equal(t, 0.4, progress[0].Fraction())
+	assert.Equal(t, "FINISHED", progress[1].State)
+	assert.Equal(t, 1.0, progress[1].Fraction())
+}
+
+func TestRefreshMaterializedViewTimeout(t *testing.T) {
+	ts := newStateTestServer(t, "RUNNING")
+	db := openTestDB(t, ts)
+
+	_, err := RefreshMaterializedView(context.Background(), db, "daily_views", RefreshOptions{Timeout: 50 * time.Millisecond})
+	assert.Error(t, err)
+
+	_, err = RefreshMaterializedView(context.Background(), db, " ", RefreshOptions{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,40 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrRowLimitExceeded is returned by rows.Err when a query executed with a
+// context returned by WithMaxRows has more rows than allowed.
+var ErrRowLimitExceeded = errors.New("trino: row limit exceeded")
+
+type maxRowsKey struct{}
+
+// WithMaxRows returns a context that limits queries executed with it to n
+// rows. Once n rows were scanned, requesting the next one fails with
+// ErrRowLimitExceeded, and the query is cancelled in Trino when the rows are
+// closed, which database/sql does on failure. A query with exactly n rows
+// does not fail.
+func WithMaxRows(ctx context.Context, n int64) context.Context {
+	return context.WithValue(ctx, maxRowsKey{}, n)
+}
+
+func maxRowsFromContext(ctx context.Context) int64 {
+	n, _ := ctx.Value(maxRowsKey{}).(int64)
+	return n
+}
@@ -0,0 +1,60 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxRows(t *testing.T) {
+	// the last page blocks, so the query must not be read to its end
+	ts := newPagedResultTestServer(t, integerPages(3), nil, blockPage(3, nil))
+	db := openTestDB(t, ts)
+
+	var stats FetchStats
+	ctx := WithFetchStats(context.Background(), func(s FetchStats) {
+		stats = s
+	})
+	rows, err := db.QueryContext(WithMaxRows(ctx, 3), "SELECT n FROM t")
+	require.NoError(t, err)
+	var count int
+	for rows.Next() {
+		count++
+	}
+	assert.Equal(t, ErrRowLimitExceeded, rows.Err())
+	require.NoError(t, rows.Close())
+	assert.Equal(t, 3, count)
+	assert.Equal(t, int64(3), stats.RowsReturned)
+	assert.True(t, stats.Cancelled)
+}
+
+func TestMaxRowsNotExceeded(t *testing.T) {
+	ts := newPagedResultTestServer(t, integerPages(2), nil)
+	db := openTestDB(t, ts)
+
+	rows, err := db.QueryContext(WithMaxRows(context.Background(), 4), "SELECT n FROM t")
+	require.NoError(t, err)
+	var count int
+	for rows.Next() {
+		count++
+	}
+	require.NoError(t, rows.Err())
+	require.NoError(t, rows.Close())
+	assert.Equal(t, 4, count)
+}
@@ -0,0 +1,117 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Queryer is implemented by *sql.DB, *sql.Conn and *sql.Tx, and is used by
+// the helpers that run queries on behalf of the application.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Table describes a table or view, as listed by ListTables.
+type Table struct {
+	Catalog string
+	Schema  string
+	Name    string
+	Type    string // "BASE TABLE" or "VIEW"
+}
+
+// Column describes a table column, as returned by DescribeTable.
+type Column struct {
+	Name     string
+	Type     string
+	Nullable bool
+	Position int
+	Comment  string
+}
+
+// ListCatalogs returns the names of the catalogs.
+func ListCatalogs(ctx context.Context, q Queryer) ([]string, error) {
+	return queryStrings(ctx, q, "SELECT catalog_name FROM system.metadata.catalogs ORDER BY catalog_name")
+}
+
+// ListSchemas returns the names of the schemas in a catalog.
+func ListSchemas(ctx context.Context, q Queryer, catalog string) ([]string, error) {
+	return queryStrings(ctx, q, "SELECT schema_name FROM "+QuoteIdentifier(catalog)+".information_schema.schemata ORDER BY schema_name")
+}
+
+// ListTables returns the tables and views in a schema.
+func ListTables(ctx context.Context, q Queryer, catalog, schema string) ([]Table, error) {
+	rows, err := q.QueryContext(ctx, "SELECT table_catalog, table_schema, table_name, table_type"+
+		" FROM "+QuoteIdentifier(catalog)+".information_schema.tables"+
+		" WHERE table_schema = "+QuoteLiteral(schema)+
+		" ORDER BY table_name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tables []Table
+	for rows.Next() {
+		var t Table
+		if err := rows.Scan(&t.Catalog, &t.Schema, &t.Name, &t.Type); err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	return tables, rows.Err()
+}
+
+// DescribeTable returns the columns of a table, in their declared order.
+func DescribeTable(ctx context.Context, q Queryer, catalog, schema, table string) ([]Column, error) {
+	rows, err := q.QueryContext(ctx, "SELECT column_name, data_type, is_nullable, ordinal_position, comment"+
+		" FROM "+QuoteIdentifier(catalog)+".information_schema.columns"+
+		" WHERE table_schema = "+QuoteLiteral(schema)+
+		" AND table_name = "+QuoteLiteral(table)+
+		" ORDER BY ordinal_position")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var columns []Column
+	for rows.Next() {
+		var c Column
+		var nullable string
+		var comment sql.NullString
+		if err := rows.Scan(&c.Name, &c.Type, &nullable, &c.Position, &comment); err != nil {
+			return nil, err
+		}
+		c.Nullable = nullable == "YES"
+		c.Comment = comment.String
+		columns = append(columns, c)
+	}
+	return columns, rows.Err()
+}
+
+func queryStrings(ctx context.Context, q Queryer, query string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
@@ -0,0 +1,223 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newResultTestServer returns a server answering every query with the
+// given columns and data, and recording the submitted queries.
+func newResultTestServer(t *testing.T, result string, queries *[]string) *httptest.Server {
+	return newPagedResultTestServer(t, []string{result}, queries)
+}
+
+// testServerHook is called by the servers of newPagedResultTestServer on
+// every request before serving it, and serves the request itself when it
+// returns true, e.g. to fail or block it.
+type testServerHook func(w http.ResponseWriter, r *http.Request) bool
+
+// newPagedResultTestServer returns a server answering every query with the
+// given pages of results, linked by their nextUri, recording the submitted
+// queries, and calling the hooks on every request, in order.
+func newPagedResultTestServer(tb testing.TB, pages []string, queries *[]string, hooks ...testServerHook) *httptest.Server {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, hook := range hooks {
+			if hook(w, r) {
+				return
+			}
+		}
+		servePages(w, r, ts.URL, pages, queries)
+	}))
+	tb.Cleanup(func() {
+		// unblock the requests waiting in hooks for their cancellation
+		ts.CloseClientConnections()
+		ts.Close()
+	})
+	return ts
+}
+
+// newHeaderTestServer returns a server like newResultTestServer, recording
+// the headers of the last submitted query instead.
+func newHeaderTestServer(t *testing.T, result string, headers *http.Header) *httptest.Server {
+	return newPagedResultTestServer(t, []string{result}, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method == "POST" {
+			*headers = r.Header.Clone()
+		}
+		return false
+	})
+}
+
+func serveResult(w http.ResponseWriter, r *http.Request, baseURL, result string, queries *[]string) {
+	servePages(w, r, baseURL, []string{result}, queries)
+}
+
+// servePages serves the requests of a query returning the given pages of
+// results, the last one being returned for the pages requested after it.
+func servePages(w http.ResponseWriter, r *http.Request, baseURL string, pages []string, queries *[]string) {
+	if r.Method == "DELETE" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.URL.Path == "/v1/statement" {
+		b, _ := ioutil.ReadAll(r.Body)
+		if queries != nil {
+			*queries = append(*queries, string(b))
+		}
+		json.NewEncoder(w).Encode(&stmtResponse{
+			ID:      testQueryID,
+			NextURI: testPageURI(baseURL, 1),
+		})
+		return
+	}
+	page := testPage(r)
+	if page < 1 || page > len(pages) {
+		page = len(pages)
+	}
+	next := 0
+	if page < len(pages) {
+		next = page + 1
+	}
+	writeTestPage(w, baseURL, pages[page-1], next)
+}
+
+// testQueryID is the ID of the queries of the test servers.
+const testQueryID = "20210101_000000_00000_abcde"
+
+// testPageURI returns the nextUri of a page of the queries of the test
+// servers.
+func testPageURI(baseURL string, page int) string {
+	return baseURL + "/v1/statement/" + testQueryID + "/" + strconv.Itoa(page)
+}
+
+// testPage returns the number of the page requested by r, or 0 if r is not
+// a request for a page.
+func testPage(r *http.Request) int {
+	prefix := "/v1/statement/" + testQueryID + "/"
+	if r.Method != "GET" || !strings.HasPrefix(r.URL.Path, prefix) {
+		return 0
+	}
+	page, _ := strconv.Atoi(r.URL.Path[len(prefix):])
+	return page
+}
+
+// writeTestPage writes a page of results, linked to the page next, if not 0.
+func writeTestPage(w http.ResponseWriter, baseURL, result string, next int) {
+	nextURI := ""
+	if next > 0 {
+		nextURI = `"nextUri": "` + testPageURI(baseURL, next) + `", `
+	}
+	w.Write([]byte(`{"id": "` + testQueryID + `", ` + nextURI + result + `}`))
+}
+
+// countPages returns a hook counting the requests for pages in n.
+func countPages(n *int32) testServerHook {
+	return func(w http.ResponseWriter, r *http.Request) bool {
+		if testPage(r) > 0 {
+			atomic.AddInt32(n, 1)
+		}
+		return false
+	}
+}
+
+// blockPage returns a hook blocking the requests for the given page until
+// release is closed, or the request is cancelled.
+func blockPage(page int, release <-chan struct{}) testServerHook {
+	return func(w http.ResponseWriter, r *http.Request) bool {
+		if testPage(r) != page {
+			return false
+		}
+		select {
+		case <-release:
+			return false
+		case <-r.Context().Done():
+			return true
+		}
+	}
+}
+
+func openTestDB(t *testing.T, ts *httptest.Server) *sql.DB {
+	db, err := sql.Open("trino", ts.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+	return db
+}
+
+func TestListSchemas(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `
+		"columns": [{"name": "schema_name", "type": "varchar"}],
+		"data": [["default"], ["information_schema"]]`, &queries)
+	db := openTestDB(t, ts)
+
+	schemas, err := ListSchemas(context.Background(), db, `my"catalog`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default", "information_schema"}, schemas)
+	assert.Equal(t, []string{`SELECT schema_name FROM "my""catalog".information_schema.schemata ORDER BY schema_name`}, queries)
+}
+
+func TestListTables(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `
+		"columns": [
+			{"name": "table_catalog", "type": "varchar"},
+			{"name": "table_schema", "type": "varchar"},
+			{"name": "table_name", "type": "varchar"},
+			{"name": "table_type", "type": "varchar"}
+		],
+		"data": [["hive", "o'neil", "orders", "BASE TABLE"]]`, &queries)
+	db := openTestDB(t, ts)
+
+	tables, err := ListTables(context.Background(), db, "hive", "o'neil")
+	require.NoError(t, err)
+	assert.Equal(t, []Table{{Catalog: "hive", Schema: "o'neil", Name: "orders", Type: "BASE TABLE"}}, tables)
+	require.Len(t, queries, 1)
+	assert.Contains(t, queries[0], `WHERE table_schema = 'o''neil'`)
+}
+
+func TestDescribeTable(t *testing.T) {
+	ts := newResultTestServer(t, `
+		"columns": [
+			{"name": "column_name", "type": "varchar"},
+			{"name": "data_type", "type": "varchar"},
+			{"name": "is_nullable", "type": "varchar"},
+			{"name": "ordinal_position", "type": "bigint"},
+			{"name": "comment", "type": "varchar"}
+		],
+		"data": [["id", "bigint", "NO", 1, "primary key"], ["name", "varchar", "YES", 2, null]]`, nil)
+	db := openTestDB(t, ts)
+
+	columns, err := DescribeTable(context.Background(), db, "hive", "default", "orders")
+	require.NoError(t, err)
+	assert.Equal(t, []Column{
+		{Name: "id", Type: "bigint", Nullable: false, Position: 1, Comment: "primary key"},
+		{Name: "name", Type: "varchar", Nullable: true, Position: 2},
+	}, columns)
+}
@@ -0,0 +1,47 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+)
+
+// maxExactFloatInt is the magnitude from which float64 values may not be
+// the exact value of the integer they were decoded from.
+const maxExactFloatInt = 1 << 53
+
+// scanFloatInt64 converts an integer decoded as float64, failing if it
+// may have lost precision rather than returning a corrupted value.
+func scanFloatInt64(v float64) (sql.NullInt64, error) {
+	if v != math.Trunc(v) || math.Abs(v) >= maxExactFloatInt {
+		return sql.NullInt64{}, fmt.Errorf("cannot convert %v (%T) to int64: loss of precision", v, v)
+	}
+	return sql.NullInt64{Valid: true, Int64: int64(v)}, nil
+}
+
+// checkLossless fails, in strict_numbers mode, on integers decoded as
+// float64, which only happens when using a JSONDecoder that does not
+// decode numbers as json.Number.
+func (c *typeConverter) checkLossless(v interface{}) error {
+	if !c.strictNumbers {
+		return nil
+	}
+	if _, ok := v.(float64); ok {
+		return fmt.Errorf("trino: cannot convert %v (%T) to %s: integers must be decoded as json.Number in strict_numbers mode", v, v, c.typeName)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const largeIntegersResult = `
+	"columns": [
+		{"name": "bi", "type": "bigint"},
+		{"name": "arr", "type": "array(bigint)"},
+		{"name": "m", "type": "map(varchar, bigint)"}
+	],
+	"data": [[9007199254740993, [9007199254740993], {"k": 9007199254740993}]]`
+
+func TestLargeIntegers(t *testing.T) {
+	ts := newResultTestServer(t, largeIntegersResult, nil)
+	db := openTestDB(t, ts)
+
+	var bi int64
+	var arr NullSliceInt64
+	var m NullMap
+	require.NoError(t, db.QueryRow("SELECT *").Scan(&bi, &arr, &m))
+	assert.Equal(t, int64(9007199254740993), bi)
+	assert.Equal(t, []sql.NullInt64{{Int64: 9007199254740993, Valid: true}}, arr.SliceInt64)
+	n, err := m.Map["k"].(json.Number).Int64()
+	require.NoError(t, err)
+	assert.Equal(t, int64(9007199254740993), n)
+}
+
+// floatJSONDecoder decodes numbers as float64, losing precision.
+type floatJSONDecoder struct{}
+
+func (floatJSONDecoder) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+func TestLossyIntegers(t *testing.T) {
+	ts := newResultTestServer(t, largeIntegersResult, nil)
+
+	for _, strict := range []bool{false, true} {
+		connector, err := NewConnector(&Config{ServerURI: ts.URL, JSONDecoder: floatJSONDecoder{}, StrictNumbers: strict})
+		require.NoError(t, err)
+		db := sql.OpenDB(connector)
+
+		var bi int64
+		var arr NullSliceInt64
+		var m NullMap
+		err = db.QueryRow("SELECT *").Scan(&bi, &arr, &m)
+		assert.Error(t, err, "corrupted integers must not be returned")
+		assert.NoError(t, db.Close())
+	}
+}
+
+func TestFloatIntegers(t *testing.T) {
+	v, err := newTypeConverter("bigint").ConvertValue(float64(42))
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), v)
+
+	_, err = newTypeConverter("bigint").ConvertValue(1.5)
+	assert.Error(t, err)
+
+	strict := newTypeConverter("bigint")
+	strict.strictNumbers = true
+	_, err = strict.ConvertValue(float64(42))
+	assert.Error(t, err)
+}
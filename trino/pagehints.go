@@ -0,0 +1,71 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Limits Trino applies to the page hints, which are rejected by the
+// driver when exceeded rather than silently capped by the server.
+const (
+	maxPageWait         = time.Second
+	maxTargetResultSize = 128 << 20
+)
+
+// addPageHints adds the max_wait and target_result_size hints to the URI
+// of the next page of a query, as the maxWait and targetResultSize query
+// parameters read by Trino.
+func (c *Conn) addPageHints(uri string) (string, error) {
+	if c.maxWait == 0 && c.targetResultSize == 0 {
+		return uri, nil
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("trino: malformed nextUri: %v", err)
+	}
+	query := u.Query()
+	if c.maxWait > 0 {
+		query.Set("maxWait", strconv.FormatInt(c.maxWait.Milliseconds(), 10)+"ms")
+	}
+	if c.targetResultSize > 0 {
+		query.Set("targetResultSize", strconv.FormatInt(c.targetResultSize, 10)+"B")
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// parseMaxWait parses the max_wait parameter, which must be between 1ms
+// and 1s, the longest Trino holds a request.
+func parseMaxWait(v string) (time.Duration, error) {
+	d, err := time.ParseDuration(v)
+	if err != nil || d < time.Millisecond || d > maxPageWait {
+		return 0, invalidParameter("max_wait", v)
+	}
+	return d, nil
+}
+
+// parseTargetResultSize parses the target_result_size parameter, in
+// bytes, which must be at most 128MB, the largest page Trino returns.
+func parseTargetResultSize(v string) (int64, error) {
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 || n > maxTargetResultSize {
+		return 0, invalidParameter("target_result_size", v)
+	}
+	return n, nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageHints(t *testing.T) {
+	var statementQuery string
+	var pageQueries []string
+	result := `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`
+	ts := newPagedResultTestServer(t, []string{result}, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if testPage(r) > 0 {
+			pageQueries = append(pageQueries, r.URL.RawQuery)
+			return false
+		}
+		if r.URL.Path != "/v1/statement" {
+			return false
+		}
+		statementQuery = r.URL.RawQuery
+		json.NewEncoder(w).Encode(&stmtResponse{
+			ID:      testQueryID,
+			NextURI: testPageURI("http://"+r.Host, 1) + "?slug=x",
+		})
+		return true
+	})
+
+	dsn, err := (&Config{ServerURI: ts.URL, MaxWait: 250 * time.Millisecond, TargetResultSize: 1 << 20}).FormatDSN()
+	require.NoError(t, err)
+	assert.Contains(t, dsn, "max_wait=250ms")
+	assert.Contains(t, dsn, "target_result_size=1048576")
+	db, err := sql.Open("trino", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	var v int
+	require.NoError(t, db.QueryRow("SELECT 1").Scan(&v))
+	assert.Empty(t, statementQuery)
+	assert.Equal(t, []string{"maxWait=250ms&slug=x&targetResultSize=1048576B"}, pageQueries)
+}
+
+func TestPageHintsDSN(t *testing.T) {
+	for _, dsn := range []string{
+		"http://foobar@localhost:8080?max_wait=soon",
+		"http://foobar@localhost:8080?max_wait=2s",
+		"http://foobar@localhost:8080?max_wait=10us",
+		"http://foobar@localhost:8080?target_result_size=-1",
+		"http://foobar@localhost:8080?target_result_size=1GB",
+		"http://foobar@localhost:8080?target_result_size=268435456",
+	} {
+		_, err := newConn(dsn)
+		assert.Error(t, err, dsn)
+	}
+	_, err := newConn("http://foobar@localhost:8080?max_wait=2s")
+	assert.EqualError(t, err, `trino: invalid max_wait: "2s"`)
+
+	c, err := newConn("http://foobar@localhost:8080?max_wait=1s&target_result_size=134217728")
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, c.maxWait)
+	assert.Equal(t, int64(128<<20), c.targetResultSize)
+}
@@ -0,0 +1,290 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned by FetchPage for a cursor that was not
+// returned by a previous call for the same request.
+var ErrInvalidCursor = errors.New("trino: invalid page cursor")
+
+// PageRequest describes the pages of the result of a query, fetched with
+// FetchPage.
+//
+// Trino cannot resume reading a result, so each page is fetched by a query
+// of its own, which must return rows in the same order every time:
+//
+//   - with Keys, the query is filtered to the rows following the last row
+//     of the previous page, and ordered by the keys, which must uniquely
+//     identify the rows. This keyset pagination is efficient and stable
+//     while rows are added, and the query does not need an ORDER BY clause.
+//   - without Keys, pages are read with OFFSET and LIMIT clauses, and the
+//     query must have a top-level ORDER BY clause, on columns uniquely
+//     identifying the rows.
+//
+// The query must not have LIMIT, OFFSET or FETCH clauses.
+type PageRequest struct {
+	Query string        // Query returning the rows to paginate
+	Args  []interface{} // Arguments of the query
+	Size  int           // Number of rows of each page
+	Keys  []string      // Columns uniquely identifying the rows, in ascending order, for keyset pagination (optional)
+}
+
+// Page is a page of rows returned by FetchPage.
+type Page struct {
+	Columns    []string        // Names of the columns
+	Rows       [][]interface{} // Values of the rows, as scanned into an interface{}
+	NextCursor string          // Cursor of the next page, empty for the last page
+}
+
+// pageCursor is the position of a page, encoded as base64 JSON.
+type pageCursor struct {
+	Request string   `json:"r"`           // hash of the request, to detect cursors of other requests
+	Offset  int64    `json:"o,omitempty"` // rows before the page
+	Keys    []string `json:"k,omitempty"` // keys of the last row of the previous page, as literals
+	Types   []string `json:"t,omitempty"` // types of the keys
+}
+
+// FetchPage fetches the page of rows of the request at the cursor, which is
+// empty for the first page, and the NextCursor of the previous page for the
+// following ones. Cursors are opaque strings that can be handed to users,
+// e.g. in URLs.
+func FetchPage(ctx context.Context, q Queryer, req PageRequest, cursor string) (*Page, error) {
+	if req.Size <= 0 {
+		return nil, fmt.Errorf("trino: invalid page size: %d", req.Size)
+	}
+	if err := checkPageQuery(req); err != nil {
+		return nil, err
+	}
+	c := pageCursor{Request: req.hash()}
+	if cursor != "" {
+		var err error
+		if c, err = decodePageCursor(cursor, req); err != nil {
+			return nil, err
+		}
+	}
+	query, args := req.pageQuery(c)
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	page := &Page{Columns: columns}
+	more := false
+	for rows.Next() {
+		if len(page.Rows) == req.Size {
+			more = true
+			break
+		}
+		row := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range row {
+			dest[i] = &row[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		page.Rows = append(page.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if !more {
+		return page, nil
+	}
+	next := pageCursor{Request: c.Request, Offset: c.Offset + int64(req.Size)}
+	if len(req.Keys) > 0 {
+		next.Offset = 0
+		last := page.Rows[len(page.Rows)-1]
+		for _, key := range req.Keys {
+			i := columnIndex(columns, key)
+			if i < 0 {
+				return nil, fmt.Errorf("trino: key column %q not in the result", key)
+			}
+			typeName := strings.ToLower(types[i].DatabaseTypeName())
+			if typeName == "timestamp" || typeName == "time" {
+				// the precision is left out of the type name, use the
+				// highest one so that the key is not rounded
+				typeName += "(12)"
+			}
+			literal, err := keyLiteral(last[i], typeName)
+			if err != nil {
+				return nil, fmt.Errorf("trino: key column %q: %v", key, err)
+			}
+			next.Keys = append(next.Keys, literal)
+			next.Types = append(next.Types, typeName)
+		}
+	}
+	b, err := json.Marshal(next)
+	if err != nil {
+		return nil, err
+	}
+	page.NextCursor = base64.RawURLEncoding.EncodeToString(b)
+	return page, nil
+}
+
+// checkPageQuery checks that the order of the rows of the query is set,
+// and that the number of rows is not limited.
+func checkPageQuery(req PageRequest) error {
+	if !isQuery(req.Query) {
+		return errors.New("trino: only queries can be paginated")
+	}
+	ordered := false
+	tokens := topLevelTokens(req.Query)
+	for i, t := range tokens {
+		switch strings.ToUpper(t.text) {
+		case "LIMIT", "OFFSET", "FETCH":
+			return fmt.Errorf("trino: paginated queries must not have a %s clause", strings.ToUpper(t.text))
+		case "ORDER":
+			ordered = ordered || (i+1 < len(tokens) && strings.ToUpper(tokens[i+1].text) == "BY")
+		}
+	}
+	if !ordered && len(req.Keys) == 0 {
+		return errors.New("trino: queries paginated without keys must have an ORDER BY clause")
+	}
+	return nil
+}
+
+// hash identifies the request in cursors.
+func (req PageRequest) hash() string {
+	h := sha256.New()
+	h.Write([]byte(req.Query))
+	for _, key := range req.Keys {
+		h.Write([]byte("\x00" + key))
+	}
+	fmt.Fprintf(h, "\x00%d", req.Size)
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// pageQuery returns the query of the page at the cursor, and its arguments.
+// One more row than the size of the page is read, to find out whether it is
+// the last one.
+func (req PageRequest) pageQuery(c pageCursor) (string, []interface{}) {
+	limit := strconv.Itoa(req.Size + 1)
+	query := strings.TrimRightFunc(req.Query, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == ';'
+	})
+	if len(req.Keys) == 0 {
+		if c.Offset > 0 {
+			query += "\nOFFSET " + strconv.FormatInt(c.Offset, 10)
+		}
+		return query + "\nLIMIT " + limit, req.Args
+	}
+	keys := make([]string, len(req.Keys))
+	for i, key := range req.Keys {
+		keys[i] = QuoteIdentifier(key)
+	}
+	args := req.Args
+	query = "SELECT * FROM (\n" + query + "\n)"
+	if len(c.Keys) > 0 {
+		params := make([]string, len(c.Keys))
+		args = append([]interface{}(nil), req.Args...)
+		for i := range c.Keys {
+			params[i] = "CAST(? AS " + c.Types[i] + ")"
+			args = append(args, c.Keys[i])
+		}
+		query += " WHERE (" + strings.Join(keys, ", ") + ") > (" + strings.Join(params, ", ") + ")"
+	}
+	return query + " ORDER BY " + strings.Join(keys, ", ") + " LIMIT " + limit, args
+}
+
+// keyTypes are the types of the keys supported by keyset pagination,
+// optionally followed by parameters, such as decimal(10, 2).
+var keyTypes = regexp.MustCompile(`^(tinyint|smallint|integer|bigint|real|double|boolean|uuid|date|varchar|decimal|time|timestamp)(\(\d+(, ?\d+)?\))?( with time zone)?$`)
+
+func decodePageCursor(cursor string, req PageRequest) (pageCursor, error) {
+	var c pageCursor
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, &c); err != nil || c.Request != req.hash() || c.Offset < 0 ||
+		len(c.Keys) != len(c.Types) || (len(req.Keys) > 0 && len(c.Keys) != len(req.Keys)) {
+		return c, ErrInvalidCursor
+	}
+	for _, t := range c.Types {
+		// types are part of the query
+		if !keyTypes.MatchString(t) {
+			return c, ErrInvalidCursor
+		}
+	}
+	return c, nil
+}
+
+// keyLiteral returns the string the value of a key is cast from in the
+// query of the next page.
+func keyLiteral(v interface{}, trinoType string) (string, error) {
+	if !keyTypes.MatchString(trinoType) {
+		return "", fmt.Errorf("unsupported type %s", trinoType)
+	}
+	switch v := v.(type) {
+	case nil:
+		return "", errors.New("NULL value")
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case time.Time:
+		layout := "2006-01-02 15:04:05.999999999"
+		switch baseTypeName(trinoType) {
+		case "date":
+			layout = "2006-01-02"
+		case "time":
+			layout = "15:04:05.999999999"
+		case "time with time zone":
+			layout = "15:04:05.999999999 -07:00"
+		case "timestamp with time zone":
+			layout = "2006-01-02 15:04:05.999999999 -07:00"
+		}
+		return v.Format(layout), nil
+	default:
+		return "", fmt.Errorf("unsupported value %T", v)
+	}
+}
+
+func columnIndex(columns []string, name string) int {
+	for i, c := range columns {
+		if strings.EqualFold(c, name) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,139 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pageResult = `
+	"columns": [
+		{"name": "id", "type": "bigint"},
+		{"name": "ts", "type": "timestamp(3)"},
+		{"name": "name", "type": "varchar"}
+	],
+	"data": [
+		[1, "2021-01-01 00:00:00.000", "a"],
+		[2, "2021-01-02 00:00:00.000", "b"],
+		[3, "2021-01-03 00:00:00.000", "c"]
+	]`
+
+// newPageTestServer returns a server recording the statements it runs, with
+// their prepared statements, if any.
+func newPageTestServer(t *testing.T, statements *[]string) *sql.DB {
+	ts := newPagedResultTestServer(t, []string{pageResult}, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method != "POST" {
+			return false
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		statement := string(b)
+		if prepared := r.Header.Get(preparedStatementHeader); prepared != "" {
+			prepared, _ = url.QueryUnescape(prepared)
+			statement = prepared + " | " + statement
+		}
+		*statements = append(*statements, statement)
+		return false
+	})
+	return openTestDB(t, ts)
+}
+
+func TestFetchPageOffset(t *testing.T) {
+	var statements []string
+	db := newPageTestServer(t, &statements)
+	req := PageRequest{Query: "SELECT id, ts, name FROM t ORDER BY id;", Size: 2}
+
+	page, err := FetchPage(context.Background(), db, req, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "ts", "name"}, page.Columns)
+	require.Len(t, page.Rows, 2)
+	assert.Equal(t, int64(2), page.Rows[1][0])
+	require.NotEmpty(t, page.NextCursor)
+
+	_, err = FetchPage(context.Background(), db, req, page.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"SELECT id, ts, name FROM t ORDER BY id\nLIMIT 3",
+		"SELECT id, ts, name FROM t ORDER BY id\nOFFSET 2\nLIMIT 3",
+	}, statements)
+
+	// cursors only apply to the request they were returned for
+	req.Size = 3
+	_, err = FetchPage(context.Background(), db, req, page.NextCursor)
+	assert.Equal(t, ErrInvalidCursor, err)
+}
+
+func TestFetchPageKeyset(t *testing.T) {
+	var statements []string
+	db := newPageTestServer(t, &statements)
+	req := PageRequest{
+		Query: "SELECT id, ts, name FROM t WHERE name <> ?",
+		Args:  []interface{}{"z"},
+		Size:  2,
+		Keys:  []string{"ts", "id"},
+	}
+
+	page, err := FetchPage(context.Background(), db, req, "")
+	require.NoError(t, err)
+	require.Len(t, page.Rows, 2)
+	_, err = FetchPage(context.Background(), db, req, page.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"_trino_go=SELECT * FROM (\nSELECT id, ts, name FROM t WHERE name <> ?\n)" +
+			` ORDER BY "ts", "id" LIMIT 3 | EXECUTE _trino_go USING 'z'`,
+		"_trino_go=SELECT * FROM (\nSELECT id, ts, name FROM t WHERE name <> ?\n)" +
+			` WHERE ("ts", "id") > (CAST(? AS timestamp(12)), CAST(? AS bigint)) ORDER BY "ts", "id" LIMIT 3` +
+			` | EXECUTE _trino_go USING 'z', '2021-01-02 00:00:00', '2'`,
+	}, statements)
+}
+
+func TestFetchPageInvalid(t *testing.T) {
+	for _, req := range []PageRequest{
+		{Query: "SELECT * FROM t ORDER BY id", Size: 0},
+		{Query: "SELECT * FROM t", Size: 10},
+		{Query: "SELECT * FROM t ORDER BY id LIMIT 100", Size: 10},
+		{Query: "SELECT * FROM t ORDER BY id OFFSET 100", Size: 10},
+		{Query: "DELETE FROM t", Size: 10, Keys: []string{"id"}},
+	} {
+		_, err := FetchPage(context.Background(), nil, req, "")
+		assert.Error(t, err, req.Query)
+	}
+
+	req := PageRequest{Query: "SELECT * FROM t", Size: 10, Keys: []string{"id"}}
+	// types are part of the query of the page
+	injected, err := json.Marshal(pageCursor{
+		Request: req.hash(),
+		Keys:    []string{"1"},
+		Types:   []string{"bigint) OR (true"},
+	})
+	require.NoError(t, err)
+	for _, cursor := range []string{
+		"not base64!",
+		"e30",
+		base64.RawURLEncoding.EncodeToString(injected),
+	} {
+		_, err := FetchPage(context.Background(), nil, req, cursor)
+		assert.Equal(t, ErrInvalidCursor, err, cursor)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Connectors supported by PartitionedTable.
+const (
+	HiveConnector    = "hive"
+	IcebergConnector = "iceberg"
+)
+
+// Modes of the system.sync_partition_metadata procedure of Hive catalogs.
+const (
+	SyncAdd  = "ADD"  // Adds the partitions found in the storage
+	SyncDrop = "DROP" // Drops the partitions missing from the storage
+	SyncFull = "FULL" // Adds and drops partitions
+)
+
+// PartitionedTable describes a table of a Hive or Iceberg catalog, to
+// generate the statements writing to it, which the layout of the table
+// makes error-prone to write by hand: Hive requires the partition columns
+// to be the last columns of the table, in the order they are declared,
+// and the values of all columns must be cast to the types of the table.
+type PartitionedTable struct {
+	Connector   string   // HiveConnector or IcebergConnector
+	Catalog     string   // Catalog of the table (optional, default is the one of the session)
+	Schema      string   // Schema of the table (optional, default is the one of the session)
+	Name        string   // Name of the table
+	Columns     []Column // Columns of the table, their values being cast to their Type, if set
+	PartitionBy []string // Partition columns for Hive, partitioning such as day(ts) or bucket(id, 16) for Iceberg (optional)
+	Format      string   // File format, e.g. PARQUET or ORC (optional, default is the one of the catalog)
+}
+
+// CreateTableAs returns the CREATE TABLE AS statement creating the table
+// with the rows of the query, whose columns must have the names of the
+// columns of the table.
+func (t *PartitionedTable) CreateTableAs(query string) (string, error) {
+	columns, err := t.orderedColumns()
+	if err != nil {
+		return "", err
+	}
+	var properties []string
+	if len(t.PartitionBy) > 0 {
+		name := "partitioned_by"
+		if t.Connector == IcebergConnector {
+			name = "partitioning"
+		}
+		properties = append(properties, name+" = "+literalArray(t.PartitionBy))
+	}
+	if t.Format != "" {
+		properties = append(properties, "format = "+QuoteLiteral(t.Format))
+	}
+	statement := "CREATE TABLE " + t.qualifiedName()
+	if len(properties) > 0 {
+		statement += " WITH (" + strings.Join(properties, ", ") + ")"
+	}
+	return statement + " AS " + selectColumns(columns, query), nil
+}
+
+// Insert returns the INSERT statement adding the rows of the query to the
+// table, whose columns must have the names of the columns of the table.
+func (t *PartitionedTable) Insert(query string) (string, error) {
+	columns, err := t.orderedColumns()
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = QuoteIdentifier(c.Name)
+	}
+	return "INSERT INTO " + t.qualifiedName() + " (" + strings.Join(names, ", ") + ") " + selectColumns(columns, query), nil
+}
+
+// SyncPartitionMetadata returns the call of the sync_partition_metadata
+// procedure, registering in the metastore the partitions of a Hive table
+// written outside of Trino, with SyncAdd, SyncDrop or SyncFull.
+func (t *PartitionedTable) SyncPartitionMetadata(mode string) (string, error) {
+	if t.Connector != HiveConnector {
+		return "", fmt.Errorf("trino: sync_partition_metadata is only supported by the hive connector")
+	}
+	switch mode {
+	case SyncAdd, SyncDrop, SyncFull:
+	default:
+		return "", fmt.Errorf("trino: invalid sync_partition_metadata mode %q", mode)
+	}
+	if t.Schema == "" || t.Name == "" {
+		return "", fmt.Errorf("trino: sync_partition_metadata requires the schema and name of the table")
+	}
+	procedure := "system.sync_partition_metadata"
+	if t.Catalog != "" {
+		procedure = QuoteIdentifier(t.Catalog) + "." + procedure
+	}
+	return "CALL " + procedure + "(schema_name => " + QuoteLiteral(t.Schema) +
+		", table_name => " + QuoteLiteral(t.Name) +
+		", mode => " + QuoteLiteral(mode) + ")", nil
+}
+
+// orderedColumns returns the columns of the table in the order of the
+// table: for Hive, the partition columns last, in the order of PartitionBy.
+func (t *PartitionedTable) orderedColumns() ([]Column, error) {
+	if t.Connector != HiveConnector && t.Connector != IcebergConnector {
+		return nil, fmt.Errorf("trino: unsupported connector %q, expected %s or %s", t.Connector, HiveConnector, IcebergConnector)
+	}
+	if t.Name == "" || len(t.Columns) == 0 {
+		return nil, fmt.Errorf("trino: table name and columns are required")
+	}
+	byName := make(map[string]Column, len(t.Columns))
+	for _, c := range t.Columns {
+		byName[c.Name] = c
+	}
+	partitioned := make(map[string]bool, len(t.PartitionBy))
+	for _, p := range t.PartitionBy {
+		name := p
+		if t.Connector == IcebergConnector {
+			name = partitionSourceColumn(p)
+		}
+		if _, ok := byName[name]; !ok {
+			return nil, fmt.Errorf("trino: unknown partition column %q", name)
+		}
+		partitioned[name] = true
+	}
+	if t.Connector == IcebergConnector {
+		return t.Columns, nil
+	}
+	if len(partitioned) == len(t.Columns) {
+		return nil, fmt.Errorf("trino: hive tables require at least one column that is not a partition column")
+	}
+	columns := make([]Column, 0, len(t.Columns))
+	for _, c := range t.Columns {
+		if !partitioned[c.Name] {
+			columns = append(columns, c)
+		}
+	}
+	for _, p := range t.PartitionBy {
+		columns = append(columns, byName[p])
+	}
+	return columns, nil
+}
+
+func (t *PartitionedTable) qualifiedName() string {
+	var parts []string
+	if t.Catalog != "" {
+		parts = append(parts, t.Catalog)
+	}
+	if t.Schema != "" {
+		parts = append(parts, t.Schema)
+	}
+	return QuoteQualifiedName(append(parts, t.Name)...)
+}
+
+// partitionSourceColumn returns the column an Iceberg partitioning applies
+// to, e.g. ts for day(ts) and id for bucket(id, 16).
+func partitionSourceColumn(partitioning string) string {
+	column := partitioning
+	if i := strings.Index(column, "("); i >= 0 {
+		column = column[i+1:]
+		if j := strings.IndexAny(column, ",)"); j >= 0 {
+			column = column[:j]
+		}
+	}
+	column = strings.TrimSpace(column)
+	if len(column) >= 2 && column[0] == '"' && column[len(column)-1] == '"' {
+		column = strings.Replace(column[1:len(column)-1], `""`, `"`, -1)
+	}
+	return column
+}
+
+// selectColumns returns the query selecting the columns from the rows of
+// query, cast to their types.
+func selectColumns(columns []Column, query string) string {
+	exprs := make([]string, len(columns))
+	for i, c := range columns {
+		name := QuoteIdentifier(c.Name)
+		if c.Type == "" {
+			exprs[i] = name
+			continue
+		}
+		exprs[i] = "CAST(" + name + " AS " + c.Type + ") AS " + name
+	}
+	return "SELECT " + strings.Join(exprs, ", ") + " FROM (" + query + ") AS source"
+}
+
+func literalArray(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = QuoteLiteral(v)
+	}
+	return "ARRAY[" + strings.Join(quoted, ", ") + "]"
+}
@@ -0,0 +1,105 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHivePartitionedTable(t *testing.T) {
+	table := &PartitionedTable{
+		Connector: HiveConnector,
+		Catalog:   "hive",
+		Schema:    "web",
+		Name:      "page_views",
+		Columns: []Column{
+			{Name: "country", Type: "varchar"},
+			{Name: "view_time", Type: "timestamp(3)"},
+			{Name: "ds", Type: "date"},
+			{Name: "user_id", Type: "bigint"},
+		},
+		PartitionBy: []string{"ds", "country"},
+		Format:      "ORC",
+	}
+	ctas, err := table.CreateTableAs("SELECT * FROM staging")
+	require.NoError(t, err)
+	assert.Equal(t, `CREATE TABLE "hive"."web"."page_views" WITH (partitioned_by = ARRAY['ds', 'country'], format = 'ORC')`+
+		` AS SELECT CAST("view_time" AS timestamp(3)) AS "view_time", CAST("user_id" AS bigint) AS "user_id",`+
+		` CAST("ds" AS date) AS "ds", CAST("country" AS varchar) AS "country" FROM (SELECT * FROM staging) AS source`, ctas)
+
+	insert, err := table.Insert("SELECT * FROM staging")
+	require.NoError(t, err)
+	assert.Equal(t, `INSERT INTO "hive"."web"."page_views" ("view_time", "user_id", "ds", "country")`+
+		` SELECT CAST("view_time" AS timestamp(3)) AS "view_time", CAST("user_id" AS bigint) AS "user_id",`+
+		` CAST("ds" AS date) AS "ds", CAST("country" AS varchar) AS "country" FROM (SELECT * FROM staging) AS source`, insert)
+
+	call, err := table.SyncPartitionMetadata(SyncFull)
+	require.NoError(t, err)
+	assert.Equal(t, `CALL "hive".system.sync_partition_metadata(schema_name => 'web', table_name => 'page_views', mode => 'FULL')`, call)
+	_, err = table.SyncPartitionMetadata("ALL")
+	assert.EqualError(t, err, `trino: invalid sync_partition_metadata mode "ALL"`)
+}
+
+func TestIcebergPartitionedTable(t *testing.T) {
+	table := &PartitionedTable{
+		Connector: IcebergConnector,
+		Name:      "events",
+		Columns: []Column{
+			{Name: "id", Type: "bigint"},
+			{Name: "ts", Type: "timestamp(6) with time zone"},
+			{Name: "payload"},
+		},
+		PartitionBy: []string{"day(ts)", "bucket(id, 16)"},
+	}
+	ctas, err := table.CreateTableAs("SELECT * FROM staging")
+	require.NoError(t, err)
+	assert.Equal(t, `CREATE TABLE "events" WITH (partitioning = ARRAY['day(ts)', 'bucket(id, 16)'])`+
+		` AS SELECT CAST("id" AS bigint) AS "id", CAST("ts" AS timestamp(6) with time zone) AS "ts", "payload"`+
+		` FROM (SELECT * FROM staging) AS source`, ctas)
+
+	_, err = table.SyncPartitionMetadata(SyncAdd)
+	assert.Error(t, err)
+}
+
+func TestInvalidPartitionedTable(t *testing.T) {
+	for _, tc := range []struct {
+		table *PartitionedTable
+		err   string
+	}{
+		{&PartitionedTable{Connector: "delta", Name: "t", Columns: []Column{{Name: "a"}}}, `trino: unsupported connector "delta", expected hive or iceberg`},
+		{&PartitionedTable{Connector: HiveConnector, Name: "t"}, "trino: table name and columns are required"},
+		{&PartitionedTable{Connector: HiveConnector, Name: "t", Columns: []Column{{Name: "a"}}, PartitionBy: []string{"b"}}, `trino: unknown partition column "b"`},
+		{&PartitionedTable{Connector: IcebergConnector, Name: "t", Columns: []Column{{Name: "a"}}, PartitionBy: []string{"month(b)"}}, `trino: unknown partition column "b"`},
+		{&PartitionedTable{Connector: HiveConnector, Name: "t", Columns: []Column{{Name: "a"}}, PartitionBy: []string{"a"}}, "trino: hive tables require at least one column that is not a partition column"},
+	} {
+		_, err := tc.table.Insert("SELECT 1")
+		assert.EqualError(t, err, tc.err)
+	}
+}
+
+func TestPartitionSourceColumn(t *testing.T) {
+	for partitioning, column := range map[string]string{
+		"ts":                   "ts",
+		"day(ts)":              "ts",
+		"bucket(id, 16)":       "id",
+		"truncate( name ,4)":   "name",
+		`year("Event ""Time")`: `Event "Time`,
+	} {
+		assert.Equal(t, column, partitionSourceColumn(partitioning))
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// defaultMaxPollInterval is the default max_poll_interval, when only
+// poll_interval is set.
+const defaultMaxPollInterval = time.Second
+
+// pollJitter is the fraction of the interval randomly added or removed,
+// so that the queries of many clients are not polled in lockstep.
+const pollJitter = 0.2
+
+// pollBackoff is the wait between requests for pages of a running query
+// that returned no data. It doubles after each empty page up to max, and
+// is reset once data is returned.
+type pollBackoff struct {
+	min, max time.Duration
+	next     time.Duration
+}
+
+func newPollBackoff(min, max time.Duration) *pollBackoff {
+	if min <= 0 {
+		return nil
+	}
+	if max < min {
+		max = min
+	}
+	return &pollBackoff{min: min, max: max, next: min}
+}
+
+// wait blocks for the current interval, or until the context is done.
+func (b *pollBackoff) wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+	delay := time.Duration(float64(b.next) * (1 - pollJitter + 2*pollJitter*rand.Float64()))
+	b.next *= 2
+	if b.next > b.max {
+		b.next = b.max
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (b *pollBackoff) reset() {
+	if b != nil {
+		b.next = b.min
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPollBackoff(t *testing.T) {
+	b := newPollBackoff(time.Millisecond, 4*time.Millisecond)
+	var intervals []time.Duration
+	for i := 0; i < 4; i++ {
+		intervals = append(intervals, b.next)
+		require.NoError(t, b.wait(context.Background()))
+	}
+	assert.Equal(t, []time.Duration{time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond, 4 * time.Millisecond}, intervals)
+	b.reset()
+	assert.Equal(t, time.Millisecond, b.next)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, newPollBackoff(time.Hour, time.Hour).wait(ctx))
+
+	assert.Nil(t, newPollBackoff(0, time.Second))
+	assert.NoError(t, (*pollBackoff)(nil).wait(ctx))
+}
+
+func TestPollInterval(t *testing.T) {
+	const emptyPages = 4
+	var polls []time.Time
+	var pages []string
+	for i := 0; i < emptyPages; i++ {
+		pages = append(pages, `"stats": {"state": "RUNNING"}`)
+	}
+	pages = append(pages, `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`)
+	ts := newPagedResultTestServer(t, pages, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if testPage(r) > 0 {
+			polls = append(polls, time.Now())
+		}
+		return false
+	})
+
+	dsn, err := (&Config{ServerURI: ts.URL, PollInterval: 20 * time.Millisecond, MaxPollInterval: 40 * time.Millisecond}).FormatDSN()
+	require.NoError(t, err)
+	db, err := sql.Open("trino", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	var v int
+	require.NoError(t, db.QueryRow("SELECT 1").Scan(&v))
+	require.Len(t, polls, emptyPages+1)
+	// 20ms, 40ms, 40ms and 40ms, with 20% jitter
+	assert.True(t, polls[emptyPages].Sub(polls[0]) >= 112*time.Millisecond, "polled too often: %v", polls[emptyPages].Sub(polls[0]))
+
+	_, err = newConn("http://foobar@localhost:8080?poll_interval=soon")
+	assert.EqualError(t, err, `trino: invalid poll_interval: "soon"`)
+}
@@ -0,0 +1,223 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// prefetchedPage is a page fetched ahead of the application.
+type prefetchedPage struct {
+	resp   *queryResponse
+	status int
+	rows   int
+	bytes  int64
+	err    error
+	spill  *spilledPage // position in the spill file, if the data was spilled
+	header http.Header  // applied to the session when the page is returned
+}
+
+// prefetcher fetches pages in the background while the application scans
+// rows, as long as the fetched-but-unscanned data fits in the buffer limits,
+// or writes the pages beyond the limits to a spill file, if set.
+//
+// The connection may run other statements while pages are fetched, so run
+// only sends the session headers and cookies of the query as they were when
+// prefetching started, updating its own copy of the cookies, and the
+// changes sent by Trino with each page are applied by next.
+type prefetcher struct {
+	maxRows  int
+	maxBytes int64
+	spill    *spillFile // written by run, read by next once a page is queued
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	session  http.Header    // sent by run
+	cookies  []*http.Cookie // sent and updated by run
+
+	mu            sync.Mutex
+	cond          *sync.Cond
+	pages         []*prefetchedPage
+	current       *prefetchedPage // the page being scanned
+	bufferedRows  int
+	bufferedBytes int64
+	closed        bool
+	rate          *prefetchRate // set with adaptive_prefetch
+}
+
+func (qr *driverRows) startPrefetch(maxRows int, maxBytes int64, spillDir string) {
+	if qr.nextURI == "" {
+		return
+	}
+	ctx, cancel := context.WithCancel(qr.ctx)
+	p := &prefetcher{
+		maxRows:  maxRows,
+		maxBytes: maxBytes,
+		cancel:   cancel,
+		session:  qr.stmt.conn.httpHeaders.Clone(),
+		cookies:  append([]*http.Cookie(nil), qr.cookies...),
+	}
+	if spillDir != "" {
+		p.spill = &spillFile{dir: spillDir, decode: qr.decodeQueryResponse}
+		if maxRows == 0 && maxBytes == 0 {
+			p.maxBytes = defaultSpillBufferBytes
+		}
+	}
+	if qr.stmt.conn.adaptivePrefetch {
+		p.rate = &prefetchRate{}
+	}
+	p.cond = sync.NewCond(&p.mu)
+	qr.prefetcher = p
+	p.wg.Add(1)
+	go p.run(ctx, qr, qr.nextURI)
+}
+
+func (p *prefetcher) full() bool {
+	return (p.maxRows > 0 && p.bufferedRows >= p.maxRows) ||
+		(p.maxBytes > 0 && p.bufferedBytes >= p.maxBytes)
+}
+
+func (p *prefetcher) run(ctx context.Context, qr *driverRows, uri string) {
+	defer p.wg.Done()
+	columns := qr.columns // known if returned with the submission
+	backoff := newPollBackoff(qr.stmt.conn.pollInterval, qr.stmt.conn.maxPollInterval)
+	for uri != "" {
+		p.mu.Lock()
+		// always allow a page to be fetched when none is buffered, and
+		// do not spill pages fetched ahead of what the application needs
+		for !p.closed && len(p.pages) > 0 && (p.full() && p.spill == nil || p.rate.ahead(len(p.pages))) {
+			p.cond.Wait()
+		}
+		closed := p.closed
+		spill := len(p.pages) > 0 && p.full()
+		p.mu.Unlock()
+		if closed {
+			return
+		}
+
+		var page *prefetchedPage
+		started := time.Now()
+		if spill {
+			page = p.spill.fetchPage(ctx, qr, uri, columns, p.session, p.cookies)
+		} else {
+			resp, h, status, size, err := qr.requestPage(ctx, uri, columns, p.session, p.cookies, nil)
+			page = &prefetchedPage{resp: resp, status: status, bytes: size, err: err, header: h}
+			if resp != nil {
+				page.rows = len(resp.Data)
+			}
+		}
+		if page.header != nil {
+			p.cookies = mergeCookies(p.cookies, responseCookies(page.header))
+		}
+		p.mu.Lock()
+		p.rate.observeFetch(time.Since(started))
+		p.pages = append(p.pages, page)
+		if page.spill == nil {
+			p.bufferedRows += page.rows
+			p.bufferedBytes += page.bytes
+		}
+		p.cond.Broadcast()
+		p.mu.Unlock()
+		resp := page.resp
+		if page.err != nil || resp.Error.ErrorName != "" {
+			return
+		}
+		if len(resp.Columns) > 0 && columns == nil {
+			columns = make([]string, len(resp.Columns))
+			for i, col := range resp.Columns {
+				columns[i] = col.Name
+			}
+		}
+		uri = resp.NextURI
+		if page.rows > 0 {
+			backoff.reset()
+		} else if uri != "" {
+			if err := backoff.wait(ctx); err != nil {
+				p.mu.Lock()
+				p.pages = append(p.pages, &prefetchedPage{err: err})
+				p.cond.Broadcast()
+				p.mu.Unlock()
+				return
+			}
+		}
+	}
+}
+
+// next returns the next page of qr, waiting for it to be fetched if needed,
+// after applying the headers of its response to the session. The page
+// previously returned is considered scanned, and released from the buffer.
+func (p *prefetcher) next(qr *driverRows) (*queryResponse, int, int64, error) {
+	p.mu.Lock()
+	p.rate.observeNext(time.Now())
+	if p.current != nil {
+		if p.current.spill == nil {
+			p.bufferedRows -= p.current.rows
+			p.bufferedBytes -= p.current.bytes
+		}
+		p.current = nil
+		p.cond.Broadcast()
+	}
+	for len(p.pages) == 0 {
+		p.cond.Wait()
+	}
+	page := p.pages[0]
+	p.rate.returned(time.Now())
+	p.current = page
+	p.pages[0] = nil
+	p.pages = p.pages[1:]
+	p.mu.Unlock()
+	if page.header != nil {
+		if err := qr.applyPageHeader(page.header); err != nil {
+			return nil, 0, 0, err
+		}
+	}
+	if page.spill != nil && page.err == nil {
+		if err := p.spill.load(page); err != nil {
+			return nil, 0, 0, err
+		}
+	}
+	return page.resp, page.status, page.bytes, page.err
+}
+
+// close stops fetching pages and waits for the pending request to finish.
+func (p *prefetcher) close() {
+	p.mu.Lock()
+	p.closed = true
+	p.cond.Broadcast()
+	p.mu.Unlock()
+	p.cancel()
+	p.wg.Wait()
+	if p.spill != nil {
+		p.spill.remove()
+	}
+}
+
+// discarded returns the number and size of the pages fetched but never
+// returned by next, once closed.
+func (p *prefetcher) discarded() (int, int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var n int
+	var size int64
+	for _, page := range p.pages {
+		if page.resp != nil {
+			n++
+			size += page.bytes
+		}
+	}
+	return n, size
+}
@@ -0,0 +1,169 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// integerPages returns the given number of pages of results of two
+// integers each, counting from 2.
+func integerPages(n int) []string {
+	pages := make([]string, n)
+	for i := range pages {
+		page := i + 1
+		pages[i] = fmt.Sprintf(`"columns": [{"name": "n", "type": "integer"}], "data": [[%d], [%d]]`, page*2, page*2+1)
+	}
+	return pages
+}
+
+func TestPrefetchMaxBufferedRows(t *testing.T) {
+	var requested int32
+	ts := newPagedResultTestServer(t, integerPages(5), nil, countPages(&requested))
+
+	db, err := sql.Open("trino", ts.URL+"?max_buffered_rows=3")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	rows, err := db.Query("SELECT 1")
+	require.NoError(t, err)
+	require.True(t, rows.Next())
+
+	// the page being scanned and a prefetched one exceed the limit
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requested))
+
+	var values []int
+	for {
+		var n int
+		require.NoError(t, rows.Scan(&n))
+		values = append(values, n)
+		if !rows.Next() {
+			break
+		}
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []int{2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, values)
+	assert.Equal(t, int32(5), atomic.LoadInt32(&requested))
+}
+
+func TestPrefetchClose(t *testing.T) {
+	var requested int32
+	ts := newPagedResultTestServer(t, integerPages(100), nil, countPages(&requested))
+
+	db, err := sql.Open("trino", ts.URL+"?max_buffered_bytes=1")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	rows, err := db.Query("SELECT 1")
+	require.NoError(t, err)
+	require.True(t, rows.Next())
+	require.NoError(t, rows.Close())
+	assert.Less(t, atomic.LoadInt32(&requested), int32(100))
+}
+
+func TestPrefetchSessionChanges(t *testing.T) {
+	const pages = 20
+	var catalogs []string
+	var mu sync.Mutex
+	data := make([]string, pages)
+	for i := range data {
+		data[i] = fmt.Sprintf(`"columns": [{"name": "n", "type": "integer"}], "data": [[%d]]`, i+1)
+	}
+	ts := newPagedResultTestServer(t, data, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path == "/v1/statement" {
+			body, _ := ioutil.ReadAll(r.Body)
+			mu.Lock()
+			catalogs = append(catalogs, r.Header.Get(trinoCatalogHeader))
+			mu.Unlock()
+			w.Header().Set(trinoSetCatalogHeader, "other")
+			if string(body) != "SELECT n FROM t" {
+				json.NewEncoder(w).Encode(&stmtResponse{ID: "20210101_000000_00000_other"})
+				return true
+			}
+			return false
+		}
+		page := testPage(r)
+		if page > 1 {
+			// cookies set by earlier pages are sent with the following ones
+			if cookie, err := r.Cookie("page"); err != nil || cookie.Value != strconv.Itoa(page-1) {
+				w.WriteHeader(http.StatusBadRequest)
+				return true
+			}
+		}
+		if page > 0 {
+			http.SetCookie(w, &http.Cookie{Name: "page", Value: strconv.Itoa(page)})
+			w.Header().Set(trinoSetCatalogHeader, "page"+strconv.Itoa(page))
+		}
+		return false
+	})
+
+	db, err := sql.Open("trino", ts.URL+"?max_buffered_rows=100")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, conn.Close())
+	})
+
+	// run other statements on the connection while pages are prefetched,
+	// with the race detector checking that both do not update the session
+	// concurrently
+	rows, err := conn.QueryContext(ctx, "SELECT n FROM t")
+	require.NoError(t, err)
+	var want []string
+	for rows.Next() {
+		var n int
+		require.NoError(t, rows.Scan(&n))
+		// the changes sent with a page are applied when it is scanned
+		want = append(want, "page"+strconv.Itoa(n))
+		_, err := conn.ExecContext(ctx, "SET SESSION a = 1")
+		require.NoError(t, err)
+	}
+	require.NoError(t, rows.Err())
+	require.NoError(t, rows.Close())
+	assert.Len(t, want, pages)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, want, catalogs[1:])
+}
+
+func TestInvalidMaxBufferedRows(t *testing.T) {
+	_, err := sql.Open("trino", "http://localhost:9?max_buffered_rows=-1")
+	require.Error(t, err)
+}
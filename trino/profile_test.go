@@ -0,0 +1,77 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile(t *testing.T) {
+	var headers http.Header
+	ts := newHeaderTestServer(t, `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`, &headers)
+	t.Cleanup(func() {
+		DeregisterProfile("test_profile")
+	})
+
+	// resolution is lazy: the profile does not need to exist when opening
+	db, err := sql.Open("trino", "trino://profile/test_profile")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+	db.SetMaxIdleConns(0)
+
+	var n int
+	err = db.QueryRow("SELECT 1").Scan(&n)
+	assert.EqualError(t, err, `trino: profile not registered: "test_profile"`)
+
+	require.NoError(t, RegisterProfile("test_profile", &Config{
+		ServerURI: strings.Replace(ts.URL, "http://", "http://alice@", 1),
+		Catalog:   "hive",
+	}))
+	require.NoError(t, db.QueryRow("SELECT 1").Scan(&n))
+	assert.Equal(t, "alice", headers.Get(trinoUserHeader))
+	assert.Equal(t, "hive", headers.Get(trinoCatalogHeader))
+
+	// connections opened after registering the profile again use it
+	require.NoError(t, RegisterProfile("test_profile", &Config{
+		ServerURI: strings.Replace(ts.URL, "http://", "http://bob@", 1),
+		Catalog:   "iceberg",
+		ExtraHeaders: http.Header{
+			"X-Test": []string{"profile"},
+		},
+	}))
+	require.NoError(t, db.QueryRow("SELECT 1").Scan(&n))
+	assert.Equal(t, "bob", headers.Get(trinoUserHeader))
+	assert.Equal(t, "iceberg", headers.Get(trinoCatalogHeader))
+	assert.Equal(t, "profile", headers.Get("X-Test"))
+}
+
+func TestRegisterProfileInvalid(t *testing.T) {
+	assert.Error(t, RegisterProfile("", &Config{ServerURI: "http://localhost:8080"}))
+	assert.Error(t, RegisterProfile("a/b", &Config{ServerURI: "http://localhost:8080"}))
+	assert.EqualError(t, RegisterProfile("invalid", &Config{
+		ServerURI:          "http://localhost:8080",
+		ClientCapabilities: []string{"FOO"},
+	}), `trino: invalid client_capabilities: "FOO"`)
+	_, err := getProfile("invalid")
+	assert.Error(t, err)
+}
@@ -0,0 +1,80 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"time"
+)
+
+// QueryProgress is the progress of a running query, as reported by Trino
+// with each page of results.
+type QueryProgress struct {
+	QueryID         string
+	State           string // e.g. "QUEUED", "RUNNING" or "FINISHED"
+	TotalSplits     int
+	CompletedSplits int
+	ProcessedRows   int64
+	ProcessedBytes  int64
+	CPUTime         time.Duration
+	WallTime        time.Duration
+}
+
+// Fraction returns the fraction of the splits of the query that completed,
+// from 0 to 1. It is 0 until the query is scheduled, and can decrease when
+// the query schedules more splits.
+func (p QueryProgress) Fraction() float64 {
+	if p.TotalSplits == 0 {
+		return 0
+	}
+	return float64(p.CompletedSplits) / float64(p.TotalSplits)
+}
+
+// QueryProgressHandler receives the progress of a query.
+type QueryProgressHandler func(progress QueryProgress)
+
+type queryProgressHandlerKey struct{}
+
+// WithQueryProgress returns a context that makes queries executed with it
+// pass their progress to fn after each page of results, including the
+// empty pages returned while they are queued or running, which is useful
+// to follow statements that return no rows, such as INSERT.
+func WithQueryProgress(ctx context.Context, fn QueryProgressHandler) context.Context {
+	return context.WithValue(ctx, queryProgressHandlerKey{}, fn)
+}
+
+func queryProgressHandlerFromContext(ctx context.Context) QueryProgressHandler {
+	fn, _ := ctx.Value(queryProgressHandlerKey{}).(QueryProgressHandler)
+	return fn
+}
+
+// reportProgress passes the progress of the last page read to the handler
+// of the context, if any.
+func (qr *driverRows) reportProgress() {
+	fn := queryProgressHandlerFromContext(qr.ctx)
+	if fn == nil {
+		return
+	}
+	fn(QueryProgress{
+		QueryID:         qr.queryID,
+		State:           qr.stats.State,
+		TotalSplits:     qr.stats.TotalSplits,
+		CompletedSplits: qr.stats.CompletedSplits,
+		ProcessedRows:   int64(qr.stats.ProcessedRows),
+		ProcessedBytes:  int64(qr.stats.ProcessedBytes),
+		CPUTime:         time.Duration(qr.stats.CPUTimeMillis) * time.Millisecond,
+		WallTime:        time.Duration(qr.stats.WallTimeMillis) * time.Millisecond,
+	})
+}
@@ -0,0 +1,254 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryInfo holds the aggregate statistics of a completed query, as
+// reported by the coordinator once the last page has been fetched.
+type QueryInfo struct {
+	QueryID              string
+	State                string
+	ElapsedTime          time.Duration
+	QueuedTime           time.Duration
+	TotalCPUTime         time.Duration
+	PeakUserMemoryBytes  int64
+	PeakTotalMemoryBytes int64
+	SpilledBytes         int64
+	PhysicalInputBytes   int64
+	ProcessedInputRows   int64
+	OutputBytes          int64
+	OutputRows           int64
+	Stages               []StageInfo // Flattened, starting from the output stage
+}
+
+// StageInfo summarizes the statistics of a single query stage.
+type StageInfo struct {
+	StageID             string
+	State               string
+	TotalCPUTime        time.Duration
+	PeakUserMemoryBytes int64
+	SpilledBytes        int64
+	InputRows           int64
+	OutputRows          int64
+}
+
+// QueryInfoHandler receives the final query info of a completed query,
+// or the error that prevented retrieving it.
+type QueryInfoHandler func(info *QueryInfo, err error)
+
+type queryInfoHandlerKey struct{}
+
+// WithQueryInfo returns a context that makes queries executed with it
+// retrieve their final query info after the last page, and pass it to fn.
+//
+// Retrieving the query info requires an additional request to the
+// coordinator, and failing to do so does not fail the query.
+func WithQueryInfo(ctx context.Context, fn QueryInfoHandler) context.Context {
+	return context.WithValue(ctx, queryInfoHandlerKey{}, fn)
+}
+
+func queryInfoHandlerFromContext(ctx context.Context) QueryInfoHandler {
+	fn, _ := ctx.Value(queryInfoHandlerKey{}).(QueryInfoHandler)
+	return fn
+}
+
+type queryInfoResponse struct {
+	QueryID     string              `json:"queryId"`
+	State       string              `json:"state"`
+	QueryStats  queryInfoStats      `json:"queryStats"`
+	OutputStage *queryInfoStageInfo `json:"outputStage"`
+}
+
+type queryInfoStats struct {
+	ElapsedTime                airliftDuration `json:"elapsedTime"`
+	QueuedTime                 airliftDuration `json:"queuedTime"`
+	TotalCPUTime               airliftDuration `json:"totalCpuTime"`
+	PeakUserMemoryReservation  airliftDataSize `json:"peakUserMemoryReservation"`
+	PeakTotalMemoryReservation airliftDataSize `json:"peakTotalMemoryReservation"`
+	SpilledDataSize            airliftDataSize `json:"spilledDataSize"`
+	PhysicalInputDataSize      airliftDataSize `json:"physicalInputDataSize"`
+	ProcessedInputPositions    int64           `json:"processedInputPositions"`
+	OutputDataSize             airliftDataSize `json:"outputDataSize"`
+	OutputPositions            int64           `json:"outputPositions"`
+}
+
+type queryInfoStageInfo struct {
+	StageID    string               `json:"stageId"`
+	State      string               `json:"state"`
+	StageStats queryInfoStageStats  `json:"stageStats"`
+	SubStages  []queryInfoStageInfo `json:"subStages"`
+}
+
+type queryInfoStageStats struct {
+	TotalCPUTime              airliftDuration `json:"totalCpuTime"`
+	PeakUserMemoryReservation airliftDataSize `json:"peakUserMemoryReservation"`
+	SpilledDataSize           airliftDataSize `json:"spilledDataSize"`
+	RawInputPositions         int64           `json:"rawInputPositions"`
+	OutputPositions           int64           `json:"outputPositions"`
+}
+
+func (r *queryInfoResponse) queryInfo() *QueryInfo {
+	info := &QueryInfo{
+		QueryID:              r.QueryID,
+		State:                r.State,
+		ElapsedTime:          time.Duration(r.QueryStats.ElapsedTime),
+		QueuedTime:           time.Duration(r.QueryStats.QueuedTime),
+		TotalCPUTime:         time.Duration(r.QueryStats.TotalCPUTime),
+		PeakUserMemoryBytes:  int64(r.QueryStats.PeakUserMemoryReservation),
+		PeakTotalMemoryBytes: int64(r.QueryStats.PeakTotalMemoryReservation),
+		SpilledBytes:         int64(r.QueryStats.SpilledDataSize),
+		PhysicalInputBytes:   int64(r.QueryStats.PhysicalInputDataSize),
+		ProcessedInputRows:   r.QueryStats.ProcessedInputPositions,
+		OutputBytes:          int64(r.QueryStats.OutputDataSize),
+		OutputRows:           r.QueryStats.OutputPositions,
+	}
+	var walk func(s *queryInfoStageInfo)
+	walk = func(s *queryInfoStageInfo) {
+		info.Stages = append(info.Stages, StageInfo{
+			StageID:             s.StageID,
+			State:               s.State,
+			TotalCPUTime:        time.Duration(s.StageStats.TotalCPUTime),
+			PeakUserMemoryBytes: int64(s.StageStats.PeakUserMemoryReservation),
+			SpilledBytes:        int64(s.StageStats.SpilledDataSize),
+			InputRows:           s.StageStats.RawInputPositions,
+			OutputRows:          s.StageStats.OutputPositions,
+		})
+		for i := range s.SubStages {
+			walk(&s.SubStages[i])
+		}
+	}
+	if r.OutputStage != nil {
+		walk(r.OutputStage)
+	}
+	return info
+}
+
+// fetchQueryInfo retrieves the final query info, if requested by the context.
+func (qr *driverRows) fetchQueryInfo() {
+	fn := queryInfoHandlerFromContext(qr.ctx)
+	if fn == nil || qr.queryID == "" {
+		return
+	}
+	fn(qr.stmt.conn.queryInfo(qr.ctx, qr.queryID, qr.stmt.user))
+}
+
+func (c *Conn) queryInfo(ctx context.Context, queryID, user string) (*QueryInfo, error) {
+	hs := make(http.Header)
+	if user != "" {
+		hs.Add(trinoUserHeader, user)
+	}
+	req, err := c.newRequest("GET", c.baseURL+"/v1/query/"+url.PathEscape(queryID), nil, hs)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.roundTrip(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var r queryInfoResponse
+	if err := c.jsonDecoder.Decode(resp.Body, &r); err != nil {
+		return nil, fmt.Errorf("trino: %v", err)
+	}
+	return r.queryInfo(), nil
+}
+
+// airliftDuration decodes durations such as "1.50ms" or "2.00d".
+type airliftDuration time.Duration
+
+func (d *airliftDuration) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("trino: cannot convert %s to duration", b)
+	}
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.ParseFloat(strings.TrimSuffix(s, "d"), 64)
+		if err != nil {
+			return fmt.Errorf("trino: cannot convert %q to duration", s)
+		}
+		*d = airliftDuration(days * float64(24*time.Hour))
+		return nil
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("trino: cannot convert %q to duration", s)
+	}
+	*d = airliftDuration(v)
+	return nil
+}
+
+// airliftDataSize decodes data sizes such as "1024B" or "1.50MB" into bytes.
+type airliftDataSize int64
+
+var dataSizeUnits = []struct {
+	suffix string
+	bytes  float64
+}{
+	// longer suffixes first, as all of them end with "B"
+	{"kB", 1 << 10},
+	{"MB", 1 << 20},
+	{"GB", 1 << 30},
+	{"TB", 1 << 40},
+	{"PB", 1 << 50},
+	{"B", 1},
+}
+
+func (s *airliftDataSize) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	var n int64
+	if err := json.Unmarshal(b, &n); err == nil {
+		*s = airliftDataSize(n)
+		return nil
+	}
+	var v string
+	if err := json.Unmarshal(b, &v); err != nil {
+		return fmt.Errorf("trino: cannot convert %s to data size", b)
+	}
+	size, err := parseDataSize(v)
+	if err != nil {
+		return err
+	}
+	*s = airliftDataSize(size)
+	return nil
+}
+
+func parseDataSize(v string) (int64, error) {
+	for _, unit := range dataSizeUnits {
+		if strings.HasSuffix(v, unit.suffix) {
+			f, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(v, unit.suffix)), 64)
+			if err != nil {
+				break
+			}
+			return int64(f * unit.bytes), nil
+		}
+	}
+	return 0, fmt.Errorf("trino: cannot convert %q to data size", v)
+}
@@ -0,0 +1,83 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryInfo(t *testing.T) {
+	result := `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`
+	ts := newPagedResultTestServer(t, []string{result}, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method != "GET" || r.URL.Path != "/v1/query/"+testQueryID {
+			return false
+		}
+		w.Write([]byte(`{
+			"queryId": "20210101_000000_00000_abcde",
+			"state": "FINISHED",
+			"queryStats": {
+				"elapsedTime": "1.50s",
+				"totalCpuTime": "2.00d",
+				"peakUserMemoryReservation": "1.50kB",
+				"spilledDataSize": "2048B",
+				"outputPositions": 1
+			},
+			"outputStage": {
+				"stageId": "20210101_000000_00000_abcde.0",
+				"state": "FINISHED",
+				"stageStats": {"totalCpuTime": "10.00ms", "outputPositions": 1},
+				"subStages": [{"stageId": "20210101_000000_00000_abcde.1", "state": "FINISHED"}]
+			}
+		}`))
+		return true
+	})
+
+	db, err := sql.Open("trino", ts.URL)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	var info *QueryInfo
+	ctx := WithQueryInfo(context.Background(), func(qi *QueryInfo, err error) {
+		require.NoError(t, err)
+		info = qi
+	})
+	rows, err := db.QueryContext(ctx, "SELECT 1")
+	require.NoError(t, err)
+	for rows.Next() {
+	}
+	require.NoError(t, rows.Err())
+	require.NoError(t, rows.Close())
+
+	require.NotNil(t, info, "query info was not retrieved")
+	assert.Equal(t, "FINISHED", info.State)
+	assert.Equal(t, 1500*time.Millisecond, info.ElapsedTime)
+	assert.Equal(t, 48*time.Hour, info.TotalCPUTime)
+	assert.Equal(t, int64(1536), info.PeakUserMemoryBytes)
+	assert.Equal(t, int64(2048), info.SpilledBytes)
+	assert.Equal(t, int64(1), info.OutputRows)
+	require.Len(t, info.Stages, 2)
+	assert.Equal(t, 10*time.Millisecond, info.Stages[0].TotalCPUTime)
+	assert.Equal(t, "20210101_000000_00000_abcde.1", info.Stages[1].StageID)
+}
@@ -0,0 +1,76 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newRateLimiter(10, 2)
+	l.now = func() time.Time { return now }
+
+	assert.Equal(t, time.Duration(0), l.reserve())
+	assert.Equal(t, time.Duration(0), l.reserve())
+	assert.Equal(t, 100*time.Millisecond, l.reserve())
+
+	now = now.Add(time.Second)
+	assert.Equal(t, time.Duration(0), l.reserve())
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	l := newRateLimiter(0.001, 1)
+	require.NoError(t, l.wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, l.wait(ctx))
+}
+
+func TestRateLimitedConnector(t *testing.T) {
+	ts := newResultTestServer(t, `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`, nil)
+
+	connector, err := NewConnector(&Config{ServerURI: ts.URL, MaxRequestsPerSec: 50, RequestBurst: 1})
+	require.NoError(t, err)
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	// two requests per query, the first one being free
+	start := time.Now()
+	var v int
+	for i := 0; i < 2; i++ {
+		require.NoError(t, db.QueryRow("SELECT 1").Scan(&v))
+	}
+	assert.True(t, time.Since(start) >= 3*20*time.Millisecond, "requests were not limited")
+}
+
+func TestInvalidRateLimit(t *testing.T) {
+	for _, dsn := range []string{
+		"http://localhost:8080?max_requests_per_second=0",
+		"http://localhost:8080?max_requests_per_second=1&request_burst=0",
+	} {
+		_, err := newConn(dsn)
+		assert.Error(t, err, dsn)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+type rawValuesKey struct{}
+
+// WithRawValues returns a context that makes queries executed with it
+// return the JSON representation of values as sent by Trino, without any
+// conversion, for the given columns, or for all columns if none is given.
+//
+// Raw values can be scanned into a RawValue, json.RawMessage, []byte or
+// string. NULL values are returned as nil.
+func WithRawValues(ctx context.Context, columns ...string) context.Context {
+	return context.WithValue(ctx, rawValuesKey{}, columns)
+}
+
+func rawValuesFromContext(ctx context.Context) ([]string, bool) {
+	columns, ok := ctx.Value(rawValuesKey{}).([]string)
+	return columns, ok
+}
+
+// RawValue holds the JSON representation of a value, as sent by Trino.
+type RawValue []byte
+
+// Scan implements the sql.Scanner interface.
+//
+// Values of columns that were not requested as raw with WithRawValues are
+// encoded back to JSON, which may differ from the representation sent by Trino.
+func (v *RawValue) Scan(value interface{}) error {
+	switch x := value.(type) {
+	case nil:
+		*v = nil
+	case []byte:
+		*v = append((*v)[:0], x...)
+	default:
+		b, err := json.Marshal(x)
+		if err != nil {
+			return fmt.Errorf("trino: cannot convert %v (%T) to RawValue: %v", value, value, err)
+		}
+		*v = b
+	}
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v RawValue) MarshalJSON() ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+	return v, nil
+}
+
+var jsonNull = []byte("null")
+
+func rawDriverValue(raw json.RawMessage) driver.Value {
+	if bytes.Equal(raw, jsonNull) {
+		return nil
+	}
+	return []byte(raw)
+}
@@ -0,0 +1,87 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rawValuesTestResult is a row whose raw values would not survive decoding
+// unchanged.
+const rawValuesTestResult = `
+	"columns": [
+		{"name": "d", "type": "double"},
+		{"name": "m", "type": "map(varchar, integer)"},
+		{"name": "n", "type": "integer"}
+	],
+	"data": [[1.10, {"a": 1}, null]]`
+
+func TestRawValues(t *testing.T) {
+	ts := newResultTestServer(t, rawValuesTestResult, nil)
+
+	db, err := sql.Open("trino", ts.URL)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	ctx := WithRawValues(context.Background())
+	var d, m, n RawValue
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT 1").Scan(&d, &m, &n))
+	assert.Equal(t, `1.10`, string(d))
+	assert.Equal(t, `{"a": 1}`, string(m))
+	assert.Nil(t, n)
+}
+
+func TestRawValuesColumns(t *testing.T) {
+	ts := newResultTestServer(t, rawValuesTestResult, nil)
+
+	db, err := sql.Open("trino", ts.URL)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	ctx := WithRawValues(context.Background(), "d")
+	var d string
+	var m NullMap
+	var n sql.NullInt64
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT 1").Scan(&d, &m, &n))
+	assert.Equal(t, `1.10`, d)
+	assert.Equal(t, map[string]interface{}{"a": json.Number("1")}, m.Map)
+	assert.False(t, n.Valid)
+}
+
+func TestRawValueScan(t *testing.T) {
+	var v RawValue
+	require.NoError(t, v.Scan([]byte(`"foo"`)))
+	assert.Equal(t, `"foo"`, string(v))
+
+	require.NoError(t, v.Scan(int64(1)))
+	assert.Equal(t, `1`, string(v))
+
+	require.NoError(t, v.Scan(nil))
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	assert.Equal(t, `null`, string(b))
+}
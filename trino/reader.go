@@ -0,0 +1,159 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// columnReader converts the values of a column of a common scalar type,
+// without the generic conversions of typeConverter, which try the layouts
+// of all temporal types in turn and box values in sql.Null types. It
+// returns false for the values it does not handle, such as NULL or
+// malformed ones, which are then converted by convertColumn, so that
+// errors and lenient conversions are unchanged.
+//
+// Readers only replace the conversion of the values: rows are still decoded
+// into a queryData each, and their values returned as driver.Value, so the
+// allocations of decoding and boxing the values are the same.
+type columnReader func(v interface{}) (driver.Value, bool)
+
+// readColumn converts the value of the column at index i with its reader,
+// returning false if it has none or it does not handle the value.
+func (qr *driverRows) readColumn(i int, v interface{}) (driver.Value, bool) {
+	if qr.readers == nil || qr.readers[i] == nil {
+		return nil, false
+	}
+	return qr.readers[i](v)
+}
+
+// reader returns the reader of the column, or nil if its values must go
+// through the generic conversions.
+func (c *typeConverter) reader() columnReader {
+	switch c.parsedType[0] {
+	case "boolean":
+		return readBool
+	case "char", "varchar":
+		return readString
+	case "tinyint", "smallint", "integer", "bigint":
+		if c.strictNumbers {
+			return nil
+		}
+		return readInt64
+	case "real", "double":
+		return readFloat64
+	case "timestamp":
+		if strings.Contains(strings.ToLower(c.typeName), "time zone") {
+			return nil
+		}
+		loc := c.location
+		return func(v interface{}) (driver.Value, bool) {
+			s, ok := v.(string)
+			if !ok {
+				return nil, false
+			}
+			return readTimestamp(s, loc)
+		}
+	default:
+		return nil
+	}
+}
+
+func readBool(v interface{}) (driver.Value, bool) {
+	b, ok := v.(bool)
+	return b, ok
+}
+
+func readString(v interface{}) (driver.Value, bool) {
+	s, ok := v.(string)
+	return s, ok
+}
+
+func readInt64(v interface{}) (driver.Value, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return nil, false
+	}
+	i, err := strconv.ParseInt(string(n), 10, 64)
+	return i, err == nil
+}
+
+func readFloat64(v interface{}) (driver.Value, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return nil, false
+	}
+	f, err := strconv.ParseFloat(string(n), 64)
+	return f, err == nil
+}
+
+// readTimestamp parses timestamps without a time zone, such as
+// 2021-01-02 03:04:05.123456, like parseNullTime, truncating picoseconds
+// to nanoseconds.
+func readTimestamp(s string, loc *time.Location) (time.Time, bool) {
+	if len(s) < len("2006-01-02 15:04:05") || s[4] != '-' || s[7] != '-' || s[10] != ' ' || s[13] != ':' || s[16] != ':' {
+		return time.Time{}, false
+	}
+	year, ok1 := readDigits(s[0:4])
+	month, ok2 := readDigits(s[5:7])
+	day, ok3 := readDigits(s[8:10])
+	hour, ok4 := readDigits(s[11:13])
+	min, ok5 := readDigits(s[14:16])
+	sec, ok6 := readDigits(s[17:19])
+	if !ok1 || !ok2 || !ok3 || !ok4 || !ok5 || !ok6 || month < 1 || month > 12 || day < 1 || hour > 23 || min > 59 || sec > 59 {
+		return time.Time{}, false
+	}
+	nsec := 0
+	if frac := s[19:]; frac != "" {
+		if frac[0] != '.' || len(frac) == 1 {
+			return time.Time{}, false
+		}
+		digits := frac[1:]
+		if _, ok := readDigits(digits); !ok {
+			return time.Time{}, false
+		}
+		for i := 0; i < 9; i++ {
+			nsec *= 10
+			if i < len(digits) {
+				nsec += int(digits[i] - '0')
+			}
+		}
+	}
+	t := time.Date(year, time.Month(month), day, hour, min, sec, nsec, loc)
+	if t.Day() != day {
+		// e.g. February 30th, rejected by time.Parse
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// readDigits parses a non-empty string of decimal digits.
+func readDigits(s string) (int, bool) {
+	if s == "" {
+		return 0, false
+	}
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, false
+		}
+		n = n*10 + int(s[i]-'0')
+	}
+	return n, true
+}
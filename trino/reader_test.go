@@ -0,0 +1,113 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypedReadersMatchConversions(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	for _, tt := range []struct {
+		typeName string
+		values   []interface{}
+	}{
+		{"boolean", []interface{}{true, false, nil, json.Number("1")}},
+		{"varchar", []interface{}{"", "abc", nil, json.Number("1")}},
+		{"char(3)", []interface{}{"abc"}},
+		{"bigint", []interface{}{json.Number("0"), json.Number("-9223372036854775808"), json.Number("9223372036854775807"), json.Number("9223372036854775808"), json.Number("1.5"), float64(3), nil}},
+		{"integer", []interface{}{json.Number("42")}},
+		{"double", []interface{}{json.Number("1.5"), json.Number("-1e300"), "NaN", "Infinity", float64(2), nil}},
+		{"timestamp(3)", []interface{}{
+			"2021-01-02 03:04:05",
+			"2021-01-02 03:04:05.1",
+			"2021-01-02 03:04:05.123456789",
+			"2021-01-02 03:04:05.123456789012",
+			"2020-02-29 23:59:59.999",
+			"2021-03-28 02:30:00",
+			"2021-02-30 00:00:00",
+			"2021-13-01 00:00:00",
+			"2021-01-02 24:00:00",
+			"2021-01-02 03:04:05.",
+			"2021-01-02T03:04:05",
+			"2021-01-02",
+			nil,
+		}},
+		{"timestamp(3) with time zone", []interface{}{"2021-01-02 03:04:05.123 UTC", "2021-01-02 03:04:05.123+01:00"}},
+	} {
+		c := newTypeConverter(tt.typeName)
+		c.location = berlin
+		r := c.reader()
+		for _, v := range tt.values {
+			want, wantErr := c.ConvertValue(v)
+			if r == nil {
+				continue
+			}
+			got, ok := r(v)
+			if !ok {
+				continue
+			}
+			require.NoError(t, wantErr, "%s %v", tt.typeName, v)
+			if wt, isTime := want.(time.Time); isTime {
+				assert.True(t, wt.Equal(got.(time.Time)), "%s %v: got %v, want %v", tt.typeName, v, got, want)
+				assert.Equal(t, wt.Location(), got.(time.Time).Location())
+				continue
+			}
+			assert.Equal(t, want, got, "%s %v", tt.typeName, v)
+		}
+	}
+}
+
+func TestTypedReadersFallBack(t *testing.T) {
+	assert.Nil(t, newTypeConverter("timestamp(3) with time zone").reader())
+	assert.Nil(t, newTypeConverter("array(bigint)").reader())
+	c := newTypeConverter("bigint")
+	c.strictNumbers = true
+	assert.Nil(t, c.reader(), "strict_numbers checks the values of the generic conversion")
+
+	_, ok := readTimestamp("2021-02-30 00:00:00", time.UTC)
+	assert.False(t, ok)
+	_, ok = readInt64(json.Number("9223372036854775808"))
+	assert.False(t, ok)
+}
+
+func TestDisableTypedReaders(t *testing.T) {
+	c := &Config{ServerURI: "http://foobar@localhost:8080", DisableTypedReaders: true}
+	dsn, err := c.FormatDSN()
+	require.NoError(t, err)
+	assert.Equal(t, "http://foobar@localhost:8080?disable_typed_readers=true&source=trino-go-client", dsn)
+
+	for _, disabled := range []string{"true", "false"} {
+		ts := newResultTestServer(t, `
+	"columns": [{"name": "ts", "type": "timestamp(3)"}],
+	"data": [["2021-01-02 03:04:05.123"]]`, nil)
+		db, err := sql.Open("trino", ts.URL+"?location=UTC&disable_typed_readers="+disabled)
+		require.NoError(t, err)
+		var got time.Time
+		require.NoError(t, db.QueryRow("SELECT ts").Scan(&got))
+		assert.Equal(t, time.Date(2021, 1, 2, 3, 4, 5, 123000000, time.UTC), got)
+		require.NoError(t, db.Close())
+	}
+
+	_, err = newConn("http://foobar@localhost:8080?disable_typed_readers=maybe")
+	assert.EqualError(t, err, `trino: invalid disable_typed_readers: "maybe"`)
+}
@@ -0,0 +1,107 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ErrReadOnly is returned, in read_only mode, when executing a statement
+// other than a query, SHOW, DESCRIBE or EXPLAIN. The statement is not sent
+// to Trino.
+type ErrReadOnly struct {
+	Statement string // First keyword of the statement, e.g. INSERT
+}
+
+// Error implements the error interface.
+func (e *ErrReadOnly) Error() string {
+	return fmt.Sprintf("trino: %s statements are not allowed in read_only mode", e.Statement)
+}
+
+// readOnlyStatements are the statements allowed in read_only mode, by their
+// first keyword.
+var readOnlyStatements = map[string]bool{
+	"SELECT":   true,
+	"WITH":     true,
+	"VALUES":   true,
+	"TABLE":    true,
+	"SHOW":     true,
+	"DESCRIBE": true,
+	"DESC":     true,
+	"EXPLAIN":  true,
+}
+
+// checkReadOnly returns an *ErrReadOnly if the statement is not allowed in
+// read_only mode. EXPLAIN ANALYZE runs the explained statement, so that
+// statement must be allowed too.
+func checkReadOnly(query string) error {
+	keyword, rest := nextKeyword(query)
+	if !readOnlyStatements[keyword] {
+		if keyword == "" {
+			keyword = "empty"
+		}
+		return &ErrReadOnly{Statement: keyword}
+	}
+	if keyword != "EXPLAIN" {
+		return nil
+	}
+	keyword, rest = nextKeyword(rest)
+	if keyword != "ANALYZE" {
+		return nil
+	}
+	if keyword, next := nextKeyword(rest); keyword == "VERBOSE" {
+		rest = next
+	}
+	return checkReadOnly(rest)
+}
+
+// nextKeyword returns the upper-cased first word of s, skipping blanks,
+// comments and opening parentheses, and the remainder of s.
+func nextKeyword(s string) (string, string) {
+	s = skipBlanks(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '_'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	return strings.ToUpper(s[:i]), s[i:]
+}
+
+func skipBlanks(s string) string {
+	for {
+		s = strings.TrimLeftFunc(s, func(r rune) bool {
+			return unicode.IsSpace(r) || r == '('
+		})
+		switch {
+		case strings.HasPrefix(s, "--"):
+			i := strings.IndexByte(s, '\n')
+			if i < 0 {
+				return ""
+			}
+			s = s[i+1:]
+		case strings.HasPrefix(s, "/*"):
+			i := strings.Index(s[2:], "*/")
+			if i < 0 {
+				return ""
+			}
+			s = s[i+4:]
+		default:
+			return s
+		}
+	}
+}
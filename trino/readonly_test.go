@@ -0,0 +1,93 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckReadOnly(t *testing.T) {
+	for query, rejected := range map[string]string{
+		"SELECT 1":                                 "",
+		"  select * from t":                        "",
+		"(SELECT 1) UNION (SELECT 2)":              "",
+		"WITH x AS (SELECT 1) SELECT * FROM x":     "",
+		"VALUES 1, 2":                              "",
+		"TABLE t":                                  "",
+		"SHOW TABLES":                              "",
+		"DESCRIBE t":                               "",
+		"DESC t":                                   "",
+		"EXPLAIN INSERT INTO t VALUES 1":           "",
+		"EXPLAIN (TYPE IO) DELETE FROM t":          "",
+		"EXPLAIN ANALYZE SELECT 1":                 "",
+		"EXPLAIN ANALYZE VERBOSE SELECT 1":         "",
+		"-- comment\nSELECT 1":                     "",
+		"/* INSERT */ SELECT 1":                    "",
+		"INSERT INTO t VALUES 1":                   "INSERT",
+		"delete from t":                            "DELETE",
+		"/* SELECT */ DROP TABLE t":                "DROP",
+		"-- SELECT\nCREATE TABLE t AS SELECT 1":    "CREATE",
+		"EXPLAIN ANALYZE INSERT INTO t VALUES 1":   "INSERT",
+		"EXPLAIN ANALYZE VERBOSE UPDATE t SET a=1": "UPDATE",
+		"CALL system.sync_partition_metadata()":    "CALL",
+		"EXECUTE stmt":                             "EXECUTE",
+		"SET SESSION a = 1":                        "SET",
+		"/* unterminated":                          "empty",
+		"":                                         "empty",
+	} {
+		err := checkReadOnly(query)
+		if rejected == "" {
+			assert.NoError(t, err, query)
+			continue
+		}
+		var roErr *ErrReadOnly
+		if assert.True(t, errors.As(err, &roErr), "%q: unexpected error: %v", query, err) {
+			assert.Equal(t, rejected, roErr.Statement, query)
+		}
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`, &queries)
+	db, err := sql.Open("trino", ts.URL+"?read_only=true")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	_, err = db.Exec("INSERT INTO t VALUES (?)", 1)
+	assert.EqualError(t, err, "trino: INSERT statements are not allowed in read_only mode")
+	assert.Empty(t, queries)
+
+	var n int
+	require.NoError(t, db.QueryRow("SELECT ?", 1).Scan(&n))
+	assert.Equal(t, []string{"EXECUTE _trino_go USING 1"}, queries)
+}
+
+func TestReadOnlyDSN(t *testing.T) {
+	c := &Config{ServerURI: "http://foobar@localhost:8080", ReadOnly: true}
+	dsn, err := c.FormatDSN()
+	require.NoError(t, err)
+	assert.Equal(t, "http://foobar@localhost:8080?read_only=true&source=trino-go-client", dsn)
+
+	_, err = sql.Open("trino", "http://foobar@localhost:8080?read_only=maybe")
+	assert.EqualError(t, err, `trino: invalid read_only: "maybe"`)
+}
@@ -0,0 +1,37 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"errors"
+	"net"
+)
+
+// isDialError returns whether a request failed to connect to Trino, in
+// which case it was not sent, and can be retried without the risk of
+// running a statement twice. Requests that failed after being sent, even
+// partially, are never retried, as Trino may have started to run them.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// markUnsent marks the failure to submit a statement as safe to retry on
+// another connection, if the statement was not sent.
+func markUnsent(err error) {
+	if qerr, ok := err.(*ErrQueryFailed); ok && isDialError(qerr.Reason) {
+		qerr.unsent = true
+	}
+}
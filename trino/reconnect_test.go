@@ -0,0 +1,136 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRestartableTestServer returns a server listening on addr, answering
+// queries with the given catalog set, and recording their query and catalog.
+func newRestartableTestServer(t *testing.T, addr, setCatalog string, queries *[]string) *httptest.Server {
+	l, err := net.Listen("tcp", addr)
+	require.NoError(t, err)
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/statement" {
+			b, _ := ioutil.ReadAll(r.Body)
+			*queries = append(*queries, string(b)+" catalog="+r.Header.Get(trinoCatalogHeader))
+		}
+		if setCatalog != "" {
+			w.Header().Set(trinoSetCatalogHeader, setCatalog)
+		}
+		servePages(w, r, "http://"+r.Host, []string{`"stats": {"state": "FINISHED"}`}, nil)
+	}))
+	ts.Listener.Close()
+	ts.Listener = l
+	ts.Start()
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestReconnect(t *testing.T) {
+	var queries []string
+	ts := newRestartableTestServer(t, "127.0.0.1:0", "tpch", &queries)
+	addr := ts.Listener.Addr().String()
+
+	db, err := sql.Open("trino", "http://foobar@"+addr+"?reconnect_timeout=5s")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+	conn, err := db.Conn(context.Background())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.ExecContext(context.Background(), "USE tpch.tiny")
+	require.NoError(t, err)
+
+	// restart the server while the next statement is retried
+	ts.Close()
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		newRestartableTestServer(t, addr, "", &queries)
+	}()
+	_, err = conn.ExecContext(context.Background(), "SELECT 1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"USE tpch.tiny catalog=", "SELECT 1 catalog=tpch"}, queries)
+}
+
+func TestReconnectTimeout(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	l.Close()
+
+	db, err := sql.Open("trino", "http://foobar@"+addr+"?reconnect_timeout=200ms")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+	started := time.Now()
+	_, err = db.Exec("SELECT 1")
+	assert.True(t, isDialError(err), "unexpected error: %v", err)
+	assert.True(t, time.Since(started) >= 200*time.Millisecond)
+}
+
+func TestErrBadConn(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	ts.Close()
+	db, err := sql.Open("trino", ts.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+	// the statement was never sent, so database/sql can retry it
+	_, err = db.Query("SELECT 1")
+	assert.True(t, errors.Is(err, driver.ErrBadConn), "unexpected error: %v", err)
+}
+
+func TestErrBadConnAfterSubmission(t *testing.T) {
+	var submissions int32
+	ts := newPagedResultTestServer(t, nil, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path == "/v1/statement" {
+			atomic.AddInt32(&submissions, 1)
+			return false
+		}
+		if testPage(r) == 0 {
+			return false
+		}
+		// drop the connection while fetching results
+		conn, _, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		conn.Close()
+		return true
+	})
+	db := openTestDB(t, ts)
+
+	_, err := db.Exec("INSERT INTO t VALUES (1)")
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, driver.ErrBadConn), "unexpected error: %v", err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&submissions))
+}
@@ -0,0 +1,82 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// redactedValue replaces secrets in DSNs, URLs and errors.
+const redactedValue = "xxxxx"
+
+// sensitiveParameters lists the DSN parameters whose values are secrets.
+var sensitiveParameters = []string{
+	"extra_credentials",
+}
+
+var (
+	dsnPasswordPattern  = regexp.MustCompile(`(//[^/:@?#]*):[^/@?#]*@`)
+	dsnParameterPattern = regexp.MustCompile(`([?&](?:` + joinPatterns(sensitiveParameters) + `)=)[^&#]*`)
+)
+
+func joinPatterns(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+	return strings.Join(quoted, "|")
+}
+
+// redactDSN masks the password and the values of sensitive parameters in
+// a DSN or URL. It does not require the input to be a valid URL, so it is
+// safe to use on the malformed DSNs reported in errors.
+func redactDSN(dsn string) string {
+	dsn = dsnPasswordPattern.ReplaceAllString(dsn, "${1}:"+redactedValue+"@")
+	return dsnParameterPattern.ReplaceAllString(dsn, "${1}"+redactedValue)
+}
+
+// redactError masks secrets in the URL reported by url errors.
+func redactError(err error) error {
+	if uerr, ok := err.(*url.Error); ok {
+		return &url.Error{Op: uerr.Op, URL: redactDSN(uerr.URL), Err: uerr.Err}
+	}
+	return err
+}
+
+// FormatRedactedDSN returns a DSN string from the configuration, like
+// FormatDSN, but with the password and extra credentials masked so that it
+// can be logged safely. The returned DSN is not usable to connect to Trino.
+func (c *Config) FormatRedactedDSN() (string, error) {
+	redacted := *c
+	serverURL, err := url.Parse(c.ServerURI)
+	if err != nil {
+		return "", redactError(err)
+	}
+	if serverURL.User != nil {
+		if _, ok := serverURL.User.Password(); ok {
+			serverURL.User = url.UserPassword(serverURL.User.Username(), redactedValue)
+			redacted.ServerURI = serverURL.String()
+		}
+	}
+	if c.ExtraCredentials != nil {
+		redacted.ExtraCredentials = make(map[string]string, len(c.ExtraCredentials))
+		for k := range c.ExtraCredentials {
+			redacted.ExtraCredentials[k] = redactedValue
+		}
+	}
+	return redacted.FormatDSN()
+}
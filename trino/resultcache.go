@@ -0,0 +1,284 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultResultCacheMaxRows is the max number of rows of the results stored
+// in the ResultCache, unless Config.ResultCacheMaxRows is set.
+const DefaultResultCacheMaxRows = 10000
+
+// ResultCache stores the results of queries, to serve repeated identical
+// queries, e.g. the aggregations of dashboards, without running them again.
+//
+// Results are stored once fully read, by a key derived from the statement,
+// its arguments and the headers of the session, such as the user, catalog,
+// schema and session properties. Only queries, i.e. SELECT, WITH, VALUES
+// and TABLE statements, are cached, except the ones reading the tables of
+// system.runtime, and the queries sent by the driver itself.
+//
+// Implementations must be safe for concurrent use.
+type ResultCache interface {
+	Get(key string) (*CachedResult, bool)
+	Set(key string, result *CachedResult)
+}
+
+// CachedResult is the result of a query stored in a ResultCache.
+//
+// Values are the ones decoded from the responses of Trino, e.g. json.Number
+// for numbers, and must not be modified. Stores serializing results should
+// decode numbers as json.Number.
+type CachedResult struct {
+	QueryID string         // ID of the query the result was read from
+	Columns []CachedColumn // Columns of the result
+	Rows    [][]interface{}
+}
+
+// CachedColumn is a column of a CachedResult.
+type CachedColumn struct {
+	Name string
+	Type string // Type of the column, e.g. bigint or array(varchar)
+}
+
+// ResultCacheStats holds the counters of a MemoryResultCache.
+type ResultCacheStats struct {
+	Hits      int64 // Number of queries served from the cache
+	Misses    int64 // Number of lookups of results not in the cache, or expired
+	Evictions int64 // Number of results removed to make room for new ones
+	Entries   int   // Number of results in the cache
+}
+
+// MemoryResultCache is a ResultCache keeping the most recently used results
+// in memory, for a limited time.
+type MemoryResultCache struct {
+	maxEntries int
+	ttl        time.Duration
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // of *resultCacheEntry, most recently used first
+	stats   ResultCacheStats
+}
+
+type resultCacheEntry struct {
+	key     string
+	result  *CachedResult
+	expires time.Time
+}
+
+// NewMemoryResultCache returns a MemoryResultCache holding up to maxEntries
+// results, each for ttl.
+func NewMemoryResultCache(maxEntries int, ttl time.Duration) *MemoryResultCache {
+	return &MemoryResultCache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		now:        time.Now,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// Get implements the ResultCache interface.
+func (c *MemoryResultCache) Get(key string) (*CachedResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	entry := e.Value.(*resultCacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.remove(e)
+		c.stats.Misses++
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	c.stats.Hits++
+	return entry.result, true
+}
+
+// Set implements the ResultCache interface.
+func (c *MemoryResultCache) Set(key string, result *CachedResult) {
+	if c.maxEntries <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+	c.entries[key] = c.lru.PushFront(&resultCacheEntry{
+		key:     key,
+		result:  result,
+		expires: c.now().Add(c.ttl),
+	})
+	for c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+func (c *MemoryResultCache) remove(e *list.Element) {
+	c.lru.Remove(e)
+	delete(c.entries, e.Value.(*resultCacheEntry).key)
+}
+
+// Stats returns the counters of the cache.
+func (c *MemoryResultCache) Stats() ResultCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	return stats
+}
+
+// cacheable returns whether the results of the statement may be stored in
+// the ResultCache: only the queries of the application are, except the ones
+// reading the tables of system.runtime, whose contents change over time.
+func (st *driverStmt) cacheable() bool {
+	return !st.internal && isQuery(st.query) && !readsRuntimeTables(st.query)
+}
+
+// readsRuntimeTables returns whether the query references a table of the
+// system.runtime schema, such as system.runtime.queries, at any depth.
+func readsRuntimeTables(query string) bool {
+	var tokens []string // identifiers, lowercased, and punctuation
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '"':
+			end := skipQuoted(query, i, c)
+			tokens = append(tokens, strings.ToLower(strings.Trim(query[i:end], `"`)))
+			i = end
+		case c == '\'':
+			tokens = append(tokens, "'")
+			i = skipQuoted(query, i, c)
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				i = len(query)
+				continue
+			}
+			i += end + 1
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				i = len(query)
+				continue
+			}
+			i += end + 4
+		case isWordByte(c):
+			start := i
+			for i < len(query) && isWordByte(query[i]) {
+				i++
+			}
+			tokens = append(tokens, strings.ToLower(query[start:i]))
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		default:
+			tokens = append(tokens, query[i:i+1])
+			i++
+		}
+	}
+	for i := 0; i+3 < len(tokens); i++ {
+		if tokens[i] == "system" && tokens[i+1] == "." && tokens[i+2] == "runtime" && tokens[i+3] == "." {
+			return true
+		}
+	}
+	return false
+}
+
+// resultCacheKey returns the key of the results of the query sent with the
+// headers. The Authorization header is left out, as SPNEGO tokens differ on
+// every request, while the user is identified by its own header.
+func (c *Conn) resultCacheKey(query string, h http.Header) string {
+	names := make([]string, 0, len(h))
+	for k := range h {
+		if k != "Authorization" {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	hash := sha256.New()
+	io.WriteString(hash, c.baseURL)
+	io.WriteString(hash, "\x00"+query)
+	for _, k := range names {
+		for _, v := range h[k] {
+			io.WriteString(hash, "\x00"+k+": "+v)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// initCached initializes the rows with a result served from the cache.
+func (qr *driverRows) initCached(result *CachedResult) {
+	qresp := &queryResponse{Columns: make([]queryColumn, len(result.Columns))}
+	for i, col := range result.Columns {
+		qresp.Columns[i] = queryColumn{Name: col.Name, Type: col.Type}
+	}
+	qr.initColumns(qresp)
+	qr.data = make([]queryData, len(result.Rows))
+	for i, row := range result.Rows {
+		qr.data[i] = row
+	}
+	qr.cached = true
+	qr.completed = true
+}
+
+// cachePage keeps the rows of a page, to store the result in the cache once
+// fully read. Results larger than the limit are not stored.
+func (qr *driverRows) cachePage(data []queryData) {
+	if qr.cacheKey == "" {
+		return
+	}
+	if int64(len(qr.cacheRows)+len(data)) > qr.stmt.conn.resultCacheMaxRows {
+		qr.cacheKey = ""
+		qr.cacheRows = nil
+		return
+	}
+	for _, row := range data {
+		qr.cacheRows = append(qr.cacheRows, row)
+	}
+}
+
+// storeResult stores the fully read result in the cache.
+func (qr *driverRows) storeResult() {
+	if qr.cacheKey == "" || qr.columns == nil || qr.failure != nil {
+		return
+	}
+	result := &CachedResult{
+		QueryID: qr.queryID,
+		Columns: make([]CachedColumn, len(qr.columns)),
+		Rows:    qr.cacheRows,
+	}
+	for i, name := range qr.columns {
+		result.Columns[i] = CachedColumn{Name: name, Type: qr.coltype[i].typeName}
+	}
+	qr.stmt.conn.resultCache.Set(qr.cacheKey, result)
+	qr.cacheKey = ""
+	qr.cacheRows = nil
+}
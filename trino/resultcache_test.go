@@ -0,0 +1,189 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultCache(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"columns": [{"name": "n", "type": "integer"}], "data": [[1], [2]]`, &queries)
+	cache := NewMemoryResultCache(10, time.Minute)
+	type tenantKey struct{}
+	connector, err := NewConnector(&Config{
+		ServerURI:   ts.URL,
+		ResultCache: cache,
+		ExtraHeadersFunc: func(ctx context.Context) (http.Header, error) {
+			hs := make(http.Header)
+			if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+				hs.Set("X-Tenant-Id", tenant)
+			}
+			return hs, nil
+		},
+	})
+	require.NoError(t, err)
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	readAll := func(ctx context.Context, query string, args ...interface{}) []int {
+		rows, err := db.QueryContext(ctx, query, args...)
+		require.NoError(t, err)
+		defer rows.Close()
+		var ns []int
+		for rows.Next() {
+			var n int
+			require.NoError(t, rows.Scan(&n))
+			ns = append(ns, n)
+		}
+		require.NoError(t, rows.Err())
+		return ns
+	}
+
+	ctx := context.Background()
+	assert.Equal(t, []int{1, 2}, readAll(ctx, "SELECT n FROM t"))
+	assert.Equal(t, []int{1, 2}, readAll(ctx, "SELECT n FROM t"))
+	assert.Len(t, queries, 1)
+
+	// arguments and headers are part of the key
+	readAll(ctx, "SELECT n FROM t WHERE n > ?", 0)
+	readAll(ctx, "SELECT n FROM t WHERE n > ?", 1)
+	readAll(context.WithValue(ctx, tenantKey{}, "acme"), "SELECT n FROM t")
+	assert.Len(t, queries, 4)
+
+	// results not fully read are not stored
+	rows, err := db.Query("SELECT m FROM t")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+	readAll(ctx, "SELECT m FROM t")
+	assert.Len(t, queries, 6)
+
+	// other statements are not cached
+	_, err = db.Exec("SHOW TABLES")
+	require.NoError(t, err)
+	_, err = db.Exec("SHOW TABLES")
+	require.NoError(t, err)
+	assert.Len(t, queries, 8)
+
+	stats := cache.Stats()
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(6), stats.Misses)
+	assert.Equal(t, 5, stats.Entries)
+}
+
+func TestResultCacheMaxRows(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"columns": [{"name": "n", "type": "integer"}], "data": [[1], [2]]`, &queries)
+	cache := NewMemoryResultCache(10, time.Minute)
+	connector, err := NewConnector(&Config{
+		ServerURI:          ts.URL,
+		ResultCache:        cache,
+		ResultCacheMaxRows: 1,
+	})
+	require.NoError(t, err)
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	for i := 0; i < 2; i++ {
+		rows, err := db.Query("SELECT n FROM t")
+		require.NoError(t, err)
+		for rows.Next() {
+		}
+		require.NoError(t, rows.Err())
+		require.NoError(t, rows.Close())
+	}
+	assert.Len(t, queries, 2)
+	assert.Equal(t, 0, cache.Stats().Entries)
+}
+
+func TestResultCacheRuntimeTables(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"columns": [{"name": "n", "type": "integer"}], "data": [[1]]`, &queries)
+	cache := NewMemoryResultCache(10, time.Minute)
+	connector, err := NewConnector(&Config{
+		ServerURI:   ts.URL,
+		ResultCache: cache,
+	})
+	require.NoError(t, err)
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	for i := 0; i < 2; i++ {
+		rows, err := db.Query("SELECT count(*) FROM system.runtime.queries")
+		require.NoError(t, err)
+		for rows.Next() {
+		}
+		require.NoError(t, rows.Err())
+		require.NoError(t, rows.Close())
+	}
+	assert.Len(t, queries, 2)
+	assert.Equal(t, 0, cache.Stats().Entries)
+}
+
+func TestReadsRuntimeTables(t *testing.T) {
+	for query, want := range map[string]bool{
+		"SELECT * FROM system.runtime.queries":                                true,
+		"select * from SYSTEM . Runtime . nodes":                              true,
+		`SELECT * FROM "system"."runtime"."tasks"`:                            true,
+		"SELECT * FROM t WHERE id IN (SELECT id FROM system.runtime.queries)": true,
+		"SELECT * FROM system.metadata.catalogs":                              false,
+		"SELECT 'system.runtime.queries'":                                     false,
+		"SELECT * FROM t -- system.runtime.queries":                           false,
+		"SELECT * FROM mysystem.runtime.queries":                              false,
+	} {
+		assert.Equal(t, want, readsRuntimeTables(query), query)
+	}
+}
+
+func TestMemoryResultCache(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewMemoryResultCache(2, time.Minute)
+	cache.now = func() time.Time {
+		return now
+	}
+	a, b, c := &CachedResult{QueryID: "a"}, &CachedResult{QueryID: "b"}, &CachedResult{QueryID: "c"}
+	cache.Set("a", a)
+	cache.Set("b", b)
+	_, ok := cache.Get("a")
+	require.True(t, ok)
+	// b is the least recently used
+	cache.Set("c", c)
+	_, ok = cache.Get("b")
+	assert.False(t, ok)
+
+	now = now.Add(30 * time.Second)
+	result, ok := cache.Get("c")
+	require.True(t, ok)
+	assert.Equal(t, c, result)
+
+	now = now.Add(30 * time.Second)
+	_, ok = cache.Get("a")
+	assert.False(t, ok)
+
+	assert.Equal(t, ResultCacheStats{Hits: 2, Misses: 2, Evictions: 1, Entries: 1}, cache.Stats())
+}
@@ -0,0 +1,61 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// defaultIdempotentRetries is the default idempotent_retries.
+const defaultIdempotentRetries = 3
+
+// isIdempotent returns whether the request can be sent again without
+// side effects: requests for the pages of a query, which Trino returns
+// again for the same nextUri, and the cancellation of queries, unlike
+// the submission of statements.
+func isIdempotent(req *http.Request) bool {
+	return req.Method == "GET" || req.Method == "DELETE"
+}
+
+// isTransientError returns whether a request failed because its
+// connection was closed or reset, or its TLS handshake failed, as happens
+// during rolling restarts of the proxies in front of Trino.
+func isTransientError(err error) bool {
+	if isDialError(err) {
+		return true
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var recordErr tls.RecordHeaderError
+	if errors.As(err, &recordErr) {
+		return true
+	}
+	// TLS alerts sent by the server, e.g. during its shutdown
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "remote error"
+}
+
+// retryIdempotent returns whether the request, which failed with err after
+// the given number of retries, should be retried.
+func (c *Conn) retryIdempotent(req *http.Request, err error, retries int) bool {
+	return retries < c.idempotentRetries && isIdempotent(req) && isTransientError(err)
+}
@@ -0,0 +1,157 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"crypto/tls"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFlakyTestServer returns a server dropping the connection of the first
+// drops requests for the page of a query, and of the first drops
+// submissions if dropPost is set.
+func newFlakyTestServer(t *testing.T, drops int32, dropPost bool, gets, posts *int32) *httptest.Server {
+	result := `"columns": [{"name": "n", "type": "bigint"}], "data": [[1]]`
+	return newPagedResultTestServer(t, []string{result}, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method == "DELETE" {
+			return false
+		}
+		var n int32
+		if r.URL.Path == "/v1/statement" {
+			n = atomic.AddInt32(posts, 1)
+			if !dropPost {
+				n = drops + 1
+			}
+		} else {
+			n = atomic.AddInt32(gets, 1)
+		}
+		if n > drops {
+			return false
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		conn.Close()
+		return true
+	})
+}
+
+// openFlakyTestDB opens a database on ts without keep-alives, so that
+// net/http does not retry requests on reused connections by itself.
+func openFlakyTestDB(t *testing.T, ts *httptest.Server, params string) *sql.DB {
+	require.NoError(t, RegisterCustomClient("flaky", &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}))
+	t.Cleanup(func() {
+		DeregisterCustomClient("flaky")
+	})
+	db, err := sql.Open("trino", ts.URL+"?custom_client=flaky"+params)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+	return db
+}
+
+func TestIdempotentRetries(t *testing.T) {
+	var gets, posts int32
+	ts := newFlakyTestServer(t, 2, false, &gets, &posts)
+	db := openFlakyTestDB(t, ts, "")
+
+	var n int64
+	require.NoError(t, db.QueryRow("SELECT 1").Scan(&n))
+	assert.Equal(t, int64(1), n)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&gets), "the page is requested again after dropped connections")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&posts))
+}
+
+func TestIdempotentRetriesExhausted(t *testing.T) {
+	for _, tc := range []struct {
+		dsn  string
+		gets int32
+	}{
+		{"&idempotent_retries=1", 2},
+		{"&idempotent_retries=0", 1},
+	} {
+		var gets, posts int32
+		ts := newFlakyTestServer(t, 5, false, &gets, &posts)
+		db := openFlakyTestDB(t, ts, tc.dsn)
+
+		var n int64
+		err := db.QueryRow("SELECT 1").Scan(&n)
+		require.Error(t, err, tc.dsn)
+		assert.Equal(t, tc.gets, atomic.LoadInt32(&gets), tc.dsn)
+	}
+}
+
+func TestSubmissionsNotRetried(t *testing.T) {
+	var gets, posts int32
+	ts := newFlakyTestServer(t, 1, true, &gets, &posts)
+	db := openFlakyTestDB(t, ts, "&idempotent_retries=5")
+
+	_, err := db.Exec("INSERT INTO t VALUES (1)")
+	require.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&posts))
+}
+
+func TestIsTransientError(t *testing.T) {
+	for _, err := range []error{
+		&url.Error{Op: "Get", URL: "http://localhost", Err: io.EOF},
+		&url.Error{Op: "Get", URL: "http://localhost", Err: &net.OpError{Op: "read", Err: syscall.ECONNRESET}},
+		&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED},
+		&net.OpError{Op: "remote error", Err: errors.New("tls: internal error")},
+		fmt.Errorf("handshake: %w", tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}),
+	} {
+		assert.True(t, isTransientError(err), "%v", err)
+	}
+	for _, err := range []error{
+		errors.New("x509: certificate signed by unknown authority"),
+		&url.Error{Op: "Get", URL: "http://localhost", Err: errors.New("net/http: timeout awaiting response headers")},
+	} {
+		assert.False(t, isTransientError(err), "%v", err)
+	}
+}
+
+func TestIdempotentRetriesConfig(t *testing.T) {
+	for _, tc := range []struct {
+		retries int
+		want    string
+	}{
+		{5, "http://foobar@localhost:8080?idempotent_retries=5&source=trino-go-client"},
+		{-1, "http://foobar@localhost:8080?idempotent_retries=0&source=trino-go-client"},
+		{0, "http://foobar@localhost:8080?source=trino-go-client"},
+	} {
+		dsn, err := (&Config{ServerURI: "http://foobar@localhost:8080", IdempotentRetries: tc.retries}).FormatDSN()
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, dsn)
+	}
+
+	conn, err := newConn("http://localhost:8080")
+	require.NoError(t, err)
+	assert.Equal(t, defaultIdempotentRetries, conn.idempotentRetries)
+
+	_, err = newConn("http://localhost:8080?idempotent_retries=-1")
+	assert.EqualError(t, err, `trino: invalid idempotent_retries: "-1"`)
+}
@@ -0,0 +1,60 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// QueryRewriter rewrites each statement executed by the connections of a
+// pool before it is sent to Trino, e.g. to add a comment with the ID of
+// the trace of the context, to expand templates or to add a LIMIT clause.
+// A non-nil error rejects the statement, and is returned as is by the
+// driver.
+//
+// The statement is the one passed to database/sql, before the comment
+// holding the SQLCommentTags and the limit added by auto_limit are added,
+// and before its arguments are bound. Statements run
+// internally by the driver, e.g. to look up idempotency keys, are not
+// rewritten.
+type QueryRewriter func(ctx context.Context, query string) (string, error)
+
+// NextURIRewriter rewrites the nextUri returned by Trino before it is
+// requested, e.g. to replace the internal address of a coordinator behind
+// a proxy with the address of the proxy.
+type NextURIRewriter func(nextURI string) (string, error)
+
+// rewriteNextURI applies force_original_host, then the NextURIRewriter.
+func (c *Conn) rewriteNextURI(uri string) (string, error) {
+	if c.forceOriginalHost {
+		base, err := url.Parse(c.baseURL)
+		if err != nil {
+			return "", fmt.Errorf("trino: malformed server URI: %v", err)
+		}
+		next, err := url.Parse(uri)
+		if err != nil {
+			return "", fmt.Errorf("trino: malformed nextUri: %v", err)
+		}
+		next.Scheme = base.Scheme
+		next.Host = base.Host
+		uri = next.String()
+	}
+	if c.nextURIRewriter != nil {
+		return c.nextURIRewriter(uri)
+	}
+	return uri, nil
+}
@@ -0,0 +1,127 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newInternalNextURITestServer returns a server whose nextUri points to an
+// unreachable internal address.
+func newInternalNextURITestServer(t *testing.T) *httptest.Server {
+	result := `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`
+	return newPagedResultTestServer(t, []string{result}, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path != "/v1/statement" {
+			return false
+		}
+		json.NewEncoder(w).Encode(&stmtResponse{
+			ID:      testQueryID,
+			NextURI: testPageURI("http://coordinator.internal:8080", 1),
+		})
+		return true
+	})
+}
+
+func TestForceOriginalHost(t *testing.T) {
+	ts := newInternalNextURITestServer(t)
+
+	dsn, err := (&Config{ServerURI: ts.URL, ForceOriginalHost: true}).FormatDSN()
+	require.NoError(t, err)
+	assert.Contains(t, dsn, "force_original_host=true")
+
+	db, err := sql.Open("trino", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	var v int
+	require.NoError(t, db.QueryRow("SELECT 1").Scan(&v))
+	assert.Equal(t, 1, v)
+}
+
+func TestNextURIRewriter(t *testing.T) {
+	ts := newInternalNextURITestServer(t)
+
+	var rewritten []string
+	connector, err := NewConnector(&Config{
+		ServerURI: ts.URL,
+		NextURIRewriter: func(nextURI string) (string, error) {
+			rewritten = append(rewritten, nextURI)
+			return strings.Replace(nextURI, "http://coordinator.internal:8080", ts.URL, 1), nil
+		},
+	})
+	require.NoError(t, err)
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	var v int
+	require.NoError(t, db.QueryRow("SELECT 1").Scan(&v))
+	assert.Equal(t, []string{"http://coordinator.internal:8080/v1/statement/20210101_000000_00000_abcde/1"}, rewritten)
+}
+
+type traceIDKey struct{}
+
+func TestQueryRewriter(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`, &queries)
+	errTemplate := errors.New("unknown template")
+	var filtered []string
+	connector, err := NewConnector(&Config{
+		ServerURI: ts.URL,
+		AutoLimit: 10,
+		QueryRewriter: func(ctx context.Context, query string) (string, error) {
+			if strings.Contains(query, "{{") {
+				return "", errTemplate
+			}
+			if id, ok := ctx.Value(traceIDKey{}).(string); ok {
+				query = "/* trace_id=" + id + " */ " + query
+			}
+			return query, nil
+		},
+		StatementFilter: func(query string) error {
+			filtered = append(filtered, query)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	ctx := context.WithValue(context.Background(), traceIDKey{}, "abc")
+	var n int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT 1").Scan(&n))
+	require.NoError(t, db.QueryRow("SELECT 2").Scan(&n))
+	assert.Equal(t, []string{"/* trace_id=abc */ SELECT 1\nLIMIT 10", "SELECT 2\nLIMIT 10"}, queries)
+	assert.Equal(t, queries, filtered, "statements are filtered once rewritten")
+
+	err = db.QueryRow("SELECT {{columns}}").Scan(&n)
+	assert.True(t, errors.Is(err, errTemplate), "unexpected error: %v", err)
+	assert.Len(t, queries, 2)
+}
@@ -0,0 +1,197 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// convertRows converts the values of rows, which Trino sends as arrays of
+// field values, to maps keyed by field name, recursively. Unnamed fields
+// are named field0, field1, etc.
+func convertRows(v interface{}, t *typeSpec) (interface{}, error) {
+	if v == nil || t == nil {
+		return v, nil
+	}
+	switch t.name {
+	case "row":
+		vs, ok := v.([]interface{})
+		if !ok || len(vs) != len(t.args) {
+			return nil, fmt.Errorf("cannot convert %v (%T) to row with %d fields", v, v, len(t.args))
+		}
+		m := make(map[string]interface{}, len(vs))
+		for i := range vs {
+			field, err := convertRows(vs[i], t.args[i])
+			if err != nil {
+				return nil, err
+			}
+			m[t.fieldName(i)] = field
+		}
+		return m, nil
+	case "array":
+		vs, ok := v.([]interface{})
+		if !ok || len(t.args) != 1 {
+			return nil, fmt.Errorf("cannot convert %v (%T) to slice", v, v)
+		}
+		for i := range vs {
+			elem, err := convertRows(vs[i], t.args[0])
+			if err != nil {
+				return nil, err
+			}
+			vs[i] = elem
+		}
+		return vs, nil
+	case "map":
+		m, ok := v.(map[string]interface{})
+		if !ok || len(t.args) != 2 {
+			return nil, fmt.Errorf("cannot convert %v (%T) to map", v, v)
+		}
+		for k := range m {
+			elem, err := convertRows(m[k], t.args[1])
+			if err != nil {
+				return nil, err
+			}
+			m[k] = elem
+		}
+		return m, nil
+	}
+	return v, nil
+}
+
+// ScanStruct returns a scanner decoding a row into dest, a pointer to a
+// struct, or an array of rows into dest, a pointer to a slice of structs.
+//
+// Row fields are assigned to the struct fields with the same name, ignoring
+// case, or named by a `trino:"name"` tag. Fields tagged with `trino:"-"`
+// are skipped. Nested rows, arrays and maps are decoded into nested
+// structs, slices and maps.
+func ScanStruct(dest interface{}) sql.Scanner {
+	return &structScanner{dest: dest}
+}
+
+type structScanner struct {
+	dest interface{}
+}
+
+// Scan implements the sql.Scanner interface.
+func (s *structScanner) Scan(value interface{}) error {
+	ptr := reflect.ValueOf(s.dest)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+		return fmt.Errorf("trino: cannot scan into %T, a pointer is required", s.dest)
+	}
+	return assignValue(value, ptr.Elem())
+}
+
+// assignValue assigns a value decoded by the driver to dst, converting
+// rows to structs and times to time.Time.
+func assignValue(v interface{}, dst reflect.Value) error {
+	if v == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	switch {
+	case dst.Type() == timeType:
+		t, err := scanNullTime(v)
+		if err != nil {
+			return fmt.Errorf("trino: cannot convert %v to %s: %v", v, dst.Type(), err)
+		}
+		dst.Set(reflect.ValueOf(t.Time))
+		return nil
+	case dst.Kind() == reflect.Ptr:
+		elem := reflect.New(dst.Type().Elem())
+		if err := assignValue(v, elem.Elem()); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	case dst.Kind() == reflect.Struct:
+		if m, ok := v.(map[string]interface{}); ok {
+			return assignStruct(m, dst)
+		}
+	case dst.Kind() == reflect.Slice && dst.Type().Elem().Kind() != reflect.Uint8:
+		if vs, ok := v.([]interface{}); ok {
+			slice := reflect.MakeSlice(dst.Type(), len(vs), len(vs))
+			for i := range vs {
+				if err := assignValue(vs[i], slice.Index(i)); err != nil {
+					return err
+				}
+			}
+			dst.Set(slice)
+			return nil
+		}
+	case dst.Kind() == reflect.Map:
+		if m, ok := v.(map[string]interface{}); ok {
+			result := reflect.MakeMapWithSize(dst.Type(), len(m))
+			for k, elem := range m {
+				key, err := parseMapKey(k, dst.Type().Key())
+				if err != nil {
+					return err
+				}
+				value := reflect.New(dst.Type().Elem()).Elem()
+				if err := assignValue(elem, value); err != nil {
+					return err
+				}
+				result.SetMapIndex(key, value)
+			}
+			dst.Set(result)
+			return nil
+		}
+	}
+	// scalars, decoded like JSON values
+	b, err := json.Marshal(v)
+	if err == nil {
+		err = json.Unmarshal(b, dst.Addr().Interface())
+	}
+	if err != nil {
+		return fmt.Errorf("trino: cannot convert %v to %s: %v", v, dst.Type(), err)
+	}
+	return nil
+}
+
+func assignStruct(m map[string]interface{}, dst reflect.Value) error {
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		name := field.Name
+		if tag := field.Tag.Get("trino"); tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		v, ok := m[name]
+		if !ok {
+			for k := range m {
+				if strings.EqualFold(k, name) {
+					v, ok = m[k], true
+					break
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+		if err := assignValue(v, dst.Field(i)); err != nil {
+			return fmt.Errorf("%v (field %s)", err, field.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testOrderLine struct {
+	Item     string `trino:"item_name"`
+	Quantity int64
+	Tags     []string
+	Attrs    map[string]int
+	Shipped  *time.Time
+	Ignored  string `trino:"-"`
+}
+
+type testOrder struct {
+	ID    int64
+	Lines []testOrderLine
+}
+
+func TestScanStruct(t *testing.T) {
+	ts := newResultTestServer(t, `
+		"columns": [
+			{"name": "lines", "type": "array(row(item_name varchar, quantity bigint, tags array(varchar), attrs map(varchar, integer), shipped date))"},
+			{"name": "order", "type": "row(id bigint, lines array(row(item_name varchar, quantity bigint, tags array(varchar), attrs map(varchar, integer), shipped date)))"},
+			{"name": "anonymous", "type": "row(integer, varchar)"}
+		],
+		"data": [[
+			[["apple", 3, ["fruit"], {"weight": 1}, "2021-01-02"], ["pear", 1, [], {}, null]],
+			[42, [["plum", 2, null, null, null]]],
+			[1, "a"]
+		]]`, nil)
+	db := openTestDB(t, ts)
+
+	var lines []testOrderLine
+	var order testOrder
+	var anonymous NullMap
+	require.NoError(t, db.QueryRow("SELECT *").Scan(ScanStruct(&lines), ScanStruct(&order), &anonymous))
+
+	shipped := time.Date(2021, 1, 2, 0, 0, 0, 0, time.Local)
+	assert.Equal(t, []testOrderLine{
+		{Item: "apple", Quantity: 3, Tags: []string{"fruit"}, Attrs: map[string]int{"weight": 1}, Shipped: &shipped},
+		{Item: "pear", Quantity: 1, Tags: []string{}, Attrs: map[string]int{}},
+	}, lines)
+	assert.Equal(t, testOrder{ID: 42, Lines: []testOrderLine{{Item: "plum", Quantity: 2}}}, order)
+	assert.Equal(t, "a", anonymous.Map["field1"])
+}
+
+func TestScanStructErrors(t *testing.T) {
+	var line testOrderLine
+	assert.Error(t, ScanStruct(line).Scan(map[string]interface{}{}), "not a pointer")
+	assert.Error(t, ScanStruct(&line).Scan(map[string]interface{}{"quantity": "many"}))
+
+	_, err := newTypeConverter("row(x integer, y integer)").ConvertValue([]interface{}{1})
+	assert.Error(t, err, "wrong number of fields")
+}
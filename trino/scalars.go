@@ -0,0 +1,169 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// scanInt converts an integer value, as converted by the driver or as
+// decoded from JSON, checking that it fits in the given number of bits.
+func scanInt(value interface{}, bits uint, typeName string) (int64, bool, error) {
+	var v int64
+	switch x := value.(type) {
+	case nil:
+		return 0, false, nil
+	case int64:
+		v = x
+	case json.Number:
+		var err error
+		v, err = x.Int64()
+		if err != nil {
+			return 0, false, fmt.Errorf("trino: cannot convert %v (%T) to %s", value, value, typeName)
+		}
+	default:
+		return 0, false, fmt.Errorf("trino: cannot convert %v (%T) to %s", value, value, typeName)
+	}
+	min, max := int64(-1)<<(bits-1), int64(1)<<(bits-1)-1
+	if v < min || v > max {
+		return 0, false, fmt.Errorf("trino: cannot convert %d to %s: out of range", v, typeName)
+	}
+	return v, true, nil
+}
+
+// NullBool represents a Trino boolean that may be null.
+type NullBool struct {
+	Bool  bool
+	Valid bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (s *NullBool) Scan(value interface{}) error {
+	v, err := scanNullBool(value)
+	if err != nil {
+		return fmt.Errorf("trino: %v", err)
+	}
+	s.Bool, s.Valid = v.Bool, v.Valid
+	return nil
+}
+
+// NullInt8 represents a Trino tinyint that may be null.
+type NullInt8 struct {
+	Int8  int8
+	Valid bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (s *NullInt8) Scan(value interface{}) error {
+	v, valid, err := scanInt(value, 8, "int8")
+	if err != nil {
+		return err
+	}
+	s.Int8, s.Valid = int8(v), valid
+	return nil
+}
+
+// NullInt16 represents a Trino smallint that may be null.
+type NullInt16 struct {
+	Int16 int16
+	Valid bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (s *NullInt16) Scan(value interface{}) error {
+	v, valid, err := scanInt(value, 16, "int16")
+	if err != nil {
+		return err
+	}
+	s.Int16, s.Valid = int16(v), valid
+	return nil
+}
+
+// NullInt32 represents a Trino integer that may be null.
+type NullInt32 struct {
+	Int32 int32
+	Valid bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (s *NullInt32) Scan(value interface{}) error {
+	v, valid, err := scanInt(value, 32, "int32")
+	if err != nil {
+		return err
+	}
+	s.Int32, s.Valid = int32(v), valid
+	return nil
+}
+
+// NullFloat32 represents a Trino real that may be null.
+type NullFloat32 struct {
+	Float32 float32
+	Valid   bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (s *NullFloat32) Scan(value interface{}) error {
+	var v float64
+	switch x := value.(type) {
+	case nil:
+		s.Float32, s.Valid = 0, false
+		return nil
+	case float64:
+		v = x
+	default:
+		vv, err := scanNullFloat64(value)
+		if err != nil {
+			return fmt.Errorf("trino: %v", err)
+		}
+		v = vv.Float64
+	}
+	if math.Abs(v) > math.MaxFloat32 && !math.IsInf(v, 0) {
+		return fmt.Errorf("trino: cannot convert %v to float32: out of range", v)
+	}
+	s.Float32, s.Valid = float32(v), true
+	return nil
+}
+
+// NullBytes represents a Trino varbinary that may be null. Trino sends
+// varbinary values encoded in base64, which Scan decodes.
+type NullBytes struct {
+	Bytes []byte
+	Valid bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (s *NullBytes) Scan(value interface{}) error {
+	var encoded string
+	switch x := value.(type) {
+	case nil:
+		s.Bytes, s.Valid = nil, false
+		return nil
+	case string:
+		encoded = x
+	case []byte:
+		encoded = string(x)
+	default:
+		return fmt.Errorf("trino: cannot convert %v (%T) to []byte", value, value)
+	}
+	b, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("trino: cannot decode varbinary: %v", err)
+	}
+	s.Bytes, s.Valid = b, true
+	return nil
+}
@@ -0,0 +1,155 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const scalarsResult = `
+	"columns": [
+		{"name": "b", "type": "boolean"},
+		{"name": "ti", "type": "tinyint"},
+		{"name": "si", "type": "smallint"},
+		{"name": "i", "type": "integer"},
+		{"name": "bi", "type": "bigint"},
+		{"name": "r", "type": "real"},
+		{"name": "d", "type": "double"},
+		{"name": "c", "type": "char(3)"},
+		{"name": "vb", "type": "varbinary"},
+		{"name": "u", "type": "uuid"}
+	],
+	"data": [
+		[true, -128, 32767, -2147483648, 9223372036854775807, 1.5, 2.5, "ab ", "aGVsbG8=", "12151fd2-7586-11e9-8f9e-2a86e4085a59"],
+		[null, null, null, null, null, null, null, null, null, null]
+	]`
+
+func TestScalarNullables(t *testing.T) {
+	ts := newResultTestServer(t, scalarsResult, nil)
+	db := openTestDB(t, ts)
+
+	rows, err := db.Query("SELECT *")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var (
+		b  NullBool
+		ti NullInt8
+		si NullInt16
+		i  NullInt32
+		bi sql.NullInt64
+		r  NullFloat32
+		d  sql.NullFloat64
+		c  sql.NullString
+		vb NullBytes
+		u  sql.NullString
+	)
+	require.True(t, rows.Next())
+	require.NoError(t, rows.Scan(&b, &ti, &si, &i, &bi, &r, &d, &c, &vb, &u))
+	assert.Equal(t, NullBool{Bool: true, Valid: true}, b)
+	assert.Equal(t, NullInt8{Int8: -128, Valid: true}, ti)
+	assert.Equal(t, NullInt16{Int16: 32767, Valid: true}, si)
+	assert.Equal(t, NullInt32{Int32: -2147483648, Valid: true}, i)
+	assert.Equal(t, sql.NullInt64{Int64: 9223372036854775807, Valid: true}, bi)
+	assert.Equal(t, NullFloat32{Float32: 1.5, Valid: true}, r)
+	assert.Equal(t, sql.NullFloat64{Float64: 2.5, Valid: true}, d)
+	assert.Equal(t, sql.NullString{String: "ab ", Valid: true}, c)
+	assert.Equal(t, NullBytes{Bytes: []byte("hello"), Valid: true}, vb)
+	assert.Equal(t, sql.NullString{String: "12151fd2-7586-11e9-8f9e-2a86e4085a59", Valid: true}, u)
+
+	require.True(t, rows.Next())
+	require.NoError(t, rows.Scan(&b, &ti, &si, &i, &bi, &r, &d, &c, &vb, &u))
+	assert.False(t, b.Valid || ti.Valid || si.Valid || i.Valid || bi.Valid || r.Valid || d.Valid || c.Valid || vb.Valid || u.Valid)
+	require.NoError(t, rows.Err())
+}
+
+func TestScalarNullablesRange(t *testing.T) {
+	var ti NullInt8
+	assert.Error(t, ti.Scan(int64(128)))
+	var si NullInt16
+	assert.Error(t, si.Scan(json.Number("-32769")))
+	var i NullInt32
+	assert.Error(t, i.Scan(int64(1)<<31))
+	require.NoError(t, i.Scan(json.Number("42")))
+	assert.Equal(t, NullInt32{Int32: 42, Valid: true}, i)
+	var r NullFloat32
+	assert.Error(t, r.Scan(1e300))
+	var vb NullBytes
+	assert.Error(t, vb.Scan("not base64!"))
+}
+
+func TestSpecialFloats(t *testing.T) {
+	ts := newResultTestServer(t, `
+		"columns": [
+			{"name": "nan", "type": "double"},
+			{"name": "inf", "type": "double"},
+			{"name": "neginf", "type": "real"},
+			{"name": "negzero", "type": "double"},
+			{"name": "arr", "type": "array(double)"}
+		],
+		"data": [["NaN", "Infinity", "-Infinity", -0.0, ["NaN", 1.0]]]`, nil)
+	db := openTestDB(t, ts)
+
+	var nan, inf, negzero float64
+	var neginf NullFloat32
+	var arr NullSliceFloat64
+	require.NoError(t, db.QueryRow("SELECT *").Scan(&nan, &inf, &neginf, &negzero, &arr))
+	assert.True(t, math.IsNaN(nan))
+	assert.True(t, math.IsInf(inf, 1))
+	assert.True(t, math.IsInf(float64(neginf.Float32), -1))
+	assert.True(t, negzero == 0 && math.Signbit(negzero))
+	require.Len(t, arr.SliceFloat64, 2)
+	assert.True(t, math.IsNaN(arr.SliceFloat64[0].Float64))
+}
+
+// testHyperLogLog is a custom scanner for a type not converted by the driver.
+type testHyperLogLog struct {
+	Encoded string
+}
+
+func (h *testHyperLogLog) Scan(value interface{}) error {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("unexpected %T", value)
+	}
+	h.Encoded = s
+	return nil
+}
+
+func TestUnknownTypePassThrough(t *testing.T) {
+	ts := newResultTestServer(t, `
+		"columns": [
+			{"name": "hll", "type": "HyperLogLog"},
+			{"name": "geom", "type": "Geometry"},
+			{"name": "custom", "type": "my_connector_type"}
+		],
+		"data": [["AgwBAIDsqCA=", "POINT (1 2)", {"a": [1]}]]`, nil)
+	db := openTestDB(t, ts)
+
+	var hll testHyperLogLog
+	var geom string
+	var custom NullMap
+	require.NoError(t, db.QueryRow("SELECT *").Scan(&hll, &geom, &custom))
+	assert.Equal(t, "AgwBAIDsqCA=", hll.Encoded)
+	assert.Equal(t, "POINT (1 2)", geom)
+	assert.Equal(t, map[string]interface{}{"a": []interface{}{json.Number("1")}}, custom.Map)
+}
@@ -15,9 +15,12 @@
 package trino
 
 import (
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -38,10 +41,53 @@ type Numeric string
 // Serial converts any supported value to its equivalent string for as a Trino parameter
 // See https://trino.io/docs/current/language/types.html
 func Serial(v interface{}) (string, error) {
+	return SerialOptions{}.Serial(v)
+}
+
+// SerialOptions configures the formatting of values by Serial, such as
+// query arguments. The zero value formats them like Serial, rejecting
+// floating point and time.Time values, whose literals would be ambiguous.
+type SerialOptions struct {
+	// Floats formats float32 and float64 values as REAL and DOUBLE
+	// literals, with the shortest representation parsed back to the same
+	// value, e.g. DOUBLE '0.1'.
+	Floats bool
+	// Timestamps formats time.Time values as timestamp with time zone
+	// literals, with the offset of their location.
+	Timestamps bool
+	// TimestampPrecision is the number of fractional second digits of
+	// timestamps, from 1 to 12, which sets the precision of their type
+	// (optional, default is 9, the precision of time.Time).
+	TimestampPrecision int
+	// Strict rejects the values Trino could read differently than
+	// intended: unsigned integers above the bigint range, which Trino reads
+	// as decimals, and Numeric values that are not decimal numbers, such as
+	// "NaN" or hexadecimal floats.
+	Strict bool
+
+	// hexStrings formats strings as hexadecimal UTF-8 decoded by Trino,
+	// rather than quoted literals, with safe_parameters.
+	hexStrings bool
+}
+
+// Serial converts a value to its Trino literal, like Serial, with the
+// options.
+func (o SerialOptions) Serial(v interface{}) (string, error) {
 	switch x := v.(type) {
 	case nil:
 		return "", UnsupportedArgError{"<nil>"}
 
+	// custom types, such as decimal wrappers or UUIDs
+	case driver.Valuer:
+		if rv := reflect.ValueOf(x); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return "", UnsupportedArgError{"<nil>"}
+		}
+		vv, err := x.Value()
+		if err != nil {
+			return "", err
+		}
+		return o.Serial(vv)
+
 	// numbers convertible to int
 	case int8:
 		return strconv.Itoa(int(x)), nil
@@ -60,20 +106,36 @@ func Serial(v interface{}) (string, error) {
 	case uint32:
 		return strconv.FormatUint(uint64(x), 10), nil
 	case uint:
-		return strconv.FormatUint(uint64(x), 10), nil
+		return o.serialUint(uint64(x))
 	case uint64:
-		return strconv.FormatUint(x, 10), nil
+		return o.serialUint(x)
 
-		// float32, float64 not supported because digit precision will easily cause large problems
+		// float32, float64 not supported because digit precision will easily cause large problems,
+		// except for the special values which have an exact representation
 	case float32:
+		if s, ok := serialSpecialFloat(float64(x)); ok {
+			return "CAST(" + s + " AS REAL)", nil
+		}
+		if o.Floats {
+			return "REAL " + QuoteLiteral(strconv.FormatFloat(float64(x), 'g', -1, 32)), nil
+		}
 		return "", UnsupportedArgError{"float32"}
 	case float64:
+		if s, ok := serialSpecialFloat(x); ok {
+			return s, nil
+		}
+		if o.Floats {
+			return "DOUBLE " + QuoteLiteral(strconv.FormatFloat(x, 'g', -1, 64)), nil
+		}
 		return "", UnsupportedArgError{"float64"}
 
 	case Numeric:
 		if _, err := strconv.ParseFloat(string(x), 64); err != nil {
 			return "", err
 		}
+		if o.Strict && !numericPattern.MatchString(string(x)) {
+			return "", UnsupportedArgError{fmt.Sprintf("Numeric %q", string(x))}
+		}
 		return string(x), nil
 
 		// note byte and uint are not supported, this is because byte is an alias for uint8
@@ -86,6 +148,9 @@ func Serial(v interface{}) (string, error) {
 		return strconv.FormatBool(x), nil
 
 	case string:
+		if o.hexStrings {
+			return fmt.Sprintf("from_utf8(X'%X')", x), nil
+		}
 		return "'" + strings.Replace(x, "'", "''", -1) + "'", nil
 
 		// TODO - []byte should probably be matched to 'VARBINARY' in trino
@@ -94,6 +159,9 @@ func Serial(v interface{}) (string, error) {
 
 		// time.Time and time.Duration not supported as time and date take several different formats in Trino
 	case time.Time:
+		if o.Timestamps {
+			return o.serialTimestamp(x)
+		}
 		return "", UnsupportedArgError{"time.Time"}
 	case time.Duration:
 		return "", UnsupportedArgError{"time.Duration"}
@@ -115,7 +183,7 @@ func Serial(v interface{}) (string, error) {
 			slice[i] = x.Index(i).Interface()
 		}
 
-		return serialSlice(slice)
+		return o.serialSlice(slice)
 	}
 
 	if reflect.TypeOf(v).Kind() == reflect.Map {
@@ -128,11 +196,11 @@ func Serial(v interface{}) (string, error) {
 	return "", UnsupportedArgError{fmt.Sprintf("%T", v)}
 }
 
-func serialSlice(v []interface{}) (string, error) {
+func (o SerialOptions) serialSlice(v []interface{}) (string, error) {
 	ss := make([]string, len(v))
 
 	for i, x := range v {
-		s, err := Serial(x)
+		s, err := o.Serial(x)
 		if err != nil {
 			return "", err
 		}
@@ -141,3 +209,187 @@ func serialSlice(v []interface{}) (string, error) {
 
 	return "ARRAY[" + strings.Join(ss, ", ") + "]", nil
 }
+
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// resolveValuers returns the value of a driver.Valuer, and the elements of
+// slices holding some with their values, so that serializing the result
+// does not call Value again. Nil pointers are kept as is.
+func resolveValuers(v interface{}) (interface{}, error) {
+	if x, ok := v.(driver.Valuer); ok {
+		if rv := reflect.ValueOf(x); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return v, nil
+		}
+		vv, err := x.Value()
+		if err != nil {
+			return nil, err
+		}
+		return resolveValuers(vv)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice || rv.IsNil() {
+		return v, nil
+	}
+	elem := rv.Type().Elem()
+	if elem.Kind() != reflect.Interface && elem.Kind() != reflect.Slice && !elem.Implements(valuerType) {
+		return v, nil
+	}
+	resolved := make([]interface{}, rv.Len())
+	for i := range resolved {
+		x, err := resolveValuers(rv.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		resolved[i] = x
+	}
+	return resolved, nil
+}
+
+// numericPattern matches the decimal numbers of Numeric values accepted
+// with SerialOptions.Strict.
+var numericPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?$`)
+
+func (o SerialOptions) serialUint(x uint64) (string, error) {
+	if o.Strict && x > math.MaxInt64 {
+		return "", UnsupportedArgError{"uint64 above the bigint range"}
+	}
+	return strconv.FormatUint(x, 10), nil
+}
+
+// serialTimestamp formats a timestamp with time zone literal, with exactly
+// TimestampPrecision fractional second digits, truncating the time or
+// padding it with zeros.
+func (o SerialOptions) serialTimestamp(t time.Time) (string, error) {
+	precision := o.TimestampPrecision
+	if precision == 0 {
+		precision = 9
+	}
+	if precision < 1 || precision > 12 {
+		return "", fmt.Errorf("trino: invalid timestamp precision %d", o.TimestampPrecision)
+	}
+	fraction := fmt.Sprintf("%09d000", t.Nanosecond())[:precision]
+	return "TIMESTAMP " + QuoteLiteral(t.Format("2006-01-02 15:04:05")+"."+fraction+t.Format(" -07:00")), nil
+}
+
+// serialSpecialFloat returns the Trino expressions of NaN, infinities and
+// negative zero, which cannot be written as numeric literals.
+func serialSpecialFloat(f float64) (string, bool) {
+	switch {
+	case math.IsNaN(f):
+		return "nan()", true
+	case math.IsInf(f, 1):
+		return "infinity()", true
+	case math.IsInf(f, -1):
+		return "-infinity()", true
+	case f == 0 && math.Signbit(f):
+		return "-0E0", true
+	}
+	return "", false
+}
+
+// QuoteIdentifier quotes an identifier, such as a table or column name, so
+// that it can be safely used in a query.
+func QuoteIdentifier(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+// QuoteQualifiedName quotes each part of a qualified name, such as
+// catalog.schema.table, and joins them with dots.
+func QuoteQualifiedName(parts ...string) string {
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		quoted[i] = QuoteIdentifier(part)
+	}
+	return strings.Join(quoted, ".")
+}
+
+// QuoteLiteral quotes a string literal so that it can be safely used in a query.
+func QuoteLiteral(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+var typeParameters = regexp.MustCompile(`\([^()]*\)`)
+
+// baseTypeName returns the name of a Trino type without its parameters,
+// e.g. "timestamp with time zone" for "timestamp(3) with time zone".
+func baseTypeName(trinoType string) string {
+	name := strings.ToLower(strings.TrimSpace(trinoType))
+	for typeParameters.MatchString(name) {
+		name = typeParameters.ReplaceAllString(name, "")
+	}
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// FormatValue converts a value to a literal of the given Trino type, such
+// as DATE '2021-01-01' for a time.Time and "date", consistently with Serial.
+//
+// Values that cannot be represented by a typed literal are cast to the type.
+func FormatValue(v interface{}, trinoType string) (string, error) {
+	typeName := strings.ToUpper(strings.TrimSpace(trinoType))
+	switch baseTypeName(trinoType) {
+	case "boolean", "tinyint", "smallint", "integer", "bigint":
+		if s, ok := v.(string); ok {
+			return typeName + " " + QuoteLiteral(s), nil
+		}
+		return Serial(v)
+	case "varchar", "char":
+		s, ok := v.(string)
+		if !ok {
+			return "", UnsupportedArgError{fmt.Sprintf("%T as %s", v, trinoType)}
+		}
+		if typeName == "VARCHAR" {
+			return QuoteLiteral(s), nil
+		}
+		return "CAST(" + QuoteLiteral(s) + " AS " + typeName + ")", nil
+	case "varbinary":
+		b, ok := v.([]byte)
+		if !ok {
+			return "", UnsupportedArgError{fmt.Sprintf("%T as %s", v, trinoType)}
+		}
+		return fmt.Sprintf("X'%X'", b), nil
+	case "date":
+		return formatTemporal(v, "DATE", "2006-01-02")
+	case "time":
+		return formatTemporal(v, "TIME", "15:04:05.999999999")
+	case "time with time zone":
+		return formatTemporal(v, "TIME", "15:04:05.999999999-07:00")
+	case "timestamp":
+		return formatTemporal(v, "TIMESTAMP", "2006-01-02 15:04:05.999999999")
+	case "timestamp with time zone":
+		return formatTemporal(v, "TIMESTAMP", "2006-01-02 15:04:05.999999999 -07:00")
+	case "real", "double", "decimal", "json", "uuid", "ipaddress":
+		var s string
+		switch x := v.(type) {
+		case string:
+			s = x
+		case Numeric:
+			s = string(x)
+		case float32:
+			s = strconv.FormatFloat(float64(x), 'g', -1, 32)
+		case float64:
+			s = strconv.FormatFloat(x, 'g', -1, 64)
+		default:
+			serial, err := Serial(v)
+			if err != nil {
+				return "", err
+			}
+			s = serial
+		}
+		return typeName + " " + QuoteLiteral(s), nil
+	}
+	s, err := Serial(v)
+	if err != nil {
+		return "", err
+	}
+	return "CAST(" + s + " AS " + typeName + ")", nil
+}
+
+func formatTemporal(v interface{}, keyword, layout string) (string, error) {
+	switch x := v.(type) {
+	case string:
+		return keyword + " " + QuoteLiteral(x), nil
+	case time.Time:
+		return keyword + " " + QuoteLiteral(x.Format(layout)), nil
+	}
+	return "", UnsupportedArgError{fmt.Sprintf("%T as %s", v, strings.ToLower(keyword))}
+}
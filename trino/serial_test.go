@@ -14,7 +14,21 @@
 
 package trino
 
-import "testing"
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+// testUUID is a custom type implementing driver.Valuer.
+type testUUID [2]uint64
+
+func (u testUUID) Value() (driver.Value, error) {
+	return fmt.Sprintf("%016x%016x", u[0], u[1]), nil
+}
 
 func TestSerial(t *testing.T) {
 	scenarios := []struct {
@@ -113,11 +127,56 @@ func TestSerial(t *testing.T) {
 			value:          false,
 			expectedSerial: "false",
 		},
+		{
+			name:          "float64",
+			value:         1.5,
+			expectedError: true,
+		},
+		{
+			name:           "float64 NaN",
+			value:          math.NaN(),
+			expectedSerial: "nan()",
+		},
+		{
+			name:           "float64 infinity",
+			value:          math.Inf(1),
+			expectedSerial: "infinity()",
+		},
+		{
+			name:           "float64 negative infinity",
+			value:          math.Inf(-1),
+			expectedSerial: "-infinity()",
+		},
+		{
+			name:           "float64 negative zero",
+			value:          math.Copysign(0, -1),
+			expectedSerial: "-0E0",
+		},
+		{
+			name:           "float32 NaN",
+			value:          float32(math.NaN()),
+			expectedSerial: "CAST(nan() AS REAL)",
+		},
 		{
 			name:          "nil",
 			value:         nil,
 			expectedError: true,
 		},
+		{
+			name:           "driver.Valuer",
+			value:          testUUID{1, 2},
+			expectedSerial: "'00000000000000010000000000000002'",
+		},
+		{
+			name:          "nil driver.Valuer",
+			value:         (*testUUID)(nil),
+			expectedError: true,
+		},
+		{
+			name:           "slice of driver.Valuer",
+			value:          []testUUID{{0, 1}},
+			expectedSerial: "ARRAY['00000000000000000000000000000001']",
+		},
 		{
 			name:          "slice typed nil",
 			value:         []interface{}(nil),
@@ -162,3 +221,220 @@ func TestSerial(t *testing.T) {
 		})
 	}
 }
+
+func TestQuoting(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		quoted   string
+		expected string
+	}{
+		{name: "identifier", quoted: QuoteIdentifier("orders"), expected: `"orders"`},
+		{name: "identifier with quote", quoted: QuoteIdentifier(`a"b`), expected: `"a""b"`},
+		{name: "qualified name", quoted: QuoteQualifiedName("hive", "default", "my table"), expected: `"hive"."default"."my table"`},
+		{name: "literal", quoted: QuoteLiteral("it's"), expected: `'it''s'`},
+	}
+
+	for i := range scenarios {
+		scenario := scenarios[i]
+
+		t.Run(scenario.name, func(t *testing.T) {
+			if scenario.quoted != scenario.expected {
+				t.Fatalf("mismatched quoting, got %q expected %q", scenario.quoted, scenario.expected)
+			}
+		})
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	ts := time.Date(2021, 1, 2, 3, 4, 5, 600000000, time.FixedZone("", 3600))
+	scenarios := []struct {
+		name          string
+		value         interface{}
+		trinoType     string
+		expectedError bool
+		expected      string
+	}{
+		{name: "bigint", value: int64(42), trinoType: "bigint", expected: "42"},
+		{name: "boolean", value: true, trinoType: "boolean", expected: "true"},
+		{name: "varchar", value: "it's", trinoType: "varchar", expected: "'it''s'"},
+		{name: "bounded varchar", value: "abc", trinoType: "varchar(3)", expected: "CAST('abc' AS VARCHAR(3))"},
+		{name: "varbinary", value: []byte{0xca, 0xfe}, trinoType: "varbinary", expected: "X'CAFE'"},
+		{name: "date", value: ts, trinoType: "date", expected: "DATE '2021-01-02'"},
+		{name: "date string", value: "2021-01-02", trinoType: "DATE", expected: "DATE '2021-01-02'"},
+		{name: "timestamp", value: ts, trinoType: "timestamp(3)", expected: "TIMESTAMP '2021-01-02 03:04:05.6'"},
+		{name: "timestamp with time zone", value: ts, trinoType: "timestamp(6) with time zone", expected: "TIMESTAMP '2021-01-02 03:04:05.6 +01:00'"},
+		{name: "double", value: 1.5, trinoType: "double", expected: "DOUBLE '1.5'"},
+		{name: "decimal", value: Numeric("12.34"), trinoType: "decimal(4,2)", expected: "DECIMAL(4,2) '12.34'"},
+		{name: "json", value: `{"a":1}`, trinoType: "json", expected: `JSON '{"a":1}'`},
+		{name: "array", value: []int{1, 2}, trinoType: "array(integer)", expected: "CAST(ARRAY[1, 2] AS ARRAY(INTEGER))"},
+		{name: "invalid varchar", value: 1, trinoType: "varchar", expectedError: true},
+		{name: "invalid date", value: 1, trinoType: "date", expectedError: true},
+	}
+
+	for i := range scenarios {
+		scenario := scenarios[i]
+
+		t.Run(scenario.name, func(t *testing.T) {
+			s, err := FormatValue(scenario.value, scenario.trinoType)
+			if err != nil {
+				if scenario.expectedError {
+					return
+				}
+				t.Fatal(err)
+			}
+
+			if scenario.expectedError {
+				t.Fatal("missing an expected error")
+			}
+
+			if scenario.expected != s {
+				t.Fatalf("mismatched value, got %q expected %q", s, scenario.expected)
+			}
+		})
+	}
+}
+
+func TestSerialOptions(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.FixedZone("", -8*3600))
+	scenarios := []struct {
+		name           string
+		options        SerialOptions
+		value          interface{}
+		expectedError  bool
+		expectedSerial string
+	}{
+		{name: "float64 rejected", value: 0.1, expectedError: true},
+		{name: "float64", options: SerialOptions{Floats: true}, value: 0.1, expectedSerial: "DOUBLE '0.1'"},
+		{name: "float64 exponent", options: SerialOptions{Floats: true}, value: 1e300, expectedSerial: "DOUBLE '1e+300'"},
+		{name: "float64 full precision", options: SerialOptions{Floats: true}, value: math.Pi, expectedSerial: "DOUBLE '3.141592653589793'"},
+		{name: "float32", options: SerialOptions{Floats: true}, value: float32(0.1), expectedSerial: "REAL '0.1'"},
+		{name: "float64 NaN", options: SerialOptions{Floats: true}, value: math.NaN(), expectedSerial: "nan()"},
+		{name: "float64 slice", options: SerialOptions{Floats: true}, value: []float64{1.5, 2}, expectedSerial: "ARRAY[DOUBLE '1.5', DOUBLE '2']"},
+		{name: "time rejected", value: ts, expectedError: true},
+		{name: "time", options: SerialOptions{Timestamps: true}, value: ts, expectedSerial: "TIMESTAMP '2024-01-02 03:04:05.123456789 -08:00'"},
+		{name: "time milliseconds", options: SerialOptions{Timestamps: true, TimestampPrecision: 3}, value: ts, expectedSerial: "TIMESTAMP '2024-01-02 03:04:05.123 -08:00'"},
+		{name: "time picoseconds", options: SerialOptions{Timestamps: true, TimestampPrecision: 12}, value: ts.UTC(), expectedSerial: "TIMESTAMP '2024-01-02 11:04:05.123456789000 +00:00'"},
+		{name: "time invalid precision", options: SerialOptions{Timestamps: true, TimestampPrecision: 13}, value: ts, expectedError: true},
+		{name: "uint64 above bigint", value: uint64(math.MaxUint64), expectedSerial: "18446744073709551615"},
+		{name: "strict uint64 above bigint", options: SerialOptions{Strict: true}, value: uint64(math.MaxUint64), expectedError: true},
+		{name: "strict uint64", options: SerialOptions{Strict: true}, value: uint64(math.MaxInt64), expectedSerial: "9223372036854775807"},
+		{name: "numeric NaN", value: Numeric("NaN"), expectedSerial: "NaN"},
+		{name: "strict numeric NaN", options: SerialOptions{Strict: true}, value: Numeric("NaN"), expectedError: true},
+		{name: "strict numeric hexadecimal", options: SerialOptions{Strict: true}, value: Numeric("0x1p-2"), expectedError: true},
+		{name: "strict numeric", options: SerialOptions{Strict: true}, value: Numeric("-1.5e3"), expectedSerial: "-1.5e3"},
+	}
+
+	for i := range scenarios {
+		scenario := scenarios[i]
+
+		t.Run(scenario.name, func(t *testing.T) {
+			s, err := scenario.options.Serial(scenario.value)
+			if err != nil {
+				if scenario.expectedError {
+					return
+				}
+				t.Fatal(err)
+			}
+
+			if scenario.expectedError {
+				t.Fatalf("missing an expected error, got %q", s)
+			}
+
+			if scenario.expectedSerial != s {
+				t.Fatalf("mismatched serial, got %q expected %q", s, scenario.expectedSerial)
+			}
+		})
+	}
+}
+
+func TestSerialOptionsArguments(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`, &queries)
+	connector, err := NewConnector(&Config{
+		ServerURI:     ts.URL,
+		SerialOptions: &SerialOptions{Floats: true, Timestamps: true, TimestampPrecision: 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var n int
+	if err := db.QueryRow("SELECT 1 WHERE ? > 0 AND ? < now()", 0.5, at).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	expected := "EXECUTE " + preparedStatementName + " USING DOUBLE '0.5', TIMESTAMP '2024-01-02 03:04:05.000 +00:00'"
+	if len(queries) != 1 || queries[0] != expected {
+		t.Fatalf("mismatched queries, got %q expected %q", queries, expected)
+	}
+}
+
+// countingValuer counts the calls to its Value method.
+type countingValuer struct {
+	calls *int
+}
+
+func (v countingValuer) Value() (driver.Value, error) {
+	*v.calls++
+	return "v", nil
+}
+
+func TestCheckNamedValueCallsValueOnce(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`, &queries)
+	db, err := sql.Open("trino", ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var calls, elemCalls int
+	var n int
+	arg := countingValuer{calls: &calls}
+	slice := []countingValuer{{calls: &elemCalls}, {calls: &elemCalls}}
+	if err := db.QueryRow("SELECT 1 WHERE ? = 'v' AND contains(?, 'v')", arg, slice).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 || elemCalls != 2 {
+		t.Fatalf("Value called %d times for the argument and %d times for the slice elements, expected 1 and 2", calls, elemCalls)
+	}
+	expected := "EXECUTE " + preparedStatementName + " USING 'v', ARRAY['v', 'v']"
+	if len(queries) != 1 || queries[0] != expected {
+		t.Fatalf("mismatched queries, got %q expected %q", queries, expected)
+	}
+}
+
+func TestSafeParameters(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`, &queries)
+	db, err := sql.Open("trino", ts.URL+"?safe_parameters=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var n int
+	if err := db.QueryRow("SELECT 1 WHERE ? = name AND contains(?, tag) AND id > ?", "o'neil", []string{"a", "é"}, 10).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	expected := "EXECUTE " + preparedStatementName + " USING from_utf8(X'6F276E65696C'), ARRAY[from_utf8(X'61'), from_utf8(X'C3A9')], 10"
+	if len(queries) != 1 || queries[0] != expected {
+		t.Fatalf("mismatched queries, got %q expected %q", queries, expected)
+	}
+}
+
+func TestSafeParametersDSN(t *testing.T) {
+	c := &Config{ServerURI: "http://foobar@localhost:8080", SafeParameters: true}
+	dsn, err := c.FormatDSN()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "http://foobar@localhost:8080?safe_parameters=true&source=trino-go-client"; dsn != expected {
+		t.Fatalf("mismatched dsn, got %q expected %q", dsn, expected)
+	}
+	if _, err := sql.Open("trino", "http://foobar@localhost:8080?safe_parameters=maybe"); err == nil {
+		t.Fatal("missing an expected error")
+	}
+}
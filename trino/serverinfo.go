@@ -0,0 +1,117 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ServerInfo describes the Trino node the DSN points to, as returned by its
+// /v1/info endpoint.
+type ServerInfo struct {
+	NodeVersion string
+	Environment string
+	Coordinator bool // Whether the node is a coordinator, rather than a worker
+	Starting    bool // Whether the node is still starting
+	Uptime      time.Duration
+}
+
+type serverInfoResponse struct {
+	NodeVersion struct {
+		Version string `json:"version"`
+	} `json:"nodeVersion"`
+	Environment string          `json:"environment"`
+	Coordinator bool            `json:"coordinator"`
+	Starting    bool            `json:"starting"`
+	Uptime      airliftDuration `json:"uptime"`
+}
+
+// ErrNotCoordinator is returned, when verify_coordinator is enabled, if the
+// DSN points to a worker rather than to a coordinator.
+type ErrNotCoordinator struct {
+	URL string // Server URL of the DSN
+}
+
+// Error implements the error interface.
+func (e *ErrNotCoordinator) Error() string {
+	return fmt.Sprintf("trino: %s is a worker, not a coordinator: the DSN must point to the coordinator", e.URL)
+}
+
+// ServerInfo returns the description of the node the connection sends its
+// requests to. It can be called with sql.Conn.Raw, or with GetServerInfo.
+func (c *Conn) ServerInfo(ctx context.Context) (*ServerInfo, error) {
+	req, err := c.newRequest("GET", c.baseURL+"/v1/info", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.roundTrip(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var r serverInfoResponse
+	if err := c.jsonDecoder.Decode(resp.Body, &r); err != nil {
+		return nil, fmt.Errorf("trino: %v", err)
+	}
+	return &ServerInfo{
+		NodeVersion: r.NodeVersion.Version,
+		Environment: r.Environment,
+		Coordinator: r.Coordinator,
+		Starting:    r.Starting,
+		Uptime:      time.Duration(r.Uptime),
+	}, nil
+}
+
+// GetServerInfo returns the description of the node the database sends its
+// requests to. Only databases opened with this driver are supported.
+func GetServerInfo(ctx context.Context, db *sql.DB) (*ServerInfo, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var info *ServerInfo
+	err = conn.Raw(func(driverConn interface{}) error {
+		c, ok := driverConn.(*Conn)
+		if !ok {
+			return errors.New("trino: server info is only supported by databases opened with this driver")
+		}
+		info, err = c.ServerInfo(ctx)
+		return err
+	})
+	return info, err
+}
+
+// verifyCoordinator checks once, when verify_coordinator is enabled, that
+// the connection sends its requests to a coordinator.
+func (c *Conn) verifyCoordinator(ctx context.Context) error {
+	if !c.checkCoordinator || c.coordinatorVerified {
+		return nil
+	}
+	info, err := c.ServerInfo(ctx)
+	if err != nil {
+		markUnsent(err)
+		return err
+	}
+	if !info.Coordinator {
+		return &ErrNotCoordinator{URL: c.baseURL}
+	}
+	c.coordinatorVerified = true
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newServerInfoTestServer(t *testing.T, coordinator bool, requests *[]string) *httptest.Server {
+	result := `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`
+	return newPagedResultTestServer(t, []string{result}, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		*requests = append(*requests, r.Method+" "+r.URL.Path)
+		if r.URL.Path != "/v1/info" {
+			return false
+		}
+		info := `{"nodeVersion": {"version": "400"}, "environment": "test", "coordinator": false, "starting": false, "uptime": "1.50m"}`
+		if coordinator {
+			info = `{"nodeVersion": {"version": "400"}, "environment": "test", "coordinator": true, "starting": false, "uptime": "1.50m"}`
+		}
+		w.Write([]byte(info))
+		return true
+	})
+}
+
+func TestGetServerInfo(t *testing.T) {
+	var requests []string
+	ts := newServerInfoTestServer(t, true, &requests)
+	db := openTestDB(t, ts)
+
+	info, err := GetServerInfo(context.Background(), db)
+	require.NoError(t, err)
+	assert.Equal(t, &ServerInfo{
+		NodeVersion: "400",
+		Environment: "test",
+		Coordinator: true,
+		Uptime:      90 * time.Second,
+	}, info)
+}
+
+func TestVerifyCoordinator(t *testing.T) {
+	var requests []string
+	ts := newServerInfoTestServer(t, true, &requests)
+	db, err := sql.Open("trino", ts.URL+"?verify_coordinator=true")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+	db.SetMaxOpenConns(1)
+
+	var n int
+	require.NoError(t, db.QueryRow("SELECT 1").Scan(&n))
+	require.NoError(t, db.QueryRow("SELECT 1").Scan(&n))
+	// verified once per connection
+	assert.Equal(t, []string{
+		"GET /v1/info",
+		"POST /v1/statement",
+		"GET /v1/statement/20210101_000000_00000_abcde/1",
+		"DELETE /v1/query/20210101_000000_00000_abcde",
+		"POST /v1/statement",
+		"GET /v1/statement/20210101_000000_00000_abcde/1",
+		"DELETE /v1/query/20210101_000000_00000_abcde",
+	}, requests)
+}
+
+func TestVerifyCoordinatorWorker(t *testing.T) {
+	var requests []string
+	ts := newServerInfoTestServer(t, false, &requests)
+	db, err := sql.Open("trino", ts.URL+"?verify_coordinator=true")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	var n int
+	err = db.QueryRow("SELECT 1").Scan(&n)
+	var workerErr *ErrNotCoordinator
+	require.True(t, errors.As(err, &workerErr), "unexpected error: %v", err)
+	assert.Equal(t, ts.URL, workerErr.URL)
+	assert.Equal(t, []string{"GET /v1/info"}, requests)
+}
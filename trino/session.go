@@ -0,0 +1,286 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type sessionPropertiesKey struct{}
+
+// contextSessionProperties holds the session properties set on a context,
+// or the error of the first invalid one.
+type contextSessionProperties struct {
+	properties []string // key=value
+	err        error
+}
+
+func withContextSessionProperty(ctx context.Context, name, value string, err error) context.Context {
+	var props contextSessionProperties
+	if parent, ok := ctx.Value(sessionPropertiesKey{}).(*contextSessionProperties); ok {
+		props.properties = append(props.properties, parent.properties...)
+		props.err = parent.err
+	}
+	if props.err == nil {
+		props.err = err
+	}
+	props.properties = append(props.properties, encodeSessionProperty(name, value))
+	return context.WithValue(ctx, sessionPropertiesKey{}, &props)
+}
+
+// encodeSessionProperty returns the session property as sent in the session
+// header, with its value URL encoded, as Trino decodes it.
+func encodeSessionProperty(name, value string) string {
+	return name + "=" + url.QueryEscape(value)
+}
+
+// parseSessionProperties returns the value of the session header from the
+// session_properties parameter, a comma separated list of name=value, in
+// which commas and backslashes of values are escaped with a backslash.
+// Properties are kept in order, including duplicated ones, and the values of
+// KnownSessionProperties are validated.
+func parseSessionProperties(v string) (string, error) {
+	names, values, ok := splitSessionProperties(v)
+	if !ok {
+		return "", invalidParameter("session_properties", v)
+	}
+	properties := make([]string, len(names))
+	for i, name := range names {
+		if err := validateSessionProperty(name, values[i]); err != nil {
+			return "", err
+		}
+		properties[i] = encodeSessionProperty(name, values[i])
+	}
+	return strings.Join(properties, ","), nil
+}
+
+// splitSessionProperties returns the names and values of the properties of
+// a session_properties parameter, and false if it is malformed.
+func splitSessionProperties(v string) ([]string, []string, bool) {
+	if v == "" {
+		return nil, nil, true
+	}
+	var names, values []string
+	var entry strings.Builder
+	flush := func() bool {
+		name, value, ok := cutString(entry.String(), "=")
+		if !ok || name == "" {
+			return false
+		}
+		names = append(names, name)
+		values = append(values, value)
+		entry.Reset()
+		return true
+	}
+	for i := 0; i < len(v); i++ {
+		switch v[i] {
+		case '\\':
+			if i+1 == len(v) {
+				return nil, nil, false
+			}
+			i++
+			entry.WriteByte(v[i])
+		case ',':
+			if !flush() {
+				return nil, nil, false
+			}
+		default:
+			entry.WriteByte(v[i])
+		}
+	}
+	if !flush() {
+		return nil, nil, false
+	}
+	return names, values, true
+}
+
+var sessionPropertyEscaper = strings.NewReplacer(`\`, `\\`, `,`, `\,`)
+
+// formatSessionProperties returns the value of the session_properties
+// parameter, with the properties named in order first, and the others
+// sorted by name.
+func formatSessionProperties(properties map[string]string, order []string) (string, error) {
+	names := make([]string, 0, len(properties))
+	seen := make(map[string]bool, len(properties))
+	for _, name := range order {
+		if _, ok := properties[name]; ok && !seen[name] {
+			names = append(names, name)
+			seen[name] = true
+		}
+	}
+	var rest []string
+	for name := range properties {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	names = append(names, rest...)
+
+	entries := make([]string, len(names))
+	for i, name := range names {
+		if name == "" || strings.ContainsAny(name, "=,\\") {
+			return "", fmt.Errorf("trino: invalid session property name %q", name)
+		}
+		entries[i] = name + "=" + sessionPropertyEscaper.Replace(properties[name])
+	}
+	return strings.Join(entries, ","), nil
+}
+
+// cutString slices s around the first instance of sep.
+func cutString(s, sep string) (string, string, bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// WithSessionProperty returns a context that sets the session property for
+// the queries executed with it, in addition to the session properties of the
+// connection. The queries fail without being sent if the value of one of the
+// KnownSessionProperties is invalid.
+func WithSessionProperty(ctx context.Context, name, value string) context.Context {
+	var err error
+	if name == "" || strings.ContainsAny(name, "=,") {
+		err = fmt.Errorf("trino: invalid session property name %q", name)
+	} else {
+		err = validateSessionProperty(name, value)
+	}
+	return withContextSessionProperty(ctx, name, value, err)
+}
+
+// WithQueryPriority returns a context that sets the query_priority session
+// property for the queries executed with it. Priorities start at 1, and
+// queries with higher priorities are scheduled first by resource groups.
+func WithQueryPriority(ctx context.Context, priority int) context.Context {
+	var err error
+	if priority < 1 {
+		err = fmt.Errorf("trino: invalid query_priority %d, must be at least 1", priority)
+	}
+	return withContextSessionProperty(ctx, "query_priority", strconv.Itoa(priority), err)
+}
+
+// WithResourceOvercommit returns a context that sets the resource_overcommit
+// session property for the queries executed with it, allowing them to use
+// more memory than the per-node limit by risking being killed.
+func WithResourceOvercommit(ctx context.Context, overcommit bool) context.Context {
+	return withContextSessionProperty(ctx, "resource_overcommit", strconv.FormatBool(overcommit), nil)
+}
+
+// WithQueryMaxMemory returns a context that sets the query_max_memory
+// session property, in bytes, for the queries executed with it.
+func WithQueryMaxMemory(ctx context.Context, bytes int64) context.Context {
+	var err error
+	if bytes <= 0 {
+		err = fmt.Errorf("trino: invalid query_max_memory %d, must be positive", bytes)
+	}
+	return withContextSessionProperty(ctx, "query_max_memory", strconv.FormatInt(bytes, 10)+"B", err)
+}
+
+// addContextSessionProperties adds the session properties set on the
+// context to the request headers, merged with the connection ones.
+func (c *Conn) addContextSessionProperties(ctx context.Context, hs http.Header) (http.Header, error) {
+	props, ok := ctx.Value(sessionPropertiesKey{}).(*contextSessionProperties)
+	if !ok {
+		return hs, nil
+	}
+	if props.err != nil {
+		return nil, props.err
+	}
+	if hs == nil {
+		hs = make(http.Header)
+	}
+	base := hs[trinoSessionHeader]
+	if base == nil {
+		base = c.httpHeaders[trinoSessionHeader]
+	}
+	hs[trinoSessionHeader] = append(append([]string{}, base...), props.properties...)
+	return hs, nil
+}
+
+// updateSession applies the changes to the state of the session sent by
+// Trino in the headers of a response, such as the catalog and schema set by
+// USE statements, to the following requests of the connection. Responses
+// changing the session in ways the driver does not support fail with
+// ErrUnsupportedHeader, without applying any of their changes.
+func (c *Conn) updateSession(h http.Header) error {
+	for _, name := range unsupportedResponseHeaders {
+		if h.Get(name) != "" {
+			return ErrUnsupportedHeader
+		}
+	}
+	for src, dst := range responseToRequestHeaderMap {
+		if v := h.Get(src); v != "" {
+			c.httpHeaders.Set(dst, v)
+		}
+	}
+	for _, v := range h[trinoAddedPrepareHeader] {
+		kv := strings.SplitN(v, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		name, err := url.QueryUnescape(kv[0])
+		if err != nil {
+			continue
+		}
+		query, err := url.QueryUnescape(kv[1])
+		if err != nil {
+			continue
+		}
+		if c.prepared == nil {
+			c.prepared = make(map[string]string)
+		}
+		c.prepared[name] = query
+	}
+	for _, v := range h[trinoDeallocatedPrepareHeader] {
+		if name, err := url.QueryUnescape(v); err == nil {
+			delete(c.prepared, name)
+		}
+	}
+	return nil
+}
+
+// addPreparedStatements adds the statements prepared with PREPARE
+// statements on the connection to the headers of a request, so that they
+// can be run with EXECUTE and described with DESCRIBE INPUT or OUTPUT.
+func (c *Conn) addPreparedStatements(h http.Header) {
+	names := make([]string, 0, len(c.prepared))
+	for name := range c.prepared {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		h.Add(preparedStatementHeader, url.QueryEscape(name)+"="+url.QueryEscape(c.prepared[name]))
+	}
+}
+
+// Catalog returns the current catalog of the session, as set by the DSN or
+// by USE statements, or an empty string if none is set. Use sql.Conn.Raw to
+// access it from a database/sql connection.
+func (c *Conn) Catalog() string {
+	return c.httpHeaders.Get(trinoCatalogHeader)
+}
+
+// Schema returns the current schema of the session, as set by the DSN or
+// by USE statements, or an empty string if none is set.
+func (c *Conn) Schema() string {
+	return c.httpHeaders.Get(trinoSchemaHeader)
+}
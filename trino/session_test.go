@@ -0,0 +1,204 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextSessionProperties(t *testing.T) {
+	var headers http.Header
+	ts := newHeaderTestServer(t, `"stats": {"state": "FINISHED"}`, &headers)
+
+	db, err := sql.Open("trino", ts.URL+"?session_properties=query_max_run_time%3D10m")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	ctx := WithQueryPriority(context.Background(), 2)
+	ctx = WithResourceOvercommit(ctx, true)
+	ctx = WithQueryMaxMemory(ctx, 1<<30)
+	ctx = WithSessionProperty(ctx, "join_distribution_type", "BROADCAST")
+	_, err = db.ExecContext(ctx, "SELECT 1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"query_max_run_time=10m",
+		"query_priority=2",
+		"resource_overcommit=true",
+		"query_max_memory=1073741824B",
+		"join_distribution_type=BROADCAST",
+	}, headers[trinoSessionHeader])
+
+	_, err = db.Exec("SELECT 1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"query_max_run_time=10m"}, headers[trinoSessionHeader])
+}
+
+func TestContextSessionPropertiesValidation(t *testing.T) {
+	db, err := sql.Open("trino", "http://localhost:9")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	for _, ctx := range []context.Context{
+		WithQueryPriority(context.Background(), 0),
+		WithQueryMaxMemory(context.Background(), -1),
+		WithSessionProperty(context.Background(), "a=b", "c"),
+		WithResourceOvercommit(WithQueryPriority(context.Background(), 0), true),
+	} {
+		_, err = db.ExecContext(ctx, "SELECT 1")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid")
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	var headers http.Header
+	ts := newHeaderTestServer(t, `
+		"columns": [{"name": "_col0", "type": "varchar"}],
+		"data": [["janvier"]]`, &headers)
+	dsn, err := (&Config{ServerURI: ts.URL, Language: "fr-FR"}).FormatDSN()
+	require.NoError(t, err)
+	db, err := sql.Open("trino", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	var month string
+	require.NoError(t, db.QueryRow("SELECT format_datetime(DATE '2021-01-01', 'MMMM')").Scan(&month))
+	assert.Equal(t, "fr-FR", headers.Get(trinoLanguageHeader))
+}
+
+func TestUseCatalogSchema(t *testing.T) {
+	var requested []string
+	ts := newPagedResultTestServer(t, []string{`"stats": {"state": "FINISHED"}`}, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method == "DELETE" {
+			return false
+		}
+		requested = append(requested, r.Header.Get(trinoCatalogHeader)+"."+r.Header.Get(trinoSchemaHeader))
+		if testPage(r) > 0 {
+			// the schema is set by the final response, as Trino does
+			w.Header().Set(trinoSetCatalogHeader, "tpch")
+			w.Header().Set(trinoSetSchemaHeader, "tiny")
+		}
+		return false
+	})
+
+	db, err := sql.Open("trino", ts.URL+"?catalog=hive&schema=default")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+	conn, err := db.Conn(context.Background())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.ExecContext(context.Background(), "USE TPCH.TINY")
+	require.NoError(t, err)
+	var catalog, schema string
+	require.NoError(t, conn.Raw(func(driverConn interface{}) error {
+		c := driverConn.(*Conn)
+		catalog, schema = c.Catalog(), c.Schema()
+		return nil
+	}))
+	assert.Equal(t, "tpch", catalog)
+	assert.Equal(t, "tiny", schema)
+
+	_, err = conn.ExecContext(context.Background(), "SELECT 1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hive.default", "hive.default", "tpch.tiny", "tpch.tiny"}, requested)
+}
+
+func TestPrepareDeallocate(t *testing.T) {
+	var prepared []string
+	ts := newPagedResultTestServer(t, []string{`"stats": {"state": "FINISHED"}`}, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path != "/v1/statement" {
+			return false
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		prepared = append(prepared, strings.Join(r.Header[preparedStatementHeader], ","))
+		switch query := string(b); {
+		case strings.HasPrefix(query, "PREPARE"):
+			w.Header().Add(trinoAddedPrepareHeader, "my+query="+url.QueryEscape("SELECT ?"))
+		case strings.HasPrefix(query, "DEALLOCATE"):
+			w.Header().Add(trinoDeallocatedPrepareHeader, "my+query")
+		}
+		return false
+	})
+	db := openTestDB(t, ts)
+	conn, err := db.Conn(context.Background())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	for _, query := range []string{
+		`PREPARE "my query" FROM SELECT ?`,
+		`EXECUTE "my query" USING 1`,
+		`DEALLOCATE PREPARE "my query"`,
+		`SELECT 1`,
+	} {
+		_, err = conn.ExecContext(context.Background(), query)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"", "my+query=SELECT+%3F", "my+query=SELECT+%3F", ""}, prepared)
+}
+
+func TestSessionPropertiesEscaping(t *testing.T) {
+	header, err := parseSessionProperties(`a=x\,y,b=k=v,c=50%,a=\\z`)
+	require.NoError(t, err)
+	assert.Equal(t, `a=x%2Cy,b=k%3Dv,c=50%25,a=%5Cz`, header)
+
+	for _, v := range []string{"a", "=x", `a=x\`} {
+		_, err := parseSessionProperties(v)
+		assert.EqualError(t, err, fmt.Sprintf("trino: invalid session_properties: %q", v))
+	}
+
+	c := &Config{
+		ServerURI: "http://foobar@localhost:8080",
+		SessionProperties: map[string]string{
+			"query_max_run_time": "10m",
+			"a":                  `x,y\z`,
+			"b":                  "k=v",
+		},
+		SessionPropertyOrder: []string{"query_max_run_time", "missing"},
+	}
+	dsn, err := c.FormatDSN()
+	require.NoError(t, err)
+	u, err := url.Parse(dsn)
+	require.NoError(t, err)
+	assert.Equal(t, `query_max_run_time=10m,a=x\,y\\z,b=k=v`, u.Query().Get("session_properties"))
+
+	conn, err := newConn(dsn)
+	require.NoError(t, err)
+	assert.Equal(t, `query_max_run_time=10m,a=x%2Cy%5Cz,b=k%3Dv`, conn.httpHeaders.Get(trinoSessionHeader))
+
+	c.SessionProperties = map[string]string{"a,b": "c"}
+	_, err = c.FormatDSN()
+	assert.EqualError(t, err, `trino: invalid session property name "a,b"`)
+}
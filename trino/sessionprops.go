@@ -0,0 +1,162 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SessionPropertyType is the type of the value of a session property.
+type SessionPropertyType string
+
+// Types of session properties.
+const (
+	SessionPropertyBoolean  SessionPropertyType = "boolean"
+	SessionPropertyInteger  SessionPropertyType = "integer"
+	SessionPropertyBigint   SessionPropertyType = "bigint"
+	SessionPropertyDouble   SessionPropertyType = "double"
+	SessionPropertyVarchar  SessionPropertyType = "varchar"
+	SessionPropertyDuration SessionPropertyType = "duration"  // e.g. 10m or 1.5h
+	SessionPropertyDataSize SessionPropertyType = "data size" // e.g. 512MB or 1.5GB
+	SessionPropertyEnum     SessionPropertyType = "enum"      // one of the Values of the property
+)
+
+// Names of well-known system session properties.
+const (
+	SessionQueryMaxRunTime                   = "query_max_run_time"
+	SessionQueryMaxExecutionTime             = "query_max_execution_time"
+	SessionQueryMaxPlanningTime              = "query_max_planning_time"
+	SessionQueryMaxCPUTime                   = "query_max_cpu_time"
+	SessionQueryMaxMemory                    = "query_max_memory"
+	SessionQueryMaxTotalMemory               = "query_max_total_memory"
+	SessionQueryMaxScanPhysicalBytes         = "query_max_scan_physical_bytes"
+	SessionQueryMaxStageCount                = "query_max_stage_count"
+	SessionQueryPriority                     = "query_priority"
+	SessionResourceOvercommit                = "resource_overcommit"
+	SessionJoinDistributionType              = "join_distribution_type"
+	SessionJoinReorderingStrategy            = "join_reordering_strategy"
+	SessionJoinMaxBroadcastTableSize         = "join_max_broadcast_table_size"
+	SessionEnableDynamicFiltering            = "enable_dynamic_filtering"
+	SessionTaskConcurrency                   = "task_concurrency"
+	SessionHashPartitionCount                = "hash_partition_count"
+	SessionRedistributeWrites                = "redistribute_writes"
+	SessionScaleWriters                      = "scale_writers"
+	SessionDistributedSort                   = "distributed_sort"
+	SessionSpillEnabled                      = "spill_enabled"
+	SessionRetryPolicy                       = "retry_policy"
+	SessionRequiredWorkersCount              = "required_workers_count"
+	SessionRequiredWorkersMaxWait            = "required_workers_max_wait_time"
+	SessionIterativeOptimizerTimeout         = "iterative_optimizer_timeout"
+	SessionPushPartialAggregationThroughJoin = "push_partial_aggregation_through_join"
+)
+
+// SessionProperty describes a session property of Trino.
+type SessionProperty struct {
+	Name   string
+	Type   SessionPropertyType
+	Values []string // Allowed values of enum properties, matched ignoring case
+}
+
+// KnownSessionProperties are the system session properties whose values are
+// validated when connections are opened, and by WithSessionProperty, rather
+// than failing the queries. Other properties, such as the ones of catalogs,
+// are sent as is.
+var KnownSessionProperties = map[string]SessionProperty{}
+
+func init() {
+	for _, p := range []SessionProperty{
+		{Name: SessionQueryMaxRunTime, Type: SessionPropertyDuration},
+		{Name: SessionQueryMaxExecutionTime, Type: SessionPropertyDuration},
+		{Name: SessionQueryMaxPlanningTime, Type: SessionPropertyDuration},
+		{Name: SessionQueryMaxCPUTime, Type: SessionPropertyDuration},
+		{Name: SessionQueryMaxMemory, Type: SessionPropertyDataSize},
+		{Name: SessionQueryMaxTotalMemory, Type: SessionPropertyDataSize},
+		{Name: SessionQueryMaxScanPhysicalBytes, Type: SessionPropertyDataSize},
+		{Name: SessionQueryMaxStageCount, Type: SessionPropertyInteger},
+		{Name: SessionQueryPriority, Type: SessionPropertyInteger},
+		{Name: SessionResourceOvercommit, Type: SessionPropertyBoolean},
+		{Name: SessionJoinDistributionType, Type: SessionPropertyEnum, Values: []string{"AUTOMATIC", "BROADCAST", "PARTITIONED"}},
+		{Name: SessionJoinReorderingStrategy, Type: SessionPropertyEnum, Values: []string{"AUTOMATIC", "ELIMINATE_CROSS_JOINS", "NONE"}},
+		{Name: SessionJoinMaxBroadcastTableSize, Type: SessionPropertyDataSize},
+		{Name: SessionEnableDynamicFiltering, Type: SessionPropertyBoolean},
+		{Name: SessionTaskConcurrency, Type: SessionPropertyInteger},
+		{Name: SessionHashPartitionCount, Type: SessionPropertyInteger},
+		{Name: SessionRedistributeWrites, Type: SessionPropertyBoolean},
+		{Name: SessionScaleWriters, Type: SessionPropertyBoolean},
+		{Name: SessionDistributedSort, Type: SessionPropertyBoolean},
+		{Name: SessionSpillEnabled, Type: SessionPropertyBoolean},
+		{Name: SessionRetryPolicy, Type: SessionPropertyEnum, Values: []string{"NONE", "QUERY", "TASK"}},
+		{Name: SessionRequiredWorkersCount, Type: SessionPropertyInteger},
+		{Name: SessionRequiredWorkersMaxWait, Type: SessionPropertyDuration},
+		{Name: SessionIterativeOptimizerTimeout, Type: SessionPropertyDuration},
+		{Name: SessionPushPartialAggregationThroughJoin, Type: SessionPropertyBoolean},
+	} {
+		KnownSessionProperties[p.Name] = p
+	}
+}
+
+var (
+	durationPattern = regexp.MustCompile(`^\s*\d+(\.\d+)?\s*(ns|us|ms|s|m|h|d)\s*$`)
+	dataSizePattern = regexp.MustCompile(`^\s*\d+(\.\d+)?\s*(B|kB|MB|GB|TB|PB)\s*$`)
+)
+
+// Validate returns an error if the value is not valid for the property.
+func (p SessionProperty) Validate(value string) error {
+	var ok bool
+	switch p.Type {
+	case SessionPropertyBoolean:
+		ok = strings.EqualFold(value, "true") || strings.EqualFold(value, "false")
+	case SessionPropertyInteger:
+		_, err := strconv.ParseInt(value, 10, 32)
+		ok = err == nil
+	case SessionPropertyBigint:
+		_, err := strconv.ParseInt(value, 10, 64)
+		ok = err == nil
+	case SessionPropertyDouble:
+		_, err := strconv.ParseFloat(value, 64)
+		ok = err == nil
+	case SessionPropertyDuration:
+		ok = durationPattern.MatchString(value)
+	case SessionPropertyDataSize:
+		ok = dataSizePattern.MatchString(value)
+	case SessionPropertyEnum:
+		for _, v := range p.Values {
+			if strings.EqualFold(value, v) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("trino: invalid value %q for session property %s, expected one of %s", value, p.Name, strings.Join(p.Values, ", "))
+		}
+	default:
+		ok = true
+	}
+	if !ok {
+		return fmt.Errorf("trino: invalid %s %q for session property %s", p.Type, value, p.Name)
+	}
+	return nil
+}
+
+// validateSessionProperty validates the value of a known session property.
+func validateSessionProperty(name, value string) error {
+	if p, ok := KnownSessionProperties[name]; ok {
+		return p.Validate(value)
+	}
+	return nil
+}
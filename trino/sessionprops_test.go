@@ -0,0 +1,76 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionPropertyValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		value string
+		err   string
+	}{
+		{name: SessionQueryMaxRunTime, value: "10m"},
+		{name: SessionQueryMaxRunTime, value: "1.5h"},
+		{name: SessionQueryMaxRunTime, value: "10x", err: `trino: invalid duration "10x" for session property query_max_run_time`},
+		{name: SessionQueryMaxMemory, value: "512MB"},
+		{name: SessionQueryMaxMemory, value: "512", err: `trino: invalid data size "512" for session property query_max_memory`},
+		{name: SessionQueryPriority, value: "2"},
+		{name: SessionQueryPriority, value: "high", err: `trino: invalid integer "high" for session property query_priority`},
+		{name: SessionResourceOvercommit, value: "TRUE"},
+		{name: SessionResourceOvercommit, value: "1", err: `trino: invalid boolean "1" for session property resource_overcommit`},
+		{name: SessionJoinDistributionType, value: "broadcast"},
+		{name: SessionJoinDistributionType, value: "HASH", err: `trino: invalid value "HASH" for session property join_distribution_type, expected one of AUTOMATIC, BROADCAST, PARTITIONED`},
+		{name: "hive.insert_existing_partitions_behavior", value: "anything"},
+	} {
+		err := validateSessionProperty(tt.name, tt.value)
+		if tt.err == "" {
+			assert.NoError(t, err, "%s=%s", tt.name, tt.value)
+		} else {
+			assert.EqualError(t, err, tt.err)
+		}
+	}
+}
+
+func TestSessionPropertyValidateDSN(t *testing.T) {
+	_, err := newConn("http://foobar@localhost:8080?session_properties=query_max_run_time%3D10x")
+	assert.EqualError(t, err, `trino: invalid duration "10x" for session property query_max_run_time`)
+
+	connector, err := NewConnector(&Config{
+		ServerURI:         "http://foobar@localhost:8080",
+		SessionProperties: map[string]string{SessionRetryPolicy: "ALWAYS"},
+	})
+	if err == nil {
+		_, err = connector.Connect(context.Background())
+	}
+	assert.EqualError(t, err, `trino: invalid value "ALWAYS" for session property retry_policy, expected one of NONE, QUERY, TASK`)
+}
+
+func TestSessionPropertyValidateContext(t *testing.T) {
+	ts := newResultTestServer(t, `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`, nil)
+	db := openTestDB(t, ts)
+
+	ctx := WithSessionProperty(context.Background(), SessionQueryMaxCPUTime, "forever")
+	err := db.QueryRowContext(ctx, "SELECT 1").Scan(new(int))
+	assert.EqualError(t, err, `trino: invalid duration "forever" for session property query_max_cpu_time`)
+
+	ctx = WithSessionProperty(context.Background(), SessionQueryMaxCPUTime, "1h")
+	assert.NoError(t, db.QueryRowContext(ctx, "SELECT 1").Scan(new(int)))
+}
@@ -0,0 +1,196 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// ErrShutdown is returned when executing a query after Shutdown was called.
+var ErrShutdown = errors.New("trino: shutting down")
+
+// Shutdown rejects new queries of the database with ErrShutdown, and waits
+// for the ones in flight to complete, that is, to be fully read, or closed.
+// If the context is done first, the remaining queries are cancelled in
+// Trino, so that they do not keep running after the application exits, and
+// the context error is returned.
+//
+// Shutdown does not close the database. Only databases opened with this
+// driver, using sql.Open or NewConnector, are supported.
+func Shutdown(ctx context.Context, db *sql.DB) error {
+	d, ok := db.Driver().(*sqldriver)
+	if !ok || d.connector == nil {
+		return errors.New("trino: shutdown is only supported by databases opened with this driver")
+	}
+	return d.connector.tracker.shutdown(ctx)
+}
+
+// queryTracker keeps track of the queries in flight of a connector.
+type queryTracker struct {
+	mu        sync.Mutex
+	inflight  int
+	queries   map[*driverRows]*cancelRequest
+	closing   bool
+	cancelled bool
+	idle      chan struct{} // closed when no query is in flight while closing
+}
+
+// cancelRequest is the request to cancel a query. It is prepared by the
+// goroutine running the query, as connections are not safe for concurrent
+// use.
+type cancelRequest struct {
+	client http.Client
+	req    *http.Request
+}
+
+func (r *cancelRequest) send() {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCancelQueryTimeout)
+	defer cancel()
+	resp, err := doCounted(&r.client, r.req.WithContext(ctx))
+	if err == nil {
+		resp.Body.Close()
+	}
+}
+
+// submit submits the query, registering it in the tracker of the
+// connection, if any.
+func (st *driverStmt) submit(ctx context.Context, args []driver.NamedValue) (*stmtResponse, error) {
+	t := st.conn.tracker
+	if t == nil {
+		return st.exec(ctx, args)
+	}
+	if err := t.begin(); err != nil {
+		return nil, err
+	}
+	sr, err := st.exec(ctx, args)
+	if err != nil {
+		t.end(nil)
+	}
+	return sr, err
+}
+
+// track registers the submitted query in the tracker of the connection,
+// until untrack is called.
+func (qr *driverRows) track() {
+	if t := qr.stmt.conn.tracker; t != nil {
+		t.track(qr)
+		qr.tracked = true
+	}
+}
+
+func (qr *driverRows) untrack() {
+	if qr.tracked {
+		qr.tracked = false
+		qr.stmt.conn.tracker.end(qr)
+	}
+}
+
+// begin registers a query about to be submitted.
+func (t *queryTracker) begin() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closing {
+		return ErrShutdown
+	}
+	t.inflight++
+	return nil
+}
+
+// track registers a submitted query, so that it can be cancelled.
+func (t *queryTracker) track(qr *driverRows) {
+	c := qr.stmt.conn
+	hs := make(http.Header)
+	if qr.stmt.user != "" {
+		hs.Add(trinoUserHeader, qr.stmt.user)
+	}
+	req, err := c.newRequest("DELETE", c.baseURL+"/v1/query/"+url.PathEscape(qr.queryID), nil, hs)
+	if err != nil {
+		return
+	}
+	for k, v := range c.extraHeaders {
+		req.Header[k] = v
+	}
+	addCookies(req, qr.cookies)
+	r := &cancelRequest{client: c.httpClient, req: req}
+
+	t.mu.Lock()
+	if t.cancelled {
+		t.mu.Unlock()
+		r.send()
+		return
+	}
+	if t.queries == nil {
+		t.queries = make(map[*driverRows]*cancelRequest)
+	}
+	t.queries[qr] = r
+	t.mu.Unlock()
+}
+
+// end unregisters a completed query, qr being nil if it failed before
+// being submitted.
+func (t *queryTracker) end(qr *driverRows) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.queries, qr)
+	t.inflight--
+	if t.inflight == 0 && t.idle != nil {
+		close(t.idle)
+		t.idle = nil
+	}
+}
+
+func (t *queryTracker) shutdown(ctx context.Context) error {
+	t.mu.Lock()
+	t.closing = true
+	if t.inflight == 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	if t.idle == nil {
+		t.idle = make(chan struct{})
+	}
+	idle := t.idle
+	t.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+	}
+
+	t.mu.Lock()
+	t.cancelled = true
+	queries := make([]*cancelRequest, 0, len(t.queries))
+	for _, r := range t.queries {
+		queries = append(queries, r)
+	}
+	t.mu.Unlock()
+	var wg sync.WaitGroup
+	for _, r := range queries {
+		wg.Add(1)
+		go func(r *cancelRequest) {
+			defer wg.Done()
+			r.send()
+		}(r)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
@@ -0,0 +1,112 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRunningTestServer returns a server running queries until they are
+// cancelled, or until finish is closed.
+func newRunningTestServer(t *testing.T, finish chan struct{}, cancelled *int32) *httptest.Server {
+	countCancels := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method == "DELETE" {
+			atomic.AddInt32(cancelled, 1)
+		}
+		return false
+	}
+	return newPagedResultTestServer(t, nil, nil, countCancels, func(w http.ResponseWriter, r *http.Request) bool {
+		if testPage(r) == 0 {
+			return false
+		}
+		resp := &stmtResponse{
+			ID:      testQueryID,
+			NextURI: testPageURI("http://"+r.Host, 1),
+			Stats:   stmtStats{State: "RUNNING"},
+		}
+		if atomic.LoadInt32(cancelled) > 0 {
+			resp.NextURI = ""
+			resp.Error = stmtError{ErrorName: "USER_CANCELLED"}
+		} else {
+			select {
+			case <-finish:
+				resp.NextURI = ""
+				resp.Stats.State = "FINISHED"
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+		json.NewEncoder(w).Encode(resp)
+		return true
+	})
+}
+
+func TestShutdownDrain(t *testing.T) {
+	finish := make(chan struct{})
+	var cancelled int32
+	ts := newRunningTestServer(t, finish, &cancelled)
+	db := openTestDB(t, ts)
+
+	done := make(chan error)
+	go func() {
+		_, err := db.Exec("INSERT INTO t SELECT * FROM u")
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(finish)
+	}()
+	require.NoError(t, Shutdown(context.Background(), db))
+	require.NoError(t, <-done)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&cancelled))
+
+	_, err := db.Exec("SELECT 1")
+	assert.Equal(t, ErrShutdown, err)
+}
+
+func TestShutdownCancel(t *testing.T) {
+	var cancelled int32
+	ts := newRunningTestServer(t, make(chan struct{}), &cancelled)
+	connector, err := NewConnector(&Config{ServerURI: ts.URL})
+	require.NoError(t, err)
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	done := make(chan error)
+	go func() {
+		_, err := db.Exec("INSERT INTO t SELECT * FROM u")
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, Shutdown(ctx, db))
+	assert.Equal(t, ErrQueryCancelled, <-done)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&cancelled))
+}
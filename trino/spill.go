@@ -0,0 +1,91 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+)
+
+// defaultSpillBufferBytes is the size of the pages kept in memory when
+// spill_dir is set, unless max_buffered_rows or max_buffered_bytes is.
+const defaultSpillBufferBytes = 64 << 20
+
+// spillFile holds the pages fetched by a prefetcher while its buffer is
+// full, to be decoded again when they are scanned. It is created on the
+// first spilled page, and removed when the rows are closed.
+type spillFile struct {
+	dir    string
+	decode func(r io.Reader, qresp *queryResponse, names []string) error
+	f      *os.File
+	size   int64
+}
+
+// Write appends to the file, implementing the io.Writer interface.
+func (s *spillFile) Write(p []byte) (int, error) {
+	n, err := s.f.Write(p)
+	s.size += int64(n)
+	return n, err
+}
+
+// fetchPage fetches the page at uri like driverRows.requestPage, writing
+// the response to the file while it is decoded. Only the metadata of the
+// response is kept in memory.
+func (s *spillFile) fetchPage(ctx context.Context, qr *driverRows, uri string, columns []string, session http.Header, cookies []*http.Cookie) *prefetchedPage {
+	if s.f == nil {
+		f, err := ioutil.TempFile(s.dir, "trino-spill-")
+		if err != nil {
+			return &prefetchedPage{err: err}
+		}
+		s.f = f
+	}
+	offset := s.size
+	resp, h, status, size, err := qr.requestPage(ctx, uri, columns, session, cookies, s)
+	page := &prefetchedPage{resp: resp, status: status, bytes: size, err: err, header: h}
+	if resp != nil {
+		page.rows = len(resp.Data)
+		resp.Data = nil
+		page.spill = &spilledPage{offset: offset, size: s.size - offset, columns: columns}
+	}
+	return page
+}
+
+// spilledPage is the position of a page in the spill file.
+type spilledPage struct {
+	offset  int64
+	size    int64
+	columns []string // columns the page was decoded with
+}
+
+// load decodes the data of the spilled page again.
+func (s *spillFile) load(page *prefetchedPage) error {
+	var qresp queryResponse
+	if err := s.decode(io.NewSectionReader(s.f, page.spill.offset, page.spill.size), &qresp, page.spill.columns); err != nil {
+		return err
+	}
+	page.resp.Data = qresp.Data
+	return nil
+}
+
+// remove closes and removes the file, if any.
+func (s *spillFile) remove() {
+	if s.f != nil {
+		s.f.Close()
+		os.Remove(s.f.Name())
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpill(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trino-spill-test")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+	var requested int32
+	ts := newPagedResultTestServer(t, integerPages(5), nil, countPages(&requested))
+	db, err := sql.Open("trino", ts.URL+"?max_buffered_rows=2&spill_dir="+dir)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	rows, err := db.Query("SELECT n FROM t")
+	require.NoError(t, err)
+	require.True(t, rows.Next())
+
+	// pages beyond the buffer are spilled rather than waiting to be scanned
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&requested) == 5
+	}, time.Second, 10*time.Millisecond)
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.True(t, files[0].Size() > 0)
+
+	var values []int
+	for ok := true; ok; ok = rows.Next() {
+		var n int
+		require.NoError(t, rows.Scan(&n))
+		values = append(values, n)
+	}
+	require.NoError(t, rows.Err())
+	require.NoError(t, rows.Close())
+	assert.Equal(t, []int{2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, values)
+
+	files, err = ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestSpillDirInvalid(t *testing.T) {
+	_, err := sql.Open("trino", "http://localhost:8080?spill_dir=/nonexistent")
+	assert.EqualError(t, err, `trino: invalid spill_dir: "/nonexistent"`)
+}
@@ -0,0 +1,84 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+type sqlCommentKey struct{}
+
+// WithSQLComment returns a context that adds the tag key=value to the
+// comment prefixing the statements executed with it, in addition to the
+// tags of the parent context and of Config.SQLCommentTags, replacing the
+// one with the same key, if any, e.g. to pass the route of the request
+// being served, or its trace context as traceparent.
+//
+// Unlike client tags, which are only seen by resource groups and event
+// listeners, comments are part of the text of the statement, and show up
+// in the query logs and the web UI of Trino.
+func WithSQLComment(ctx context.Context, key, value string) context.Context {
+	tags := make(map[string]string)
+	if parent, ok := ctx.Value(sqlCommentKey{}).(map[string]string); ok {
+		for k, v := range parent {
+			tags[k] = v
+		}
+	}
+	tags[key] = value
+	return context.WithValue(ctx, sqlCommentKey{}, tags)
+}
+
+// addSQLComment prefixes the statement with the tags of the connection and
+// of the context, in the sqlcommenter format, e.g.
+// /*action='list',service='billing'*/ SELECT ...
+func (c *Conn) addSQLComment(ctx context.Context, query string) string {
+	tags, _ := ctx.Value(sqlCommentKey{}).(map[string]string)
+	if len(c.sqlCommentTags) == 0 && len(tags) == 0 {
+		return query
+	}
+	merged := make(map[string]string, len(c.sqlCommentTags)+len(tags))
+	for k, v := range c.sqlCommentTags {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return formatSQLComment(merged) + " " + query
+}
+
+// formatSQLComment returns the comment holding the tags, sorted by key,
+// with keys and values URL-encoded and values quoted, as specified by
+// https://google.github.io/sqlcommenter/spec/.
+func formatSQLComment(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = sqlCommentEscape(k) + "='" + sqlCommentEscape(tags[k]) + "'"
+	}
+	return "/*" + strings.Join(pairs, ",") + "*/"
+}
+
+// sqlCommentEscape URL-encodes s, which also escapes the quotes and the
+// end of comment sequence.
+func sqlCommentEscape(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
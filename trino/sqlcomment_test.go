@@ -0,0 +1,66 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatSQLComment(t *testing.T) {
+	assert.Equal(t, "/*action='run%20report',framework='net%2Fhttp',traceparent='00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01'*/",
+		formatSQLComment(map[string]string{
+			"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+			"framework":   "net/http",
+			"action":      "run report",
+		}))
+	assert.Equal(t, "/*name='it%27s%2A%2F'*/", formatSQLComment(map[string]string{"name": "it's*/"}))
+}
+
+func TestSQLCommentTags(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"columns": [{"name": "_col0", "type": "integer"}], "data": [[1]]`, &queries)
+	connector, err := NewConnector(&Config{
+		ServerURI:      ts.URL,
+		SQLCommentTags: map[string]string{"service": "billing", "route": "/"},
+	})
+	require.NoError(t, err)
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	ctx := WithSQLComment(context.Background(), "route", "/invoices")
+	ctx = WithSQLComment(ctx, "traceparent", "00-abc-def-01")
+	var n int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT 1").Scan(&n))
+	require.NoError(t, db.QueryRow("SELECT ?", 2).Scan(&n))
+	assert.Equal(t, []string{
+		"/*route='%2Finvoices',service='billing',traceparent='00-abc-def-01'*/ SELECT 1",
+		"EXECUTE _trino_go USING 2",
+	}, queries)
+}
+
+func TestWithSQLCommentDoesNotChangeParent(t *testing.T) {
+	parent := WithSQLComment(context.Background(), "route", "/a")
+	WithSQLComment(parent, "route", "/b")
+	c := &Conn{}
+	assert.Equal(t, "/*route='%2Fa'*/ SELECT 1", c.addSQLComment(parent, "SELECT 1"))
+	assert.Equal(t, "SELECT 1", c.addSQLComment(context.Background(), "SELECT 1"))
+}
@@ -0,0 +1,71 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+)
+
+// TableStatistics holds the statistics of a table, as reported by SHOW STATS.
+// Statistics are estimates and may be missing, depending on the connector.
+type TableStatistics struct {
+	RowCount sql.NullFloat64
+	Columns  []ColumnStatistics
+}
+
+// ColumnStatistics holds the statistics of a single column.
+type ColumnStatistics struct {
+	Name           string
+	DataSize       sql.NullFloat64
+	DistinctValues sql.NullFloat64
+	NullsFraction  sql.NullFloat64
+	Low            sql.NullString
+	High           sql.NullString
+}
+
+// TableStats returns the statistics of a table, optionally restricted to
+// the rows matching a filter, such as "ds = DATE '2021-01-01'".
+//
+// The table name is used as is, so it must be quoted if needed, using
+// QuoteIdentifier or QuoteQualifiedName.
+func TableStats(ctx context.Context, q Queryer, table, where string) (*TableStatistics, error) {
+	query := "SHOW STATS FOR " + table
+	if where != "" {
+		query = "SHOW STATS FOR (SELECT * FROM " + table + " WHERE " + where + ")"
+	}
+	rows, err := q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	stats := &TableStatistics{}
+	for rows.Next() {
+		var name sql.NullString
+		var c ColumnStatistics
+		var rowCount sql.NullFloat64
+		if err := rows.Scan(&name, &c.DataSize, &c.DistinctValues, &c.NullsFraction, &rowCount, &c.Low, &c.High); err != nil {
+			return nil, err
+		}
+		// the summary row has no column name, and holds the row count
+		if !name.Valid {
+			stats.RowCount = rowCount
+			continue
+		}
+		c.Name = name.String
+		stats.Columns = append(stats.Columns, c)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,60 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableStats(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `
+		"columns": [
+			{"name": "column_name", "type": "varchar"},
+			{"name": "data_size", "type": "double"},
+			{"name": "distinct_values_count", "type": "double"},
+			{"name": "nulls_fraction", "type": "double"},
+			{"name": "row_count", "type": "double"},
+			{"name": "low_value", "type": "varchar"},
+			{"name": "high_value", "type": "varchar"}
+		],
+		"data": [
+			["id", null, 100.0, 0.0, null, "1", "100"],
+			["name", 1200.0, 90.0, 0.1, null, null, null],
+			[null, null, null, null, 100.0, null, null]
+		]`, &queries)
+	db := openTestDB(t, ts)
+
+	stats, err := TableStats(context.Background(), db, `"hive"."default"."users"`, "id > 0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{`SHOW STATS FOR (SELECT * FROM "hive"."default"."users" WHERE id > 0)`}, queries)
+	assert.Equal(t, sql.NullFloat64{Float64: 100, Valid: true}, stats.RowCount)
+	require.Len(t, stats.Columns, 2)
+	assert.Equal(t, ColumnStatistics{
+		Name:           "id",
+		DistinctValues: sql.NullFloat64{Float64: 100, Valid: true},
+		NullsFraction:  sql.NullFloat64{Valid: true},
+		Low:            sql.NullString{String: "1", Valid: true},
+		High:           sql.NullString{String: "100", Valid: true},
+	}, stats.Columns[0])
+	assert.Equal(t, "name", stats.Columns[1].Name)
+	assert.Equal(t, sql.NullFloat64{Float64: 1200, Valid: true}, stats.Columns[1].DataSize)
+	assert.False(t, stats.Columns[1].Low.Valid)
+}
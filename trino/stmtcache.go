@@ -0,0 +1,65 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"container/list"
+	"net/url"
+)
+
+// stmtCache is a least recently used cache of the prepared statements of a
+// connection, keyed by their SQL text. Statements are not registered in
+// Trino, but sent with each execution, so caching them saves preparing and
+// encoding the statement again when an application prepares the same SQL
+// repeatedly, as many ORMs do.
+type stmtCache struct {
+	size  int
+	order *list.List               // most recently used first
+	stmts map[string]*list.Element // values are *driverStmt
+}
+
+func newStmtCache(size int) *stmtCache {
+	return &stmtCache{
+		size:  size,
+		order: list.New(),
+		stmts: make(map[string]*list.Element),
+	}
+}
+
+// get returns the cached statement for the query, preparing and caching it
+// if needed.
+func (c *stmtCache) get(conn *Conn, query string) *driverStmt {
+	if e, ok := c.stmts[query]; ok {
+		c.order.MoveToFront(e)
+		return e.Value.(*driverStmt)
+	}
+	st := &driverStmt{conn: conn, query: query}
+	c.stmts[query] = c.order.PushFront(st)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.stmts, oldest.Value.(*driverStmt).query)
+	}
+	return st
+}
+
+// preparedStatement returns the value of the prepared statement header
+// for the statement, encoded once.
+func (st *driverStmt) preparedStatement() string {
+	if st.encoded == "" {
+		st.encoded = preparedStatementName + "=" + url.QueryEscape(st.query)
+	}
+	return st.encoded
+}
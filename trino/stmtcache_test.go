@@ -0,0 +1,49 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStmtCache(t *testing.T) {
+	c, err := newConn("http://foobar@localhost:8080?stmt_cache_size=2")
+	require.NoError(t, err)
+
+	prepare := func(query string) *driverStmt {
+		st, err := c.PrepareContext(context.Background(), query)
+		require.NoError(t, err)
+		return st.(*driverStmt)
+	}
+	a := prepare("SELECT ?")
+	assert.Same(t, a, prepare("SELECT ?"))
+	b := prepare("SELECT ?, ?")
+	assert.Same(t, a, prepare("SELECT ?"))
+	prepare("SELECT ?, ?, ?") // evicts the least recently used one
+	assert.Same(t, a, prepare("SELECT ?"))
+	assert.NotSame(t, b, prepare("SELECT ?, ?"))
+	assert.Equal(t, "_trino_go=SELECT+%3F", a.preparedStatement())
+
+	c, err = newConn("http://foobar@localhost:8080")
+	require.NoError(t, err)
+	assert.NotSame(t, prepare("SELECT ?"), prepare("SELECT ?"))
+
+	_, err = newConn("http://foobar@localhost:8080?stmt_cache_size=-1")
+	assert.EqualError(t, err, `trino: invalid stmt_cache_size: "-1"`)
+}
@@ -0,0 +1,89 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type tagsKey struct{}
+
+// contextTags holds the client tags set on a context, or the error of the
+// first invalid one.
+type contextTags struct {
+	keys []string
+	tags map[string]string // key=value, by key
+	err  error
+}
+
+// WithTag returns a context that adds the client tag key=value to the
+// queries executed with it, in addition to the tags of the parent context,
+// replacing the one with the same key, if any. The tag is just key when the
+// value is empty.
+//
+// Client tags are sent in the X-Trino-Client-Tags header, and can be used
+// by resource groups to select queries, e.g. to attribute them to the
+// request or the team they are made for. Unlike headers set with named
+// arguments, tags set on the context apply to queries made by libraries that
+// do not give access to their arguments, such as ORMs.
+func WithTag(ctx context.Context, key, value string) context.Context {
+	tags := contextTags{tags: make(map[string]string)}
+	if parent, ok := ctx.Value(tagsKey{}).(*contextTags); ok {
+		tags.keys = append(tags.keys, parent.keys...)
+		for k, v := range parent.tags {
+			tags.tags[k] = v
+		}
+		tags.err = parent.err
+	}
+	tag := key
+	if value != "" {
+		tag = key + "=" + value
+	}
+	if tags.err == nil && (key == "" || strings.ContainsAny(tag, ",") || strings.Contains(key, "=")) {
+		tags.err = fmt.Errorf("trino: invalid client tag %q", tag)
+	}
+	if _, ok := tags.tags[key]; !ok {
+		tags.keys = append(tags.keys, key)
+	}
+	tags.tags[key] = tag
+	return context.WithValue(ctx, tagsKey{}, &tags)
+}
+
+// addContextTags adds the client tags set on the context to the request
+// headers, after the ones set with arguments.
+func addContextTags(ctx context.Context, hs http.Header) (http.Header, error) {
+	tags, ok := ctx.Value(tagsKey{}).(*contextTags)
+	if !ok {
+		return hs, nil
+	}
+	if tags.err != nil {
+		return nil, tags.err
+	}
+	if hs == nil {
+		hs = make(http.Header)
+	}
+	values := make([]string, 0, len(tags.keys)+1)
+	if v := hs.Get(trinoClientTagsHeader); v != "" {
+		values = append(values, v)
+	}
+	for _, key := range tags.keys {
+		values = append(values, tags.tags[key])
+	}
+	hs.Set(trinoClientTagsHeader, strings.Join(values, ","))
+	return hs, nil
+}
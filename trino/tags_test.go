@@ -0,0 +1,49 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTag(t *testing.T) {
+	var headers http.Header
+	ts := newHeaderTestServer(t, `"columns": [{"name": "_col0", "type": "bigint"}], "data": [[1]]`, &headers)
+	db := openTestDB(t, ts)
+
+	ctx := WithTag(context.Background(), "team", "search")
+	ctx = WithTag(ctx, "request", "r1")
+	ctx = WithTag(ctx, "batch", "")
+	ctx = WithTag(ctx, "request", "r2")
+
+	var n int64
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT 1").Scan(&n))
+	assert.Equal(t, "team=search,request=r2,batch", headers.Get(trinoClientTagsHeader))
+
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT 1", sql.Named(trinoClientTagsHeader, "adhoc")).Scan(&n))
+	assert.Equal(t, "adhoc,team=search,request=r2,batch", headers.Get(trinoClientTagsHeader))
+
+	require.NoError(t, db.QueryRow("SELECT 1").Scan(&n))
+	assert.Empty(t, headers.Get(trinoClientTagsHeader))
+
+	err := db.QueryRowContext(WithTag(ctx, "a,b", "c"), "SELECT 1").Scan(&n)
+	assert.EqualError(t, err, `trino: invalid client tag "a,b=c"`)
+}
@@ -0,0 +1,113 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// TimeoutKind tells which limit an ErrTimeout exceeded.
+type TimeoutKind string
+
+// Kinds of ErrTimeout: network timeouts, set by the transport parameters
+// of the DSN, and deadlines of the context of a query, told apart by the
+// state of the query.
+const (
+	// TimeoutDial is the failure to connect within dial_timeout.
+	TimeoutDial TimeoutKind = "dial"
+	// TimeoutTLSHandshake is the failure to complete the TLS handshake
+	// within tls_handshake_timeout.
+	TimeoutTLSHandshake TimeoutKind = "TLS handshake"
+	// TimeoutResponseHeader is the failure to receive the headers of a
+	// response within response_header_timeout.
+	TimeoutResponseHeader TimeoutKind = "response header"
+	// TimeoutQueued is the deadline of the context of a query exceeded
+	// while the query was waiting in a queue of Trino.
+	TimeoutQueued TimeoutKind = "queued"
+	// TimeoutExecution is the deadline of the context of a query exceeded
+	// while the query was running, or its results were read.
+	TimeoutExecution TimeoutKind = "execution"
+)
+
+// ErrTimeout is returned, wrapped in the errors of the driver, when a
+// request to Trino or a query times out. Use errors.As and its Kind to
+// tell network issues from slow queries. Errors of the TimeoutQueued and
+// TimeoutExecution kinds always match context.DeadlineExceeded with
+// errors.Is, as they are caused by the context of the query, while the
+// network timeouts of some Go versions also do.
+type ErrTimeout struct {
+	Kind    TimeoutKind
+	QueryID string // ID of the query, if it was submitted
+	State   string // State of the query when the deadline was exceeded, e.g. QUEUED or RUNNING
+	Err     error
+}
+
+// Error implements the error interface.
+func (e *ErrTimeout) Error() string {
+	return fmt.Sprintf("trino: %s timeout: %v", e.Kind, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ErrTimeout) Unwrap() error {
+	return e.Err
+}
+
+// queuedStates are the states of queries that did not start to run.
+var queuedStates = map[string]bool{
+	"":                      true,
+	"QUEUED":                true,
+	"WAITING_FOR_RESOURCES": true,
+	"DISPATCHING":           true,
+}
+
+// networkTimeout returns the failure of a request as an *ErrTimeout if it
+// exceeded one of the timeouts of the transport.
+func networkTimeout(err error) error {
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		return err
+	}
+	var opErr *net.OpError
+	switch {
+	case errors.As(err, &opErr) && opErr.Op == "dial":
+		return &ErrTimeout{Kind: TimeoutDial, Err: err}
+	case strings.Contains(err.Error(), "TLS handshake timeout"):
+		return &ErrTimeout{Kind: TimeoutTLSHandshake, Err: err}
+	case strings.Contains(err.Error(), "timeout awaiting response headers"):
+		return &ErrTimeout{Kind: TimeoutResponseHeader, Err: err}
+	}
+	return err
+}
+
+// queryTimeout returns err as an *ErrTimeout if the deadline of the
+// context of the query was exceeded.
+func (qr *driverRows) queryTimeout(err error) error {
+	if qr.ctx.Err() != context.DeadlineExceeded || !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	var terr *ErrTimeout
+	if errors.As(err, &terr) {
+		return err
+	}
+	kind := TimeoutExecution
+	if queuedStates[qr.stats.State] {
+		kind = TimeoutQueued
+	}
+	return &ErrTimeout{Kind: kind, QueryID: qr.queryID, State: qr.stats.State, Err: err}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStateTestServer returns a server reporting queries in the given state
+// forever.
+func newStateTestServer(t *testing.T, state string) *httptest.Server {
+	return newPagedResultTestServer(t, nil, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if testPage(r) == 0 {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+		writeTestPage(w, "http://"+r.Host, `"stats": {"state": "`+state+`"}`, 1)
+		return true
+	})
+}
+
+func TestQueryTimeoutKinds(t *testing.T) {
+	for _, tc := range []struct {
+		state string
+		kind  TimeoutKind
+	}{
+		{"QUEUED", TimeoutQueued},
+		{"WAITING_FOR_RESOURCES", TimeoutQueued},
+		{"RUNNING", TimeoutExecution},
+	} {
+		t.Run(tc.state, func(t *testing.T) {
+			db := openTestDB(t, newStateTestServer(t, tc.state))
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			_, err := db.QueryContext(ctx, "SELECT 1")
+			require.Error(t, err)
+
+			var terr *ErrTimeout
+			require.True(t, errors.As(err, &terr), "unexpected error: %v", err)
+			assert.Equal(t, tc.kind, terr.Kind)
+			assert.Equal(t, tc.state, terr.State)
+			assert.Equal(t, "20210101_000000_00000_abcde", terr.QueryID)
+			assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		})
+	}
+}
+
+func TestCancelledQueryIsNotTimeout(t *testing.T) {
+	db := openTestDB(t, newStateTestServer(t, "RUNNING"))
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err := db.QueryContext(ctx, "SELECT 1")
+	require.Error(t, err)
+	var terr *ErrTimeout
+	assert.False(t, errors.As(err, &terr), "unexpected error: %v", err)
+}
+
+func TestResponseHeaderTimeoutKind(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	t.Cleanup(ts.Close)
+	db, err := sql.Open("trino", ts.URL+"?response_header_timeout=10ms")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	_, err = db.Exec("SELECT 1")
+	var terr *ErrTimeout
+	require.True(t, errors.As(err, &terr), "unexpected error: %v", err)
+	assert.Equal(t, TimeoutResponseHeader, terr.Kind)
+	assert.Contains(t, err.Error(), "response header timeout")
+}
+
+// timeoutError is a net.Error that timed out.
+type timeoutError struct{ msg string }
+
+func (e timeoutError) Error() string   { return e.msg }
+func (e timeoutError) Timeout() bool   { return true }
+func (e timeoutError) Temporary() bool { return true }
+
+func TestNetworkTimeout(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		kind TimeoutKind
+	}{
+		{&url.Error{Op: "Post", URL: "http://localhost", Err: &net.OpError{Op: "dial", Net: "tcp", Err: timeoutError{"i/o timeout"}}}, TimeoutDial},
+		{&url.Error{Op: "Post", URL: "https://localhost", Err: timeoutError{"net/http: TLS handshake timeout"}}, TimeoutTLSHandshake},
+		{&url.Error{Op: "Get", URL: "http://localhost", Err: timeoutError{"net/http: timeout awaiting response headers"}}, TimeoutResponseHeader},
+	} {
+		var terr *ErrTimeout
+		require.True(t, errors.As(networkTimeout(tc.err), &terr), "%v", tc.err)
+		assert.Equal(t, tc.kind, terr.Kind)
+	}
+	for _, err := range []error{
+		&url.Error{Op: "Get", URL: "http://localhost", Err: context.DeadlineExceeded},
+		&url.Error{Op: "Get", URL: "http://localhost", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}},
+	} {
+		assert.Equal(t, err, networkTimeout(err))
+	}
+}
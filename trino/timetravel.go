@@ -0,0 +1,126 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// TableVersion is the version of a table read by a time travel query, of
+// an Iceberg or Delta Lake catalog. Exactly one of its fields must be set.
+type TableVersion struct {
+	SnapshotID int64     // ID of a snapshot, as listed by ListSnapshots
+	Ref        string    // Name of a branch or tag
+	Timestamp  time.Time // Point in time, reading the snapshot current at that time
+}
+
+// clause returns the FOR VERSION AS OF or FOR TIMESTAMP AS OF clause
+// reading the version.
+func (v TableVersion) clause() (string, error) {
+	set := 0
+	var clause string
+	if v.SnapshotID != 0 {
+		set++
+		clause = "FOR VERSION AS OF " + strconv.FormatInt(v.SnapshotID, 10)
+	}
+	if v.Ref != "" {
+		set++
+		clause = "FOR VERSION AS OF " + QuoteLiteral(v.Ref)
+	}
+	if !v.Timestamp.IsZero() {
+		set++
+		ts, err := FormatValue(v.Timestamp, "timestamp with time zone")
+		if err != nil {
+			return "", err
+		}
+		clause = "FOR TIMESTAMP AS OF " + ts
+	}
+	if set != 1 {
+		return "", errors.New("trino: a table version requires exactly one of a snapshot ID, a ref or a timestamp")
+	}
+	return clause, nil
+}
+
+// TableAsOf returns the reference to a version of a table, such as
+// `"events" FOR VERSION AS OF 8954597067493422955`, to use in the FROM
+// clause of a query.
+//
+// The table name is used as is, so it must be quoted if needed, using
+// QuoteIdentifier or QuoteQualifiedName.
+func TableAsOf(table string, version TableVersion) (string, error) {
+	clause, err := version.clause()
+	if err != nil {
+		return "", err
+	}
+	return table + " " + clause, nil
+}
+
+// QueryAsOf returns the rows of a version of a table, optionally restricted
+// to the rows matching a filter, such as "ds = ?", whose parameters are
+// given by args. Reading a snapshot, rather than the current version of the
+// table, makes the results reproducible while the table is written to.
+//
+// The table name and the filter are used as is, so they must be quoted if
+// needed.
+func QueryAsOf(ctx context.Context, q Queryer, table string, version TableVersion, where string, args ...interface{}) (*sql.Rows, error) {
+	from, err := TableAsOf(table, version)
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT * FROM " + from
+	if where != "" {
+		query += " WHERE " + where
+	}
+	return q.QueryContext(ctx, query, args...)
+}
+
+// Snapshot describes a snapshot of an Iceberg table, as listed by
+// ListSnapshots.
+type Snapshot struct {
+	SnapshotID   int64
+	ParentID     sql.NullInt64 // Null for the first snapshot
+	CommittedAt  time.Time
+	Operation    string            // e.g. "append", "overwrite" or "delete"
+	ManifestList string            // Location of the manifest list
+	Summary      map[string]string // e.g. the number of added records
+}
+
+// ListSnapshots returns the snapshots of an Iceberg table, from the oldest,
+// read from its $snapshots metadata table.
+func ListSnapshots(ctx context.Context, q Queryer, catalog, schema, table string) ([]Snapshot, error) {
+	rows, err := q.QueryContext(ctx, "SELECT snapshot_id, parent_id, committed_at, operation, manifest_list, summary"+
+		" FROM "+QuoteQualifiedName(catalog, schema, table+"$snapshots")+
+		" ORDER BY committed_at, snapshot_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var snapshots []Snapshot
+	for rows.Next() {
+		var s Snapshot
+		var operation, manifestList sql.NullString
+		if err := rows.Scan(&s.SnapshotID, &s.ParentID, &s.CommittedAt, &operation, &manifestList, ScanMap(&s.Summary)); err != nil {
+			return nil, err
+		}
+		s.Operation = operation.String
+		s.ManifestList = manifestList.String
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
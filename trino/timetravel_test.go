@@ -0,0 +1,98 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableAsOf(t *testing.T) {
+	table := QuoteQualifiedName("iceberg", "web", "events")
+	for _, tc := range []struct {
+		version TableVersion
+		want    string
+	}{
+		{TableVersion{SnapshotID: 8954597067493422955}, `"iceberg"."web"."events" FOR VERSION AS OF 8954597067493422955`},
+		{TableVersion{Ref: "audit's"}, `"iceberg"."web"."events" FOR VERSION AS OF 'audit''s'`},
+		{
+			TableVersion{Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 600000000, time.UTC)},
+			`"iceberg"."web"."events" FOR TIMESTAMP AS OF TIMESTAMP '2024-01-02 03:04:05.6 +00:00'`,
+		},
+	} {
+		got, err := TableAsOf(table, tc.version)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, got)
+	}
+
+	_, err := TableAsOf(table, TableVersion{})
+	assert.Error(t, err)
+	_, err = TableAsOf(table, TableVersion{SnapshotID: 1, Ref: "main"})
+	assert.Error(t, err)
+}
+
+func TestQueryAsOf(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"columns": [{"name": "id", "type": "bigint"}], "data": [[1], [2]]`, &queries)
+	db := openTestDB(t, ts)
+
+	rows, err := QueryAsOf(context.Background(), db, QuoteIdentifier("events"), TableVersion{SnapshotID: 42}, "id > 0")
+	require.NoError(t, err)
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		require.NoError(t, rows.Scan(&id))
+		ids = append(ids, id)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []int64{1, 2}, ids)
+	assert.Equal(t, []string{`SELECT * FROM "events" FOR VERSION AS OF 42 WHERE id > 0`}, queries)
+}
+
+func TestListSnapshots(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `
+		"columns": [
+			{"name": "snapshot_id", "type": "bigint"},
+			{"name": "parent_id", "type": "bigint"},
+			{"name": "committed_at", "type": "timestamp(3) with time zone"},
+			{"name": "operation", "type": "varchar"},
+			{"name": "manifest_list", "type": "varchar"},
+			{"name": "summary", "type": "map(varchar, varchar)"}
+		],
+		"data": [
+			[1, null, "2024-01-01 00:00:00.000 UTC", "append", "s3://bucket/snap-1.avro", {"added-records": "10"}],
+			[2, 1, "2024-01-02 00:00:00.000 UTC", "overwrite", "s3://bucket/snap-2.avro", {}]
+		]`, &queries)
+	db := openTestDB(t, ts)
+
+	snapshots, err := ListSnapshots(context.Background(), db, "iceberg", "web", "events")
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, int64(1), snapshots[0].SnapshotID)
+	assert.False(t, snapshots[0].ParentID.Valid)
+	assert.True(t, snapshots[0].CommittedAt.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "append", snapshots[0].Operation)
+	assert.Equal(t, map[string]string{"added-records": "10"}, snapshots[0].Summary)
+	assert.Equal(t, sql.NullInt64{Int64: 1, Valid: true}, snapshots[1].ParentID)
+	assert.Equal(t, "overwrite", snapshots[1].Operation)
+	assert.Equal(t, []string{`SELECT snapshot_id, parent_id, committed_at, operation, manifest_list, summary` +
+		` FROM "iceberg"."web"."events$snapshots" ORDER BY committed_at, snapshot_id`}, queries)
+}
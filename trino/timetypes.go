@@ -0,0 +1,149 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"fmt"
+	"time"
+)
+
+// loadLocation loads a time zone by name, such as America/New_York, or
+// creates a fixed zone from a numeric offset, such as +01:00.
+func loadLocation(name string) (*time.Location, error) {
+	if len(name) > 0 && (name[0] == '+' || name[0] == '-') {
+		t, err := time.Parse("-07:00", name)
+		if err != nil {
+			return nil, err
+		}
+		_, offset := t.Zone()
+		return time.FixedZone(name, offset), nil
+	}
+	return time.LoadLocation(name)
+}
+
+// scanTime converts the values of Trino time types to time.Time, whether
+// they were converted by the driver or not.
+func scanTime(value interface{}, trinoType string) (time.Time, bool, error) {
+	switch v := value.(type) {
+	case nil:
+		return time.Time{}, false, nil
+	case time.Time:
+		return v, true, nil
+	case NullTime:
+		return v.Time, v.Valid, nil
+	case string:
+		vv, err := scanNullTime(v)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("trino: cannot convert %q to %s: %v", v, trinoType, err)
+		}
+		return vv.Time, vv.Valid, nil
+	}
+	return time.Time{}, false, fmt.Errorf("trino: cannot convert %v (%T) to %s", value, value, trinoType)
+}
+
+// wallClock returns the time with the same date and clock reading in UTC,
+// for the Trino types without time zone.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// NullDate represents a Trino date that may be null.
+// Time is midnight UTC of the date, as dates have no time zone.
+type NullDate struct {
+	Time  time.Time
+	Valid bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (s *NullDate) Scan(value interface{}) error {
+	t, valid, err := scanTime(value, "date")
+	if err != nil {
+		return err
+	}
+	t = wallClock(t)
+	s.Time = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	s.Valid = valid
+	return nil
+}
+
+// NullTimeOfDay represents a Trino time, with or without time zone, that
+// may be null. Time is the clock reading on January 1 of year 0, in the
+// time zone of the value, or in UTC if the value has none.
+type NullTimeOfDay struct {
+	Time    time.Time
+	HasZone bool
+	Valid   bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (s *NullTimeOfDay) Scan(value interface{}) error {
+	t, valid, err := scanTime(value, "time")
+	if err != nil {
+		return err
+	}
+	s.HasZone = t.Location() != time.Local
+	if !s.HasZone {
+		t = wallClock(t)
+	}
+	s.Time = time.Date(0, 1, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	s.Valid = valid
+	return nil
+}
+
+// NullTimestamp represents a Trino timestamp without time zone that may be
+// null. Time holds the date and clock reading, with nanosecond precision,
+// in UTC, so that it does not depend on the local time zone.
+type NullTimestamp struct {
+	Time  time.Time
+	Valid bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (s *NullTimestamp) Scan(value interface{}) error {
+	t, valid, err := scanTime(value, "timestamp")
+	if err != nil {
+		return err
+	}
+	s.Time = wallClock(t)
+	s.Valid = valid
+	return nil
+}
+
+// NullTimestampTZ represents a Trino timestamp with time zone that may be
+// null. Time is in the time zone of the value, whose name, such as
+// America/New_York or +01:00, is in Zone.
+type NullTimestampTZ struct {
+	Time  time.Time
+	Zone  string
+	Valid bool
+}
+
+// Scan implements the sql.Scanner interface.
+func (s *NullTimestampTZ) Scan(value interface{}) error {
+	t, valid, err := scanTime(value, "timestamp with time zone")
+	if err != nil {
+		return err
+	}
+	if valid && t.Location() == time.Local {
+		return fmt.Errorf("trino: cannot convert %v to timestamp with time zone: missing time zone", t)
+	}
+	s.Time = t
+	s.Zone = ""
+	if valid {
+		s.Zone = t.Location().String()
+	}
+	s.Valid = valid
+	return nil
+}
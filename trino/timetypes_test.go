@@ -0,0 +1,157 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimePrecision(t *testing.T) {
+	for _, tc := range []struct {
+		DataType string
+		Sample   string
+		Expected time.Time
+	}{
+		{"timestamp(6)", "2017-07-10 01:02:03.123456", time.Date(2017, 7, 10, 1, 2, 3, 123456000, time.Local)},
+		{"timestamp(12)", "2017-07-10 01:02:03.123456789012", time.Date(2017, 7, 10, 1, 2, 3, 123456789, time.Local)},
+		{"timestamp(0)", "2017-07-10 01:02:03", time.Date(2017, 7, 10, 1, 2, 3, 0, time.Local)},
+		{"time(9)", "01:02:03.123456789", time.Date(0, 1, 1, 1, 2, 3, 123456789, time.Local)},
+	} {
+		t.Run(tc.DataType, func(t *testing.T) {
+			v, err := newTypeConverter(tc.DataType).ConvertValue(tc.Sample)
+			require.NoError(t, err)
+			assert.Equal(t, tc.Expected, v)
+		})
+	}
+}
+
+func TestTimeOffsets(t *testing.T) {
+	v, err := newTypeConverter("timestamp(3) with time zone").ConvertValue("2017-07-10 01:02:03.000 +01:00")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2017, 7, 10, 0, 2, 3, 0, time.UTC).Equal(v.(time.Time)))
+	assert.Equal(t, "+01:00", v.(time.Time).Location().String())
+
+	v, err = newTypeConverter("time(3) with time zone").ConvertValue("01:02:03.456+01:00")
+	require.NoError(t, err)
+	_, offset := v.(time.Time).Zone()
+	assert.Equal(t, 3600, offset)
+}
+
+func TestNullTimeVariants(t *testing.T) {
+	local := time.Date(2017, 7, 10, 1, 2, 3, 123456789, time.Local)
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	var date NullDate
+	require.NoError(t, date.Scan(local))
+	assert.Equal(t, NullDate{Time: time.Date(2017, 7, 10, 0, 0, 0, 0, time.UTC), Valid: true}, date)
+
+	var ts NullTimestamp
+	require.NoError(t, ts.Scan(local))
+	assert.Equal(t, NullTimestamp{Time: time.Date(2017, 7, 10, 1, 2, 3, 123456789, time.UTC), Valid: true}, ts)
+
+	var tod NullTimeOfDay
+	require.NoError(t, tod.Scan("01:02:03.5+01:00"))
+	assert.True(t, tod.Valid)
+	assert.True(t, tod.HasZone)
+	assert.Equal(t, 500000000, tod.Time.Nanosecond())
+
+	var tstz NullTimestampTZ
+	require.NoError(t, tstz.Scan(time.Date(2017, 7, 10, 1, 2, 3, 0, ny)))
+	assert.Equal(t, "America/New_York", tstz.Zone)
+	assert.Error(t, tstz.Scan(local))
+
+	require.NoError(t, tstz.Scan(nil))
+	assert.False(t, tstz.Valid)
+	assert.Error(t, date.Scan(42))
+}
+
+func TestLocation(t *testing.T) {
+	ts := newResultTestServer(t, `
+		"columns": [
+			{"name": "ts", "type": "timestamp(3)"},
+			{"name": "tstz", "type": "timestamp(3) with time zone"}
+		],
+		"data": [["2021-01-02 03:04:05.000", "2021-01-02 03:04:05.000 UTC"]]`, nil)
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	connector, err := NewConnector(&Config{ServerURI: ts.URL, Location: paris})
+	require.NoError(t, err)
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	var ts1, ts2 time.Time
+	require.NoError(t, db.QueryRow("SELECT *").Scan(&ts1, &ts2))
+	assert.Equal(t, time.Date(2021, 1, 2, 3, 4, 5, 0, paris), ts1)
+	assert.Equal(t, "Europe/Paris", ts1.Location().String())
+	assert.Equal(t, "UTC", ts2.Location().String())
+}
+
+func TestLocationFixedZone(t *testing.T) {
+	ts := newResultTestServer(t, `
+		"columns": [{"name": "ts", "type": "timestamp(3)"}],
+		"data": [["2021-07-02 03:04:05.000"]]`, nil)
+	for _, loc := range []*time.Location{time.FixedZone("", 3600), time.FixedZone("CET", 3600), time.FixedZone("XYZ", 5400)} {
+		connector, err := NewConnector(&Config{ServerURI: ts.URL, Location: loc})
+		require.NoError(t, err)
+		db := sql.OpenDB(connector)
+
+		var v time.Time
+		require.NoError(t, db.QueryRow("SELECT *").Scan(&v))
+		assert.Equal(t, time.Date(2021, 7, 2, 3, 4, 5, 0, loc), v)
+		assert.Equal(t, loc, v.Location())
+		assert.NoError(t, db.Close())
+	}
+}
+
+func TestLocationDSN(t *testing.T) {
+	c := &Config{ServerURI: "http://foobar@localhost:8080", Location: time.UTC}
+	dsn, err := c.FormatDSN()
+	require.NoError(t, err)
+	assert.Equal(t, "http://foobar@localhost:8080?location=UTC&source=trino-go-client", dsn)
+
+	_, err = newConn("http://foobar@localhost:8080?location=Mars%2FOlympus")
+	assert.EqualError(t, err, `trino: invalid location: "Mars/Olympus"`)
+}
+
+func TestTimeZone(t *testing.T) {
+	var headers http.Header
+	ts := newHeaderTestServer(t, `
+		"columns": [{"name": "ts", "type": "timestamp(3)"}],
+		"data": [["2021-01-02 03:04:05.000"]]`, &headers)
+	db, err := sql.Open("trino", ts.URL+"?time_zone=%2B05:30")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	var v time.Time
+	require.NoError(t, db.QueryRow("SELECT localtimestamp").Scan(&v))
+	assert.Equal(t, "+05:30", headers.Get(trinoTimeZoneHeader))
+	_, offset := v.Zone()
+	assert.Equal(t, 5*3600+30*60, offset)
+	assert.Equal(t, "2021-01-02T03:04:05+05:30", v.Format(time.RFC3339))
+
+	_, err = newConn("http://foobar@localhost:8080?time_zone=Mars%2FOlympus")
+	assert.EqualError(t, err, `trino: invalid time_zone: "Mars/Olympus"`)
+}
@@ -0,0 +1,113 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// transportTimeouts are the DSN parameters setting the timeouts of the
+// connection establishment, as opposed to the timeout of the query, and
+// the time the addresses of hosts are cached.
+var transportTimeouts = []string{
+	"dial_timeout",
+	"tls_handshake_timeout",
+	"response_header_timeout",
+	"dns_cache_ttl",
+	"fallback_delay",
+}
+
+// withTransportTimeouts returns a client using a copy of the transport of
+// the given client, with the timeouts, DNS cache and IP family set in the
+// DSN, shared by the connections using the same transport and options,
+// or the given client if the DSN sets none of them. The shared transport
+// is to be released with releaseTransport once unused.
+func withTransportTimeouts(client *http.Client, query url.Values) (*http.Client, error) {
+	timeouts := make(map[string]time.Duration)
+	for _, name := range transportTimeouts {
+		v := query.Get(name)
+		if v == "" {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, invalidParameter(name, v)
+		}
+		timeouts[name] = d
+	}
+	family := query.Get("ip_family")
+	if family != "" && !validIPFamily(family) {
+		return nil, invalidParameter("ip_family", family)
+	}
+	if len(timeouts) == 0 && family == "" {
+		return client, nil
+	}
+
+	rt := client.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	base, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("trino: transport options require an *http.Transport, got %T", rt)
+	}
+	key := fmt.Sprintf("%p %v %s", base, timeouts, family)
+	transport := sharedTransport(key, func() *http.Transport {
+		transport := base.Clone()
+		fallbackDelay, hasFallbackDelay := timeouts["fallback_delay"]
+		preferring := family == PreferIPv4 || family == PreferIPv6
+		if d, ok := timeouts["dial_timeout"]; ok || hasFallbackDelay && !preferring {
+			if !ok {
+				d = 30 * time.Second
+			}
+			transport.DialContext = (&net.Dialer{
+				Timeout:       d,
+				KeepAlive:     30 * time.Second,
+				FallbackDelay: fallbackDelay,
+			}).DialContext
+		}
+		if ttl, ok := timeouts["dns_cache_ttl"]; ok {
+			transport.DialContext = newDNSCache(ttl, dialerOf(transport)).dialContext
+		}
+		if family != "" {
+			if !hasFallbackDelay {
+				fallbackDelay = defaultFallbackDelay
+			}
+			transport.DialContext = dialIPFamily(dialerOf(transport), family, fallbackDelay)
+		}
+		if d, ok := timeouts["tls_handshake_timeout"]; ok {
+			transport.TLSHandshakeTimeout = d
+		}
+		if d, ok := timeouts["response_header_timeout"]; ok {
+			transport.ResponseHeaderTimeout = d
+		}
+		return transport
+	})
+	c := *client
+	c.Transport = transport
+	return &c, nil
+}
+
+// dialerOf returns the dial function of the transport.
+func dialerOf(transport *http.Transport) dialFunc {
+	if transport.DialContext != nil {
+		return transport.DialContext
+	}
+	return (&net.Dialer{}).DialContext
+}
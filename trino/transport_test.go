@@ -0,0 +1,71 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransportTimeouts(t *testing.T) {
+	dsn, err := (&Config{
+		ServerURI:             "http://foobar@localhost:8080",
+		DialTimeout:           5 * time.Second,
+		TLSHandshakeTimeout:   3 * time.Second,
+		ResponseHeaderTimeout: time.Minute,
+	}).FormatDSN()
+	require.NoError(t, err)
+
+	conn, err := newConn(dsn)
+	require.NoError(t, err)
+	transport, ok := conn.httpClient.Transport.(*http.Transport)
+	require.True(t, ok)
+	assert.NotNil(t, transport.DialContext)
+	assert.Equal(t, 3*time.Second, transport.TLSHandshakeTimeout)
+	assert.Equal(t, time.Minute, transport.ResponseHeaderTimeout)
+	assert.Nil(t, http.DefaultClient.Transport, "the default client must not be modified")
+}
+
+func TestInvalidTransportTimeouts(t *testing.T) {
+	for _, param := range []string{"dial_timeout=0s", "tls_handshake_timeout=foo", "response_header_timeout=-1s"} {
+		_, err := newConn("http://localhost:8080?" + param)
+		require.Error(t, err, param)
+		assert.Contains(t, err.Error(), strings.Split(param, "=")[0])
+	}
+}
+
+func TestResponseHeaderTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	t.Cleanup(ts.Close)
+
+	db, err := sql.Open("trino", ts.URL+"?response_header_timeout=10ms")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	_, err = db.Exec("SELECT 1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timeout awaiting response headers")
+}
@@ -0,0 +1,124 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// ErrTransport is the Reason of an ErrQueryFailed caused by a request to
+// Trino that failed, or that returned an unexpected HTTP status, as
+// opposed to a failure reported by Trino in a query response. It allows
+// telling the errors of proxies and load balancers in front of Trino from
+// the errors of the coordinator.
+type ErrTransport struct {
+	Method     string      // Method of the request
+	URI        string      // URI of the request, with credentials masked
+	StatusCode int         // HTTP status of the response, or 0 if none was received
+	Header     http.Header // Response headers listed in TransportErrorHeaders
+	RequestID  string      // ID of the request assigned by a proxy, if any
+	Attempts   int         // Number of attempts, including retries after 503 responses and connection failures
+	Body       string      // Start of the response body, if any
+	Err        error       // Failure of the request, if no response was received
+}
+
+// TransportErrorHeaders are the response headers kept in ErrTransport,
+// identifying the server that returned the response.
+var TransportErrorHeaders = []string{
+	"Content-Type",
+	"Retry-After",
+	"Server",
+	"Via",
+	"X-Amz-Cf-Id",
+	"X-Amzn-Requestid",
+	"X-Amzn-Trace-Id",
+	"X-Correlation-Id",
+	"X-Request-Id",
+}
+
+// requestIDHeaders are the headers the RequestID of ErrTransport is read
+// from, in order of preference.
+var requestIDHeaders = []string{
+	"X-Request-Id",
+	"X-Amzn-Requestid",
+	"X-Correlation-Id",
+	"X-Amz-Cf-Id",
+}
+
+// Error returns the failure of the request, or the body of the response,
+// as reported by Trino before ErrTransport was introduced.
+func (e *ErrTransport) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return e.Body
+}
+
+// Unwrap returns the failure of the request.
+func (e *ErrTransport) Unwrap() error {
+	return e.Err
+}
+
+func newErrTransport(req *http.Request, attempts int) *ErrTransport {
+	return &ErrTransport{
+		Method:   req.Method,
+		URI:      redactDSN(req.URL.String()),
+		Attempts: attempts,
+	}
+}
+
+// newErrQueryFailedFromRequest returns the failure of a request for which
+// no response was received.
+func newErrQueryFailedFromRequest(req *http.Request, attempts int, err error) *ErrQueryFailed {
+	te := newErrTransport(req, attempts)
+	te.Err = networkTimeout(redactError(err))
+	return &ErrQueryFailed{Reason: te}
+}
+
+// newErrQueryFailedFromResponse returns the failure of a request that
+// returned an unexpected status, closing its body.
+func newErrQueryFailedFromResponse(req *http.Request, attempts int, resp *http.Response) *ErrQueryFailed {
+	const maxBytes = 8 * 1024
+	defer resp.Body.Close()
+	te := newErrTransport(req, attempts)
+	te.StatusCode = resp.StatusCode
+	for _, name := range TransportErrorHeaders {
+		if v, ok := resp.Header[http.CanonicalHeaderKey(name)]; ok {
+			if te.Header == nil {
+				te.Header = make(http.Header)
+			}
+			te.Header[http.CanonicalHeaderKey(name)] = v
+		}
+	}
+	for _, name := range requestIDHeaders {
+		if v := resp.Header.Get(name); v != "" {
+			te.RequestID = v
+			break
+		}
+	}
+	qf := &ErrQueryFailed{StatusCode: resp.StatusCode, Reason: te}
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBytes))
+	if err != nil {
+		te.Err = err
+		return qf
+	}
+	te.Body = string(b)
+	if resp.ContentLength > maxBytes {
+		te.Body += "..."
+	}
+	return qf
+}
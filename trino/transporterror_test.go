@@ -0,0 +1,87 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrTransportStatus(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Via", "1.1 gateway")
+		w.Header().Set("Server", "nginx")
+		w.Header().Set("X-Request-Id", "req-42")
+		w.Header().Set("X-Unrelated", "dropped")
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("upstream unavailable"))
+	}))
+	t.Cleanup(ts.Close)
+	db := openTestDB(t, ts)
+
+	_, err := db.Query("SELECT 1")
+	var qerr *ErrQueryFailed
+	require.True(t, errors.As(err, &qerr), "unexpected error: %v", err)
+	assert.Equal(t, http.StatusBadGateway, qerr.StatusCode)
+	assert.EqualError(t, err, `trino: query failed (502 Bad Gateway): "upstream unavailable"`)
+
+	var terr *ErrTransport
+	require.True(t, errors.As(err, &terr), "unexpected error: %v", err)
+	assert.Equal(t, "POST", terr.Method)
+	assert.Equal(t, ts.URL+"/v1/statement", terr.URI)
+	assert.Equal(t, http.StatusBadGateway, terr.StatusCode)
+	assert.Equal(t, "req-42", terr.RequestID)
+	assert.Equal(t, 2, terr.Attempts)
+	assert.Equal(t, "upstream unavailable", terr.Body)
+	assert.Equal(t, http.Header{
+		"Content-Type": {"text/plain; charset=utf-8"},
+		"Server":       {"nginx"},
+		"Via":          {"1.1 gateway"},
+		"X-Request-Id": {"req-42"},
+	}, terr.Header)
+	assert.NoError(t, terr.Unwrap())
+}
+
+func TestErrTransportConnection(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	ts.Close()
+	db, err := sql.Open("trino", ts.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	_, err = db.Query("SELECT 1")
+	var terr *ErrTransport
+	require.True(t, errors.As(err, &terr), "unexpected error: %v", err)
+	assert.Equal(t, 0, terr.StatusCode)
+	assert.Equal(t, 1, terr.Attempts)
+	assert.Empty(t, terr.Header)
+	var opErr *net.OpError
+	assert.True(t, errors.As(err, &opErr), "unexpected error: %v", err)
+}
@@ -66,7 +66,6 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
-	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -92,9 +91,21 @@ var (
 	// ErrOperationNotSupported indicates that a database operation is not supported.
 	ErrOperationNotSupported = errors.New("trino: operation not supported")
 
-	// ErrQueryCancelled indicates that a query has been cancelled.
+	// ErrQueryCancelled indicates that a query has been cancelled in Trino,
+	// by a client. Queries cancelled by the context of this driver fail
+	// with errors matching context.Canceled with errors.Is instead.
 	ErrQueryCancelled = errors.New("trino: query cancelled")
 
+	// ErrQueryKilled is matched, using errors.Is, by the errors of queries
+	// killed by an administrator or preempted by Trino.
+	ErrQueryKilled = errors.New("trino: query killed")
+
+	// ErrQueryTimeout is matched, using errors.Is, by the errors of queries
+	// that exceeded a time limit of Trino, such as query_max_run_time.
+	// Queries exceeding the deadline of their context fail with errors
+	// matching context.DeadlineExceeded instead.
+	ErrQueryTimeout = errors.New("trino: query exceeded time limit")
+
 	// ErrUnsupportedHeader indicates that the server response contains an unsupported header.
 	ErrUnsupportedHeader = errors.New("trino: server response contains an unsupported header")
 )
@@ -105,18 +116,25 @@ const (
 	preparedStatementHeader    = trinoHeaderPrefix+"Prepared-Statement"
 	preparedStatementName      = "_trino_go"
 
-	trinoUserHeader            = trinoHeaderPrefix+`User`
-	trinoSourceHeader          = trinoHeaderPrefix+`Source`
-	trinoCatalogHeader         = trinoHeaderPrefix+`Catalog`
-	trinoSchemaHeader          = trinoHeaderPrefix+`Schema`
-	trinoSessionHeader         = trinoHeaderPrefix+`Session`
-	trinoSetCatalogHeader      = trinoHeaderPrefix+`Set-Catalog`
-	trinoSetSchemaHeader       = trinoHeaderPrefix+`Set-Schema`
-	trinoSetPathHeader         = trinoHeaderPrefix+`Set-Path`
-	trinoSetSessionHeader      = trinoHeaderPrefix+`Set-Session`
-	trinoClearSessionHeader    = trinoHeaderPrefix+`Clear-Session`
-	trinoSetRoleHeader         = trinoHeaderPrefix+`Set-Role`
-	trinoExtraCredentialHeader = trinoHeaderPrefix+`Extra-Credential`
+	trinoUserHeader               = trinoHeaderPrefix+`User`
+	trinoSourceHeader             = trinoHeaderPrefix+`Source`
+	trinoCatalogHeader            = trinoHeaderPrefix+`Catalog`
+	trinoSchemaHeader             = trinoHeaderPrefix+`Schema`
+	trinoSessionHeader            = trinoHeaderPrefix+`Session`
+	trinoSetCatalogHeader         = trinoHeaderPrefix+`Set-Catalog`
+	trinoSetSchemaHeader          = trinoHeaderPrefix+`Set-Schema`
+	trinoSetPathHeader            = trinoHeaderPrefix+`Set-Path`
+	trinoSetSessionHeader         = trinoHeaderPrefix+`Set-Session`
+	trinoClearSessionHeader       = trinoHeaderPrefix+`Clear-Session`
+	trinoSetRoleHeader            = trinoHeaderPrefix+`Set-Role`
+	trinoExtraCredentialHeader    = trinoHeaderPrefix+`Extra-Credential`
+	trinoRoutingGroupHeader       = trinoHeaderPrefix+`Routing-Group`
+	trinoTimeZoneHeader           = trinoHeaderPrefix+`Time-Zone`
+	trinoLanguageHeader           = trinoHeaderPrefix+`Language`
+	trinoClientCapabilitiesHeader = trinoHeaderPrefix+`Client-Capabilities`
+	trinoClientTagsHeader         = trinoHeaderPrefix+`Client-Tags`
+	trinoAddedPrepareHeader       = trinoHeaderPrefix+`Added-Prepare`
+	trinoDeallocatedPrepareHeader = trinoHeaderPrefix+`Deallocated-Prepare`
 
 	KerberosEnabledConfig    = "KerberosEnabled"
 	kerberosKeytabPathConfig = "KerberosKeytabPath"
@@ -139,48 +157,211 @@ var (
 	}
 )
 
-type sqldriver struct{}
+type sqldriver struct {
+	connector *connector // set when returned by connector.Driver
+}
 
 func (d *sqldriver) Open(name string) (driver.Conn, error) {
+	if profile, ok := profileName(name); ok {
+		return (&connector{profile: profile}).Connect(context.Background())
+	}
 	return newConn(name)
 }
 
-var _ driver.Driver = &sqldriver{}
+// OpenConnector implements the driver.DriverContext interface.
+//
+// Invalid DSN parameters fail here, when opening the database, rather than
+// when connecting to Trino.
+func (d *sqldriver) OpenConnector(name string) (driver.Connector, error) {
+	if profile, ok := profileName(name); ok {
+		return &connector{profile: profile}, nil
+	}
+	if err := validateDSN(name); err != nil {
+		return nil, err
+	}
+	return &connector{dsn: name}, nil
+}
+
+var (
+	_ driver.Driver        = &sqldriver{}
+	_ driver.DriverContext = &sqldriver{}
+)
+
+type connector struct {
+	dsn     string
+	config  *Config
+	profile string // name of the profile resolved on each connection, if set
+
+	mu      sync.Mutex
+	limiter *rateLimiter // shared by the connections of the connector
+	tracker queryTracker // queries in flight, for Shutdown
+}
+
+// NewConnector returns a connector for the configuration, to be used with sql.OpenDB.
+//
+// Unlike the DSN returned by FormatDSN, the connector also honors the
+// configuration options that cannot be encoded in a DSN, such as the Logger.
+func NewConnector(config *Config) (driver.Connector, error) {
+	// the location is set by Connect, as time zones without an IANA name,
+	// such as the ones returned by time.FixedZone, cannot be encoded
+	dsnConfig := *config
+	dsnConfig.Location = nil
+	dsn, err := dsnConfig.FormatDSN()
+	if err != nil {
+		return nil, err
+	}
+	if err := validateDSN(dsn); err != nil {
+		return nil, err
+	}
+	return &connector{dsn: dsn, config: config}, nil
+}
+
+// Connect implements the driver.Connector interface.
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dsn, config := c.dsn, c.config
+	if c.profile != "" {
+		var err error
+		if config, err = getProfile(c.profile); err != nil {
+			return nil, err
+		}
+		if dsn, err = config.FormatDSN(); err != nil {
+			return nil, err
+		}
+	}
+	conn, err := newConn(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if conn.limiter != nil {
+		c.mu.Lock()
+		if c.limiter == nil {
+			c.limiter = conn.limiter
+		}
+		conn.limiter = c.limiter
+		c.mu.Unlock()
+	}
+	conn.tracker = &c.tracker
+	if config != nil {
+		if config.Logger != nil {
+			conn.logger = config.Logger
+		}
+		if config.JSONDecoder != nil {
+			conn.jsonDecoder = config.JSONDecoder
+		}
+		if config.Location != nil {
+			conn.location = config.Location
+		}
+		conn.nextURIRewriter = config.NextURIRewriter
+		conn.queryRewriter = config.QueryRewriter
+		conn.sqlCommentTags = config.SQLCommentTags
+		if config.SerialOptions != nil {
+			conn.serialOptions = *config.SerialOptions
+		}
+		conn.hostClients = config.HostClients
+		conn.extraHeaders = config.ExtraHeaders
+		conn.extraHeadersFunc = config.ExtraHeadersFunc
+		conn.statementFilter = config.StatementFilter
+		conn.resultCache = config.ResultCache
+		conn.resultCacheMaxRows = config.ResultCacheMaxRows
+		if conn.resultCacheMaxRows <= 0 {
+			conn.resultCacheMaxRows = DefaultResultCacheMaxRows
+		}
+	}
+	return conn, nil
+}
+
+// Driver implements the driver.Connector interface.
+func (c *connector) Driver() driver.Driver {
+	return &sqldriver{connector: c}
+}
+
+var _ driver.Connector = &connector{}
 
 // Config is a configuration that can be encoded to a DSN string.
+
 type Config struct {
-	ServerURI          string            // URI of the Trino server, e.g. http://user@localhost:8080
-	Source             string            // Source of the connection (optional)
-	Catalog            string            // Catalog (optional)
-	Schema             string            // Schema (optional)
-	SessionProperties  map[string]string // Session properties (optional)
-	ExtraCredentials   map[string]string // Extra credentials (optional)
-	CustomClientName   string            // Custom client name (optional)
-	KerberosEnabled    string            // KerberosEnabled (optional, default is false)
-	KerberosKeytabPath string            // Kerberos Keytab Path (optional)
-	KerberosPrincipal  string            // Kerberos Principal used to authenticate to KDC (optional)
-	KerberosRealm      string            // The Kerberos Realm (optional)
-	KerberosConfigPath string            // The krb5 config path (optional)
-	SSLCertPath        string            // The SSL cert path for TLS verification (optional)
+	ServerURI             string            // URI of the Trino server, e.g. http://user@localhost:8080
+	Source                string            // Source of the connection (optional)
+	Catalog               string            // Catalog (optional)
+	Schema                string            // Schema (optional)
+	SessionProperties     map[string]string // Session properties (optional)
+	SessionPropertyOrder  []string          // Names of the SessionProperties set first, in order, the others following by name (optional)
+	ExtraCredentials      map[string]string // Extra credentials (optional)
+	CustomClientName      string            // Custom client name (optional)
+	KerberosEnabled       string            // KerberosEnabled (optional, default is false)
+	KerberosKeytabPath    string            // Kerberos Keytab Path (optional)
+	KerberosPrincipal     string            // Kerberos Principal used to authenticate to KDC (optional)
+	KerberosRealm         string            // The Kerberos Realm (optional)
+	KerberosConfigPath    string            // The krb5 config path (optional)
+	SSLCertPath           string            // The SSL cert path for TLS verification (optional)
+	SlowQueryThreshold    time.Duration     // Log statements running longer than this (optional, default is disabled)
+	MaxBufferedRows       int               // Max rows fetched ahead of the application, enables prefetching (optional, default is disabled)
+	MaxBufferedBytes      int64             // Max response bytes fetched ahead of the application, enables prefetching (optional, default is disabled)
+	AdaptivePrefetch      bool              // Fetch ahead only the pages the application is expected to scan before the next one is fetched, within the buffer limits (optional, default is false)
+	SpillDir              string            // Directory of the temporary files the pages fetched beyond the buffer limits are written to, enables prefetching (optional, default is disabled)
+	MaxResponseBytes      int64             // Max size of a single response (optional, default is unlimited)
+	MaxValueBytes         int               // Max size of a single string or raw value (optional, default is unlimited)
+	MaxStatementBytes     int               // Max size of statements sent to Trino (optional, default is unlimited)
+	GzipStatementBytes    int               // Min size of the statements compressed with gzip when sent to Trino (optional, default is disabled)
+	MaxHeaderBytes        int               // Max size of prepared statement headers, larger ones are sent with EXECUTE IMMEDIATE (optional, default is unlimited)
+	RoutingGroup          string            // Trino Gateway routing group (optional)
+	ForceOriginalHost     bool              // Send all requests of a query to the scheme, host and port of ServerURI (optional, default is false)
+	MaxRequestsPerSec     float64           // Max rate of requests sent by the connections of a pool (optional, default is unlimited)
+	RequestBurst          int               // Max requests sent at once when MaxRequestsPerSec is set (optional, default is 1)
+	DialTimeout           time.Duration     // Timeout of establishing TCP connections (optional, default is the one of the HTTP client)
+	TLSHandshakeTimeout   time.Duration     // Timeout of TLS handshakes (optional, default is the one of the HTTP client)
+	ResponseHeaderTimeout time.Duration     // Timeout of receiving the headers of a response (optional, default is the one of the HTTP client)
+	DNSCacheTTL           time.Duration     // Time the addresses of the coordinator are cached and rotated through, resolved again on connection failures (optional, default is disabled)
+	IPFamily              string            // IP versions used to connect to Trino, one of IPv4Only, IPv6Only, PreferIPv4 and PreferIPv6 (optional, default is both, in the order returned by the resolver)
+	FallbackDelay         time.Duration     // Time waited for a connection over the preferred IP version before also trying the other one (optional, default is 300ms)
+	StmtCacheSize         int               // Number of prepared statements cached by each connection (optional, default is disabled)
+	ReconnectTimeout      time.Duration     // Max time spent retrying requests while Trino cannot be connected to, e.g. while restarting (optional, default is disabled)
+	IdempotentRetries     int               // Max retries of requests for pages and cancellations failing on connection resets and TLS handshake failures (optional, default is 3, negative to disable)
+	PartialResults        bool              // Return the rows received with the failure of a query before failing (optional, default is false)
+	PollInterval          time.Duration     // Wait before polling a running query again after a page without data, doubled up to MaxPollInterval (optional, default is disabled)
+	MaxPollInterval       time.Duration     // Max wait between polls of a running query when PollInterval is set (optional, default is 1s)
+	MaxWait               time.Duration     // Max time Trino holds a request for a page of a running query without data, between 1ms and 1s (optional, default is the one of the server, 1s)
+	TargetResultSize      int64             // Target size in bytes of the pages returned by Trino, at most 128MB (optional, default is the one of the server, 16MB)
+	StrictNumbers         bool              // Fail on integers that may have lost precision when decoded (optional, default is false)
+	LenientConversions    bool              // Convert numeric strings, 0 and 1 to booleans, and pass unparsable temporal values as strings (optional, default is false)
+	DisableTypedReaders   bool              // Convert all values with the generic conversions, instead of the readers specialized for common scalar types (optional, default is false)
+	TimeZone              string            // Session time zone, e.g. Europe/Paris or +01:00, also used as default Location (optional, default is the one of the server)
+	Language              string            // Language of the session, e.g. en-US (optional, default is the one of the server)
+	ClientCapabilities    []string          // Capabilities declared to Trino, set to an empty slice to declare none (optional, default is DefaultClientCapabilities)
+	StrictDSN             bool              // Reject unknown DSN parameters (optional, default is false)
+	ReadOnly              bool              // Reject statements other than queries, SHOW, DESCRIBE and EXPLAIN before sending them (optional, default is false)
+	SafeParameters        bool              // Send string arguments as hexadecimal UTF-8 rather than quoted literals, the default of the next major version (optional, default is false)
+	AutoLimit             int64             // Max rows of queries, added as a LIMIT to the ones without, or lowering larger ones (optional, default is disabled)
+	VerifyCoordinator     bool              // Fail with ErrNotCoordinator if the server is a worker, checked once per connection (optional, default is false)
+	ConfirmCancel         bool              // Wait for Trino to stop cancelled queries, failing with ErrCancelNotConfirmed otherwise (optional, default is false)
+	Location              *time.Location    // Location of date, time and timestamp values without a time zone (optional, default is TimeZone, or time.Local)
+	Logger                Logger            // Logger for diagnostics, only honored by NewConnector (optional, default is the log package)
+	JSONDecoder           JSONDecoder       // Decoder for responses, only honored by NewConnector (optional, default is encoding/json)
+	NextURIRewriter       NextURIRewriter   // Rewrites the nextUri of responses, only honored by NewConnector (optional)
+	QueryRewriter         QueryRewriter     // Rewrites statements before they are sent, only honored by NewConnector (optional)
+	SQLCommentTags        map[string]string // Metadata added to statements as a comment in the sqlcommenter format, e.g. service, only honored by NewConnector (optional)
+	SerialOptions         *SerialOptions    // Formatting of query arguments, e.g. to allow floats and timestamps, only honored by NewConnector (optional, default is the one of Serial)
+	HostClients           HostClients       // HTTP clients of the requests to other hosts than the one of ServerURI, only honored by NewConnector (optional)
+	ExtraHeaders          http.Header       // Headers added to every request, only honored by NewConnector (optional)
+	ExtraHeadersFunc      ExtraHeadersFunc  // Returns headers added to every request, only honored by NewConnector (optional)
+	StatementFilter       StatementFilter   // Rejects statements before they are sent, only honored by NewConnector (optional)
+	ResultCache           ResultCache       // Serves repeated identical queries from a cache, only honored by NewConnector (optional)
+	ResultCacheMaxRows    int64             // Max rows of the results stored in the ResultCache, only honored by NewConnector (optional, default is DefaultResultCacheMaxRows)
 }
 
 // FormatDSN returns a DSN string from the configuration.
 func (c *Config) FormatDSN() (string, error) {
 	serverURL, err := url.Parse(c.ServerURI)
 	if err != nil {
-		return "", err
+		return "", redactError(err)
 	}
-	var sessionkv []string
-	if c.SessionProperties != nil {
-		for k, v := range c.SessionProperties {
-			sessionkv = append(sessionkv, k+"="+v)
-		}
+	sessionProperties, err := formatSessionProperties(c.SessionProperties, c.SessionPropertyOrder)
+	if err != nil {
+		return "", err
 	}
-	var credkv []string
-	if c.ExtraCredentials != nil {
-		for k, v := range c.ExtraCredentials {
-			credkv = append(credkv, k+"="+v)
-		}
+	extraCredentials, err := formatExtraCredentials(c.ExtraCredentials)
+	if err != nil {
+		return "", err
 	}
 	source := c.Source
 	if source == "" {
@@ -207,16 +388,128 @@ func (c *Config) FormatDSN() (string, error) {
 		}
 	}
 
-	// ensure consistent order of items
-	sort.Strings(sessionkv)
-	sort.Strings(credkv)
+	if c.SlowQueryThreshold > 0 {
+		query.Add("slow_query_threshold", c.SlowQueryThreshold.String())
+	}
+	if c.MaxBufferedRows > 0 {
+		query.Add("max_buffered_rows", strconv.Itoa(c.MaxBufferedRows))
+	}
+	if c.MaxBufferedBytes > 0 {
+		query.Add("max_buffered_bytes", strconv.FormatInt(c.MaxBufferedBytes, 10))
+	}
+	if c.AdaptivePrefetch {
+		query.Add("adaptive_prefetch", "true")
+	}
+	if c.MaxResponseBytes > 0 {
+		query.Add("max_response_bytes", strconv.FormatInt(c.MaxResponseBytes, 10))
+	}
+	if c.MaxValueBytes > 0 {
+		query.Add("max_value_bytes", strconv.Itoa(c.MaxValueBytes))
+	}
+	if c.MaxStatementBytes > 0 {
+		query.Add("max_statement_bytes", strconv.Itoa(c.MaxStatementBytes))
+	}
+	if c.GzipStatementBytes > 0 {
+		query.Add("gzip_statement_bytes", strconv.Itoa(c.GzipStatementBytes))
+	}
+	if c.MaxHeaderBytes > 0 {
+		query.Add("max_header_bytes", strconv.Itoa(c.MaxHeaderBytes))
+	}
+	if c.ForceOriginalHost {
+		query.Add("force_original_host", "true")
+	}
+	if c.MaxRequestsPerSec > 0 {
+		query.Add("max_requests_per_second", strconv.FormatFloat(c.MaxRequestsPerSec, 'f', -1, 64))
+	}
+	if c.RequestBurst > 0 {
+		query.Add("request_burst", strconv.Itoa(c.RequestBurst))
+	}
+	if c.DialTimeout > 0 {
+		query.Add("dial_timeout", c.DialTimeout.String())
+	}
+	if c.TLSHandshakeTimeout > 0 {
+		query.Add("tls_handshake_timeout", c.TLSHandshakeTimeout.String())
+	}
+	if c.ResponseHeaderTimeout > 0 {
+		query.Add("response_header_timeout", c.ResponseHeaderTimeout.String())
+	}
+	if c.DNSCacheTTL > 0 {
+		query.Add("dns_cache_ttl", c.DNSCacheTTL.String())
+	}
+	if c.IPFamily != "" {
+		query.Add("ip_family", c.IPFamily)
+	}
+	if c.FallbackDelay > 0 {
+		query.Add("fallback_delay", c.FallbackDelay.String())
+	}
+	if c.StmtCacheSize > 0 {
+		query.Add("stmt_cache_size", strconv.Itoa(c.StmtCacheSize))
+	}
+	if c.ReconnectTimeout > 0 {
+		query.Add("reconnect_timeout", c.ReconnectTimeout.String())
+	}
+	if c.IdempotentRetries > 0 {
+		query.Add("idempotent_retries", strconv.Itoa(c.IdempotentRetries))
+	} else if c.IdempotentRetries < 0 {
+		query.Add("idempotent_retries", "0")
+	}
+	if c.PartialResults {
+		query.Add("partial_results", "true")
+	}
+	if c.PollInterval > 0 {
+		query.Add("poll_interval", c.PollInterval.String())
+	}
+	if c.MaxPollInterval > 0 {
+		query.Add("max_poll_interval", c.MaxPollInterval.String())
+	}
+	if c.MaxWait > 0 {
+		query.Add("max_wait", c.MaxWait.String())
+	}
+	if c.TargetResultSize > 0 {
+		query.Add("target_result_size", strconv.FormatInt(c.TargetResultSize, 10))
+	}
+	if c.StrictNumbers {
+		query.Add("strict_numbers", "true")
+	}
+	if c.LenientConversions {
+		query.Add("lenient_conversions", "true")
+	}
+	if c.DisableTypedReaders {
+		query.Add("disable_typed_readers", "true")
+	}
+	if c.StrictDSN {
+		query.Add("strict_dsn", "true")
+	}
+	if c.ReadOnly {
+		query.Add("read_only", "true")
+	}
+	if c.SafeParameters {
+		query.Add("safe_parameters", "true")
+	}
+	if c.AutoLimit > 0 {
+		query.Add("auto_limit", strconv.FormatInt(c.AutoLimit, 10))
+	}
+	if c.VerifyCoordinator {
+		query.Add("verify_coordinator", "true")
+	}
+	if c.ConfirmCancel {
+		query.Add("confirm_cancel", "true")
+	}
+	if c.Location != nil {
+		query.Add("location", c.Location.String())
+	}
 
 	for k, v := range map[string]string{
-		"catalog":            c.Catalog,
-		"schema":             c.Schema,
-		"session_properties": strings.Join(sessionkv, ","),
-		"extra_credentials":  strings.Join(credkv, ","),
-		"custom_client":      c.CustomClientName,
+		"catalog":             c.Catalog,
+		"schema":              c.Schema,
+		"session_properties":  sessionProperties,
+		"extra_credentials":   extraCredentials,
+		"custom_client":       c.CustomClientName,
+		"routing_group":       c.RoutingGroup,
+		"time_zone":           c.TimeZone,
+		"language":            c.Language,
+		"client_capabilities": formatClientCapabilities(c.ClientCapabilities),
+		"spill_dir":           c.SpillDir,
 	} {
 		if v != "" {
 			query[k] = []string{v}
@@ -231,23 +524,98 @@ type Conn struct {
 	baseURL         string
 	auth            *url.Userinfo
 	httpClient      http.Client
+	transports      []*http.Transport // shared transports used by httpClient, released by Close
 	httpHeaders     http.Header
 	kerberosClient  client.Client
 	kerberosEnabled bool
+	logger          Logger
+	slowQuery        time.Duration
+	jsonDecoder      JSONDecoder
+	maxBufferedRows  int
+	maxBufferedBytes int64
+	maxResponseBytes  int64
+	maxValueBytes     int
+	maxStatementBytes int
+	maxHeaderBytes    int
+	forceOriginalHost bool
+	nextURIRewriter   NextURIRewriter
+	queryRewriter     QueryRewriter
+	sqlCommentTags    map[string]string
+	serialOptions     SerialOptions
+	extraHeaders      http.Header
+	extraHeadersFunc  ExtraHeadersFunc
+	limiter           *rateLimiter
+	tracker           *queryTracker
+	partialResults    bool
+	reconnectTimeout  time.Duration
+	idempotentRetries int
+	prepared          map[string]string // statements prepared with PREPARE, by name
+	stmtCache         *stmtCache
+	pollInterval      time.Duration
+	maxPollInterval   time.Duration
+	maxWait           time.Duration
+	targetResultSize  int64
+	strictNumbers     bool
+	lenientConversions bool
+	noTypedReaders     bool
+	location           *time.Location
+	readOnly           bool
+	safeParameters     bool
+	statementFilter    StatementFilter
+	autoLimit          int64
+	resultCache        ResultCache
+	resultCacheMaxRows int64
+	spillDir           string
+	confirmCancel      bool
+	hostClients        HostClients
+	gzipStatementBytes int
+	gzipRejected       bool // whether the server rejected a compressed statement
+	adaptivePrefetch   bool
+
+	checkCoordinator    bool
+	coordinatorVerified bool
 }
 
 var (
 	_ driver.Conn               = &Conn{}
 	_ driver.ConnPrepareContext = &Conn{}
+	_ driver.NamedValueChecker  = &Conn{}
 )
 
+// CheckNamedValue implements the driver.NamedValueChecker interface.
+//
+// Arguments implementing driver.Valuer are replaced by their value, so that
+// Value is called once per argument. Arguments supported by Serial, with
+// the SerialOptions of the connection, such as slices and Numeric values,
+// are kept as is, and other ones are converted like database/sql does by
+// default.
+func (c *Conn) CheckNamedValue(arg *driver.NamedValue) error {
+	v, err := resolveValuers(arg.Value)
+	if err != nil {
+		return err
+	}
+	arg.Value = v
+	if _, err := c.serialOptions.Serial(arg.Value); err == nil {
+		return nil
+	}
+	v, err = driver.DefaultParameterConverter.ConvertValue(arg.Value)
+	if err != nil {
+		return err
+	}
+	arg.Value = v
+	return nil
+}
+
 func newConn(dsn string) (*Conn, error) {
 	serverURL, err := url.Parse(dsn)
 	if err != nil {
-		return nil, fmt.Errorf("trino: malformed dsn: %v", err)
+		return nil, fmt.Errorf("trino: malformed dsn: %v", redactError(err))
 	}
 
 	query := serverURL.Query()
+	if err := checkParameters(query); err != nil {
+		return nil, err
+	}
 
 	kerberosEnabled, _ := strconv.ParseBool(query.Get(KerberosEnabledConfig))
 
@@ -273,35 +641,31 @@ func newConn(dsn string) (*Conn, error) {
 		}
 	}
 
-	var httpClient = http.DefaultClient
-	if clientKey := query.Get("custom_client"); clientKey != "" {
-		httpClient = getCustomClient(clientKey)
-		if httpClient == nil {
-			return nil, fmt.Errorf("trino: custom client not registered: %q", clientKey)
-		}
-	} else if certPath := query.Get(SSLCertPathConfig); certPath != "" && serverURL.Scheme == "https" {
-		cert, err := ioutil.ReadFile(certPath)
-		if err != nil {
-			return nil, fmt.Errorf("trino: Error loading SSL Cert File: %v", err)
-		}
-		certPool := x509.NewCertPool()
-		certPool.AppendCertsFromPEM(cert)
-
-		httpClient = &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					RootCAs: certPool,
-				},
-			},
-		}
-	}
-
 	c := &Conn{
 		baseURL:         serverURL.Scheme + "://" + serverURL.Host,
-		httpClient:      *httpClient,
 		httpHeaders:     make(http.Header),
 		kerberosClient:  kerberosClient,
 		kerberosEnabled: kerberosEnabled,
+		logger:          stdLogger{},
+		jsonDecoder:     stdJSONDecoder{},
+		location:        time.Local,
+	}
+
+	if err := c.parseParameters(query); err != nil {
+		return nil, err
+	}
+
+	capabilities, err := parseClientCapabilities(query.Get("client_capabilities"))
+	if err != nil {
+		return nil, err
+	}
+	sessionProperties, err := parseSessionProperties(query.Get("session_properties"))
+	if err != nil {
+		return nil, err
+	}
+	extraCredentials, err := parseExtraCredentials(query.Get("extra_credentials"))
+	if err != nil {
+		return nil, err
 	}
 
 	var user string
@@ -314,21 +678,76 @@ func newConn(dsn string) (*Conn, error) {
 	}
 
 	for k, v := range map[string]string{
-		trinoUserHeader:            user,
-		trinoSourceHeader:          query.Get("source"),
-		trinoCatalogHeader:         query.Get("catalog"),
-		trinoSchemaHeader:          query.Get("schema"),
-		trinoSessionHeader:         query.Get("session_properties"),
-		trinoExtraCredentialHeader: query.Get("extra_credentials"),
+		trinoUserHeader:               user,
+		trinoSourceHeader:             query.Get("source"),
+		trinoCatalogHeader:            query.Get("catalog"),
+		trinoSchemaHeader:             query.Get("schema"),
+		trinoSessionHeader:            sessionProperties,
+		trinoExtraCredentialHeader:    extraCredentials,
+		trinoRoutingGroupHeader:       query.Get("routing_group"),
+		trinoTimeZoneHeader:           query.Get("time_zone"),
+		trinoLanguageHeader:           query.Get("language"),
+		trinoClientCapabilitiesHeader: capabilities,
 	} {
 		if v != "" {
 			c.httpHeaders.Add(k, v)
 		}
 	}
 
+	if err := c.setHTTPClient(serverURL, query); err != nil {
+		return nil, err
+	}
 	return c, nil
 }
 
+// setHTTPClient sets the client of the connection, built from the DSN.
+func (c *Conn) setHTTPClient(serverURL *url.URL, query url.Values) error {
+	var httpClient = http.DefaultClient
+	if clientKey := query.Get("custom_client"); clientKey != "" {
+		httpClient = getCustomClient(clientKey)
+		if httpClient == nil {
+			return fmt.Errorf("trino: custom client not registered: %q", clientKey)
+		}
+	} else if certPath := query.Get(SSLCertPathConfig); certPath != "" && serverURL.Scheme == "https" {
+		cert, err := ioutil.ReadFile(certPath)
+		if err != nil {
+			return fmt.Errorf("trino: Error loading SSL Cert File: %v", err)
+		}
+		transport := sharedTransport("SSLCertPath="+string(cert), func() *http.Transport {
+			certPool := x509.NewCertPool()
+			certPool.AppendCertsFromPEM(cert)
+			return &http.Transport{
+				TLSClientConfig: &tls.Config{
+					RootCAs: certPool,
+				},
+				// setting TLSClientConfig disables HTTP/2 otherwise
+				ForceAttemptHTTP2: true,
+			}
+		})
+		c.transports = append(c.transports, transport)
+		httpClient = &http.Client{Transport: transport}
+	}
+
+	timeoutsClient, err := withTransportTimeouts(httpClient, query)
+	if err != nil {
+		c.releaseTransports()
+		return err
+	}
+	if timeoutsClient != httpClient {
+		c.transports = append(c.transports, timeoutsClient.Transport.(*http.Transport))
+	}
+	c.httpClient = *timeoutsClient
+	return nil
+}
+
+// releaseTransports releases the shared transports of the connection.
+func (c *Conn) releaseTransports() {
+	for _, t := range c.transports {
+		releaseTransport(t)
+	}
+	c.transports = nil
+}
+
 // registry for custom http clients
 var customClientRegistry = struct {
 	sync.RWMutex
@@ -399,15 +818,43 @@ func (c *Conn) Prepare(query string) (driver.Stmt, error) {
 
 // PrepareContext implements the driver.ConnPrepareContext interface.
 func (c *Conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if c.queryRewriter != nil {
+		var err error
+		if query, err = c.queryRewriter(ctx, query); err != nil {
+			return nil, err
+		}
+	}
+	query = c.addSQLComment(ctx, query)
+	if c.autoLimit > 0 {
+		query = applyAutoLimit(query, c.autoLimit)
+	}
+	if c.stmtCache != nil {
+		return c.stmtCache.get(c, query), nil
+	}
 	return &driverStmt{conn: c, query: query}, nil
 }
 
+// newInternalStmt returns a statement for a query sent by the driver on its
+// own, such as a lookup in the system tables. Unlike the statements of the
+// application, it is not rewritten, checked by the StatementFilter or
+// read_only, nor served from the ResultCache.
+func (c *Conn) newInternalStmt(query string) *driverStmt {
+	return &driverStmt{conn: c, query: query, internal: true}
+}
+
 // Close implements the driver.Conn interface.
 func (c *Conn) Close() error {
+	c.releaseTransports()
 	return nil
 }
 
 func (c *Conn) newRequest(method, url string, body io.Reader, hs http.Header) (*http.Request, error) {
+	return c.newSessionRequest(method, url, body, c.httpHeaders, hs)
+}
+
+// newSessionRequest is newRequest, sending the given session headers
+// instead of the current ones of the connection.
+func (c *Conn) newSessionRequest(method, url string, body io.Reader, session, hs http.Header) (*http.Request, error) {
 	req, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("trino: %v", err)
@@ -420,12 +867,15 @@ func (c *Conn) newRequest(method, url string, body io.Reader, hs http.Header) (*
 		}
 	}
 
-	for k, v := range c.httpHeaders {
+	for k, v := range session {
 		req.Header[k] = v
 	}
 	for k, v := range hs {
 		req.Header[k] = v
 	}
+	if method == "POST" {
+		c.addPreparedStatements(req.Header)
+	}
 
 	if c.auth != nil {
 		pass, _ := c.auth.Password()
@@ -435,38 +885,82 @@ func (c *Conn) newRequest(method, url string, body io.Reader, hs http.Header) (*
 }
 
 func (c *Conn) roundTrip(ctx context.Context, req *http.Request) (*http.Response, error) {
+	resp, err := c.sendRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.updateSession(resp.Header); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// sendRequest sends req, retrying it as needed, and returns the successful
+// response without applying its changes to the session of the connection,
+// unlike roundTrip.
+func (c *Conn) sendRequest(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if err := c.addExtraHeaders(ctx, req); err != nil {
+		return nil, err
+	}
 	delay := 100 * time.Millisecond
 	const maxDelayBetweenRequests = float64(15 * time.Second)
 	timer := time.NewTimer(0)
 	defer timer.Stop()
-	for {
+	var reconnectDeadline time.Time
+	var retries int // of idempotent requests failing on transient errors
+	for attempt := 0; ; attempt++ {
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		case <-timer.C:
+			if attempt > 0 && req.GetBody != nil {
+				// the body was consumed by the previous attempt
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, fmt.Errorf("trino: %v", err)
+				}
+				req.Body = body
+			}
 			timeout := DefaultQueryTimeout
 			if deadline, ok := ctx.Deadline(); ok {
 				timeout = time.Until(deadline)
 			}
-			client := c.httpClient
+			if c.limiter != nil && req.Method != "DELETE" {
+				if err := c.limiter.wait(ctx); err != nil {
+					return nil, err
+				}
+			}
+			client := c.clientFor(req)
 			client.Timeout = timeout
-			req.Cancel = ctx.Done()
-			resp, err := client.Do(req)
+			resp, err := doCounted(&client, req.WithContext(ctx))
 			if err != nil {
-				return nil, &ErrQueryFailed{Reason: err}
-			}
-			switch resp.StatusCode {
-			case http.StatusOK:
-				for src, dst := range responseToRequestHeaderMap {
-					if v := resp.Header.Get(src); v != "" {
-						c.httpHeaders.Set(dst, v)
-					}
+				if ctx.Err() == nil && c.retryIdempotent(req, err, retries) {
+					retries++
+					timer.Reset(delay)
+					delay = time.Duration(math.Min(
+						float64(delay)*math.Phi,
+						maxDelayBetweenRequests,
+					))
+					continue
 				}
-				for _, name := range unsupportedResponseHeaders {
-					if v := resp.Header.Get(name); v != "" {
-						return nil, ErrUnsupportedHeader
+				if c.reconnectTimeout > 0 && isDialError(err) {
+					if reconnectDeadline.IsZero() {
+						reconnectDeadline = time.Now().Add(c.reconnectTimeout)
+					}
+					if time.Now().Before(reconnectDeadline) {
+						timer.Reset(delay)
+						delay = time.Duration(math.Min(
+							float64(delay)*math.Phi,
+							maxDelayBetweenRequests,
+						))
+						continue
 					}
 				}
+				return nil, newErrQueryFailedFromRequest(req, attempt+1, err)
+			}
+			switch resp.StatusCode {
+			case http.StatusOK:
 				return resp, nil
 			case http.StatusServiceUnavailable:
 				resp.Body.Close()
@@ -477,7 +971,7 @@ func (c *Conn) roundTrip(ctx context.Context, req *http.Request) (*http.Response
 				))
 				continue
 			default:
-				return nil, newErrQueryFailedFromResponse(resp)
+				return nil, newErrQueryFailedFromResponse(req, attempt+1, resp)
 			}
 		}
 	}
@@ -487,6 +981,16 @@ func (c *Conn) roundTrip(ctx context.Context, req *http.Request) (*http.Response
 type ErrQueryFailed struct {
 	StatusCode int
 	Reason     error
+
+	// The following fields are only set when the failure was reported by
+	// Trino in a query response, as opposed to a transport failure.
+	ErrorName   string         // e.g. SYNTAX_ERROR
+	ErrorCode   int            // Numeric code of ErrorName
+	ErrorType   string         // e.g. USER_ERROR
+	Location    *ErrorLocation // Position in the SQL text, if known
+	FailureInfo *FailureInfo   // Failure details, including the stack trace
+
+	unsent bool // whether the statement failed to be sent to Trino
 }
 
 // Error implements the error interface.
@@ -495,27 +999,37 @@ func (e *ErrQueryFailed) Error() string {
 		e.StatusCode, http.StatusText(e.StatusCode), e.Reason)
 }
 
-func newErrQueryFailedFromResponse(resp *http.Response) *ErrQueryFailed {
-	const maxBytes = 8 * 1024
-	defer resp.Body.Close()
-	qf := &ErrQueryFailed{StatusCode: resp.StatusCode}
-	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBytes))
-	if err != nil {
-		qf.Reason = err
-		return qf
-	}
-	reason := string(b)
-	if resp.ContentLength > maxBytes {
-		reason += "..."
+// Unwrap returns the reason of the failure, so that transport failures
+// caused by the context of the query match context.Canceled or
+// context.DeadlineExceeded with errors.Is.
+func (e *ErrQueryFailed) Unwrap() error {
+	return e.Reason
+}
+
+// Is matches ErrQueryKilled and ErrQueryTimeout, depending on the name of
+// the error reported by Trino, and driver.ErrBadConn for statements that
+// could not be sent to Trino because connecting to it failed, so that
+// database/sql retries them. Failures after a statement was sent, even
+// partially, never match driver.ErrBadConn, as retrying them could run the
+// statement twice.
+func (e *ErrQueryFailed) Is(target error) bool {
+	switch target {
+	case driver.ErrBadConn:
+		return e.unsent
+	case ErrQueryKilled:
+		return e.ErrorName == "ADMINISTRATIVELY_KILLED" || e.ErrorName == "ADMINISTRATIVELY_PREEMPTED"
+	case ErrQueryTimeout:
+		return e.ErrorName == "EXCEEDED_TIME_LIMIT"
 	}
-	qf.Reason = errors.New(reason)
-	return qf
+	return false
 }
 
 type driverStmt struct {
-	conn  *Conn
-	query string
-	user  string
+	conn     *Conn
+	query    string
+	user     string
+	encoded  string // prepared statement header value
+	internal bool   // sent by the driver, see newInternalStmt
 }
 
 var (
@@ -537,7 +1051,7 @@ func (st *driverStmt) Exec(args []driver.Value) (driver.Result, error) {
 }
 
 func (st *driverStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
-	sr, err := st.exec(ctx, args)
+	sr, err := st.submit(ctx, args)
 	if err != nil {
 		return nil, err
 	}
@@ -547,7 +1061,13 @@ func (st *driverStmt) ExecContext(ctx context.Context, args []driver.NamedValue)
 		queryID:      sr.ID,
 		nextURI:      sr.NextURI,
 		rowsAffected: sr.UpdateCount,
+		updateType:   sr.UpdateType,
+		started:      sr.started,
+		stats:        sr.Stats,
+		cookies:      sr.cookies,
 	}
+	rows.track()
+	defer rows.untrack()
 	// consume all results, if there are any
 	for err == nil {
 		err = rows.fetch(true)
@@ -555,52 +1075,75 @@ func (st *driverStmt) ExecContext(ctx context.Context, args []driver.NamedValue)
 	if err != nil && err != io.EOF {
 		return nil, err
 	}
+	if fn := updateHandlerFromContext(ctx); fn != nil {
+		fn(rows.updateType, rows.rowsAffected)
+	}
 	return rows, nil
 }
 
 type stmtResponse struct {
-	ID          string    `json:"id"`
-	InfoURI     string    `json:"infoUri"`
-	NextURI     string    `json:"nextUri"`
-	Stats       stmtStats `json:"stats"`
-	Error       stmtError `json:"error"`
-	UpdateType  string    `json:"updateType"`
-	UpdateCount int64     `json:"updateCount"`
+	ID          string              `json:"id"`
+	InfoURI     string              `json:"infoUri"`
+	NextURI     string              `json:"nextUri"`
+	Columns     []queryColumn       `json:"columns"`
+	Data        [][]json.RawMessage `json:"data"` // decoded by the rows
+	Stats       stmtStats           `json:"stats"`
+	Error       stmtError           `json:"error"`
+	UpdateType  string              `json:"updateType"`
+	UpdateCount int64               `json:"updateCount"`
+
+	started  time.Time
+	cookies  []*http.Cookie
+	size     int64
+	cacheKey string        // key of the result in the ResultCache, if cacheable
+	cached   *CachedResult // result served from the ResultCache
 }
 
+
 type stmtStats struct {
-	State           string    `json:"state"`
-	Scheduled       bool      `json:"scheduled"`
-	Nodes           int       `json:"nodes"`
-	TotalSplits     int       `json:"totalSplits"`
-	QueuesSplits    int       `json:"queuedSplits"`
-	RunningSplits   int       `json:"runningSplits"`
-	CompletedSplits int       `json:"completedSplits"`
-	UserTimeMillis  int       `json:"userTimeMillis"`
-	CPUTimeMillis   int       `json:"cpuTimeMillis"`
-	WallTimeMillis  int       `json:"wallTimeMillis"`
-	ProcessedRows   int       `json:"processedRows"`
-	ProcessedBytes  int       `json:"processedBytes"`
-	RootStage       stmtStage `json:"rootStage"`
+	State              string    `json:"state"`
+	Scheduled          bool      `json:"scheduled"`
+	Nodes              int       `json:"nodes"`
+	TotalSplits        int       `json:"totalSplits"`
+	QueuesSplits       int       `json:"queuedSplits"`
+	RunningSplits      int       `json:"runningSplits"`
+	CompletedSplits    int       `json:"completedSplits"`
+	UserTimeMillis     int       `json:"userTimeMillis"`
+	CPUTimeMillis      int       `json:"cpuTimeMillis"`
+	WallTimeMillis     int       `json:"wallTimeMillis"`
+	ProcessedRows      int       `json:"processedRows"`
+	ProcessedBytes     int       `json:"processedBytes"`
+	PhysicalInputBytes int64     `json:"physicalInputBytes"`
+	PeakMemoryBytes    int64     `json:"peakMemoryBytes"`
+	RootStage          stmtStage `json:"rootStage"`
 }
 
 type stmtError struct {
-	Message       string               `json:"message"`
-	ErrorName     string               `json:"errorName"`
-	ErrorCode     int                  `json:"errorCode"`
-	ErrorLocation stmtErrorLocation    `json:"errorLocation"`
-	FailureInfo   stmtErrorFailureInfo `json:"failureInfo"`
+	Message       string         `json:"message"`
+	ErrorName     string         `json:"errorName"`
+	ErrorCode     int            `json:"errorCode"`
+	ErrorType     string         `json:"errorType"`
+	ErrorLocation *ErrorLocation `json:"errorLocation"`
+	FailureInfo   FailureInfo    `json:"failureInfo"`
 	// Other fields omitted
 }
 
-type stmtErrorLocation struct {
+// ErrorLocation is the position in the SQL text where a query failed.
+// Lines and columns start at 1.
+type ErrorLocation struct {
 	LineNumber   int `json:"lineNumber"`
 	ColumnNumber int `json:"columnNumber"`
 }
 
-type stmtErrorFailureInfo struct {
-	Type string `json:"type"`
-	// Other fields omitted
+// FailureInfo describes the failure of a query as reported by Trino,
+// including its server-side stack trace and causes.
+type FailureInfo struct {
+	Type          string         `json:"type"`
+	Message       string         `json:"message"`
+	Cause         *FailureInfo   `json:"cause"`
+	Suppressed    []FailureInfo  `json:"suppressed"`
+	Stack         []string       `json:"stack"`
+	ErrorLocation *ErrorLocation `json:"errorLocation"`
 }
 
 func (e stmtError) Error() string {
@@ -629,7 +1172,7 @@ func (st *driverStmt) Query(args []driver.Value) (driver.Rows, error) {
 }
 
 func (st *driverStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
-	sr, err := st.exec(ctx, args)
+	sr, err := st.submit(ctx, args)
 	if err != nil {
 		return nil, err
 	}
@@ -638,8 +1181,31 @@ func (st *driverStmt) QueryContext(ctx context.Context, args []driver.NamedValue
 		stmt:    st,
 		queryID: sr.ID,
 		nextURI: sr.NextURI,
+		started: sr.started,
+		stats:   sr.Stats,
+		cookies: sr.cookies,
+
+		cacheKey: sr.cacheKey,
+	}
+	rows.track()
+	if sr.cached != nil {
+		rows.initCached(sr.cached)
+		return rows, nil
+	}
+	// rows returned with the submission are available right away, without
+	// waiting for the next page
+	if err = rows.initFirstPage(sr); err != nil {
+		rows.untrack()
+		return nil, err
+	}
+	if st.conn.maxBufferedRows > 0 || st.conn.maxBufferedBytes > 0 || st.conn.spillDir != "" {
+		rows.startPrefetch(st.conn.maxBufferedRows, st.conn.maxBufferedBytes, st.conn.spillDir)
+	}
+	if len(rows.data) > 0 {
+		return rows, nil
 	}
 	if err = rows.fetch(false); err != nil {
+		rows.untrack()
 		return nil, err
 	}
 	return rows, nil
@@ -648,12 +1214,29 @@ func (st *driverStmt) QueryContext(ctx context.Context, args []driver.NamedValue
 func (st *driverStmt) exec(ctx context.Context, args []driver.NamedValue) (*stmtResponse, error) {
 	query := st.query
 	var hs http.Header
+	st.user = "" // set by the arguments of each execution
+
+	if st.conn.readOnly && !st.internal {
+		if err := checkReadOnly(query); err != nil {
+			return nil, err
+		}
+	}
+	if st.conn.statementFilter != nil && !st.internal {
+		if err := st.conn.statementFilter(query); err != nil {
+			return nil, err
+		}
+	}
+	if err := st.conn.verifyCoordinator(ctx); err != nil {
+		return nil, err
+	}
 
 	if len(args) > 0 {
 		hs = make(http.Header)
 		var ss []string
+		serialOptions := st.conn.serialOptions
+		serialOptions.hexStrings = st.conn.safeParameters
 		for _, arg := range args {
-			s, err := Serial(arg.Value)
+			s, err := serialOptions.Serial(arg.Value)
 			if err != nil {
 				return nil, err
 			}
@@ -668,34 +1251,92 @@ func (st *driverStmt) exec(ctx context.Context, args []driver.NamedValue) (*stmt
 				hs.Add(arg.Name, headerValue)
 			} else {
 				if hs.Get(preparedStatementHeader) == "" {
-					hs.Add(preparedStatementHeader, preparedStatementName+"="+url.QueryEscape(st.query))
+					hs.Add(preparedStatementHeader, st.preparedStatement())
 				}
 				ss = append(ss, s)
 			}
 		}
 		if len(ss) > 0 {
 			query = "EXECUTE " + preparedStatementName + " USING " + strings.Join(ss, ", ")
+			if limit := st.conn.maxHeaderBytes; limit > 0 && len(hs.Get(preparedStatementHeader)) > limit {
+				// too large for a header, send the statement in the body instead
+				hs.Del(preparedStatementHeader)
+				quoted, _ := Serial(st.query)
+				query = "EXECUTE IMMEDIATE " + quoted + " USING " + strings.Join(ss, ", ")
+			}
+		}
+	}
+	if key := idempotencyKeyFromContext(ctx); key != "" {
+		if err := st.conn.checkIdempotencyKey(ctx, key, st.user); err != nil {
+			return nil, err
 		}
+		query = idempotencyMarker(key) + query
+	}
+	if limit := st.conn.maxStatementBytes; limit > 0 && len(query) > limit {
+		return nil, &ErrStatementTooLarge{Size: len(query), Limit: limit}
 	}
 
-	req, err := st.conn.newRequest("POST", st.conn.baseURL+"/v1/statement", strings.NewReader(query), hs)
+	hs, err := st.conn.addContextSessionProperties(ctx, hs)
+	if err != nil {
+		return nil, err
+	}
+	if group := routingGroupFromContext(ctx); group != "" {
+		if hs == nil {
+			hs = make(http.Header)
+		}
+		hs.Set(trinoRoutingGroupHeader, group)
+	}
+	hs, err = addContextTags(ctx, hs)
 	if err != nil {
 		return nil, err
 	}
 
+	started := time.Now()
+	compress := st.conn.gzipStatement(query)
+	req, err := st.conn.newStatementRequest(query, hs, compress)
+	if err != nil {
+		return nil, err
+	}
+	var cacheKey string
+	if st.conn.resultCache != nil && st.cacheable() {
+		// headers set by ExtraHeadersFunc, e.g. the tenant, are part of the
+		// key, they are set again by roundTrip
+		if err := st.conn.addExtraHeaders(ctx, req); err != nil {
+			return nil, err
+		}
+		cacheKey = st.conn.resultCacheKey(query, req.Header)
+		if result, ok := st.conn.resultCache.Get(cacheKey); ok {
+			return &stmtResponse{ID: result.QueryID, started: started, cached: result}, nil
+		}
+	}
+
 	resp, err := st.conn.roundTrip(ctx, req)
+	if err != nil && compress && isGzipRejected(err) {
+		st.conn.gzipRejected = true
+		if req, err = st.conn.newStatementRequest(query, hs, false); err != nil {
+			return nil, err
+		}
+		resp, err = st.conn.roundTrip(ctx, req)
+	}
 	if err != nil {
+		markUnsent(err)
 		return nil, err
 	}
 
 	defer resp.Body.Close()
+	body := &countingReader{r: resp.Body, limit: st.conn.maxResponseBytes}
 	var sr stmtResponse
-	d := json.NewDecoder(resp.Body)
-	d.UseNumber()
-	err = d.Decode(&sr)
+	err = st.conn.jsonDecoder.Decode(body, &sr)
+	if body.err != nil {
+		return nil, body.err
+	}
 	if err != nil {
 		return nil, fmt.Errorf("trino: %v", err)
 	}
+	sr.started = started
+	sr.cookies = resp.Cookies()
+	sr.size = body.n
+	sr.cacheKey = cacheKey
 	return &sr, handleResponseError(resp.StatusCode, sr.Error)
 }
 
@@ -709,8 +1350,27 @@ type driverRows struct {
 	rowindex     int
 	columns      []string
 	coltype      []*typeConverter
+	readers      []columnReader // nil for columns without a typed reader
 	data         []queryData
 	rowsAffected int64
+	updateType   string
+	started      time.Time
+	stats        stmtStats
+	completed    bool
+	prefetcher   *prefetcher
+	cookies      []*http.Cookie // sent back on every request of the query
+	tracked      bool           // registered in the tracker of the connection
+	backoff      *pollBackoff
+	failure      error // failure of the query, returned after the rows received with it
+	fetchStats   FetchStats
+	reported     bool // whether the fetch statistics were reported
+
+	zeroCopyStrings bool
+	maxRows         int64 // rows allowed by WithMaxRows, if positive
+
+	cached    bool            // served from the ResultCache
+	cacheKey  string          // key of the result in the ResultCache, if it is to be stored
+	cacheRows [][]interface{} // rows read so far, stored in the ResultCache once all are read
 }
 
 var _ driver.Rows = &driverRows{}
@@ -718,10 +1378,21 @@ var _ driver.Result = &driverRows{}
 
 // Close closes the rows iterator.
 func (qr *driverRows) Close() error {
-	if qr.err == sql.ErrNoRows || qr.err == io.EOF {
+	defer qr.untrack()
+	if qr.prefetcher != nil {
+		qr.prefetcher.close()
+		qr.fetchStats.DiscardedPages, qr.fetchStats.DiscardedBytes = qr.prefetcher.discarded()
+	}
+	if qr.err == io.EOF || qr.cached {
+		qr.err = io.EOF
+		qr.reportFetchStats()
 		return nil
 	}
 	qr.err = io.EOF
+	running := qr.nextURI != ""
+	qr.fetchStats.Cancelled = running
+	qr.reportFetchStats()
+	qr.complete()
 	hs := make(http.Header)
 	if qr.stmt.user != "" {
 		hs.Add(trinoUserHeader, qr.stmt.user)
@@ -730,6 +1401,7 @@ func (qr *driverRows) Close() error {
 	if err != nil {
 		return err
 	}
+	addCookies(req, qr.cookies)
 	ctx, cancel := context.WithTimeout(context.Background(), DefaultCancelQueryTimeout)
 	defer cancel()
 	resp, err := qr.stmt.conn.roundTrip(ctx, req)
@@ -737,11 +1409,17 @@ func (qr *driverRows) Close() error {
 		qferr, ok := err.(*ErrQueryFailed)
 		if ok && qferr.StatusCode == http.StatusNoContent {
 			qr.nextURI = ""
-			return nil
+			err = nil
+		}
+		if qr.stmt.conn.confirmCancel && running {
+			return qr.confirmCancel(ctx, err)
 		}
 		return err
 	}
 	resp.Body.Close()
+	if qr.stmt.conn.confirmCancel && running {
+		return qr.confirmCancel(ctx, nil)
+	}
 	return qr.err
 }
 
@@ -779,7 +1457,13 @@ func (qr *driverRows) Next(dest []driver.Value) error {
 		return qr.err
 	}
 	if qr.columns == nil || qr.rowindex >= len(qr.data) {
+		if qr.failure != nil {
+			qr.err = qr.failure
+			return qr.err
+		}
 		if qr.nextURI == "" {
+			qr.complete()
+			qr.storeResult()
 			qr.err = io.EOF
 			return qr.err
 		}
@@ -789,18 +1473,43 @@ func (qr *driverRows) Next(dest []driver.Value) error {
 		}
 	}
 	if len(qr.coltype) == 0 {
-		qr.err = sql.ErrNoRows
+		// statements without results, such as DDL, have no rows
+		qr.err = io.EOF
 		return qr.err
 	}
-	for i, v := range qr.coltype {
-		vv, err := v.ConvertValue(qr.data[qr.rowindex][i])
-		if err != nil {
+	if qr.maxRows > 0 && qr.fetchStats.RowsReturned >= qr.maxRows {
+		qr.err = ErrRowLimitExceeded
+		return qr.err
+	}
+	if len(qr.data[qr.rowindex]) != len(qr.coltype) {
+		qr.err = fmt.Errorf("trino: malformed row with %d values for %d columns", len(qr.data[qr.rowindex]), len(qr.coltype))
+		return qr.err
+	}
+	for i := range qr.coltype {
+		if err := qr.checkValueSize(i, qr.data[qr.rowindex][i]); err != nil {
 			qr.err = err
 			return err
 		}
+		if raw, ok := qr.data[qr.rowindex][i].(json.RawMessage); ok {
+			dest[i] = rawDriverValue(raw)
+			continue
+		}
+		vv, ok := qr.readColumn(i, qr.data[qr.rowindex][i])
+		if !ok {
+			var err error
+			vv, err = qr.convertColumn(i, qr.data[qr.rowindex][i])
+			if err != nil {
+				qr.err = err
+				return err
+			}
+		}
+		if s, ok := vv.(string); ok && qr.zeroCopyStrings {
+			vv = unsafeBytes(s)
+		}
 		dest[i] = vv
 	}
 	qr.rowindex++
+	qr.fetchStats.RowsReturned++
 	return nil
 }
 
@@ -850,52 +1559,88 @@ func handleResponseError(status int, respErr stmtError) error {
 	case "USER_CANCELLED":
 		return ErrQueryCancelled
 	default:
+		location := respErr.ErrorLocation
+		if location == nil {
+			location = respErr.FailureInfo.ErrorLocation
+		}
 		return &ErrQueryFailed{
-			StatusCode: status,
-			Reason:     &respErr,
+			StatusCode:  status,
+			Reason:      &respErr,
+			ErrorName:   respErr.ErrorName,
+			ErrorCode:   respErr.ErrorCode,
+			ErrorType:   respErr.ErrorType,
+			Location:    location,
+			FailureInfo: &respErr.FailureInfo,
 		}
 	}
 }
 
 func (qr *driverRows) fetch(allowEOF bool) error {
 	if qr.nextURI == "" {
+		if qr.failure != nil {
+			return qr.failure
+		}
+		qr.complete()
 		if allowEOF {
 			return io.EOF
 		}
 		return nil
 	}
-	hs := make(http.Header)
-	hs.Add(trinoUserHeader, qr.stmt.user)
-	req, err := qr.stmt.conn.newRequest("GET", qr.nextURI, nil, hs)
-	if err != nil {
-		return err
+	var qresp *queryResponse
+	var status int
+	var size int64
+	var err error
+	if qr.prefetcher != nil {
+		qresp, status, size, err = qr.prefetcher.next(qr)
+	} else {
+		qresp, status, size, err = qr.fetchPage(qr.ctx, qr.nextURI, qr.columns)
 	}
-	resp, err := qr.stmt.conn.roundTrip(qr.ctx, req)
 	if err != nil {
-		if qr.ctx.Err() == context.Canceled {
-			qr.Close()
-			return err
+		if qr.ctx.Err() == context.Canceled || qr.ctx.Err() != nil && qr.stmt.conn.confirmCancel {
+			// with confirm_cancel, the query is also cancelled on deadline,
+			// and the failure to confirm it is reported instead of err
+			var nerr *ErrCancelNotConfirmed
+			if errors.As(qr.Close(), &nerr) {
+				return nerr
+			}
+			return qr.queryTimeout(err)
 		}
-		return err
+		return qr.queryTimeout(err)
 	}
-	defer resp.Body.Close()
-	var qresp queryResponse
-	d := json.NewDecoder(resp.Body)
-	d.UseNumber()
-	err = d.Decode(&qresp)
+	qr.countPage(len(qresp.Data), size)
+	err = handleResponseError(status, qresp.Error)
 	if err != nil {
-		return fmt.Errorf("trino: %v", err)
-	}
-	err = handleResponseError(resp.StatusCode, qresp.Error)
-	if err != nil {
-		return err
+		if !qr.stmt.conn.partialResults || len(qresp.Data) == 0 {
+			return err
+		}
+		// return the rows received with the failure before it
+		qr.failure = err
+		qresp.NextURI = ""
 	}
 
 	qr.rowindex = 0
 	qr.data = qresp.Data
+	qr.cachePage(qr.data)
 	qr.nextURI = qresp.NextURI
+	qr.stats = qresp.Stats
+	qr.reportProgress()
+	qr.rowsAffected = qresp.UpdateCount
+	if qresp.UpdateType != "" {
+		qr.updateType = qresp.UpdateType
+	}
+	if qr.nextURI == "" {
+		qr.complete()
+	}
 	if len(qr.data) == 0 {
 		if qr.nextURI != "" {
+			if qr.prefetcher == nil {
+				if qr.backoff == nil {
+					qr.backoff = newPollBackoff(qr.stmt.conn.pollInterval, qr.stmt.conn.maxPollInterval)
+				}
+				if err := qr.backoff.wait(qr.ctx); err != nil {
+					return err
+				}
+			}
 			return qr.fetch(allowEOF)
 		}
 		if allowEOF {
@@ -903,32 +1648,156 @@ func (qr *driverRows) fetch(allowEOF bool) error {
 			return qr.err
 		}
 	}
+	qr.backoff.reset()
 	if qr.columns == nil && len(qresp.Columns) > 0 {
-		qr.initColumns(&qresp)
+		qr.initColumns(qresp)
 	}
-	qr.rowsAffected = qresp.UpdateCount
 	return nil
 }
 
+// initFirstPage sets the columns and the rows of the response to the
+// submission of the query, if any.
+func (qr *driverRows) initFirstPage(sr *stmtResponse) error {
+	qr.countPage(len(sr.Data), sr.size)
+	if len(sr.Columns) == 0 {
+		return nil
+	}
+	qresp := &queryResponse{Columns: sr.Columns}
+	qr.initColumns(qresp)
+	data, err := qr.decodeData(sr.Data, qr.columns)
+	if err != nil {
+		return fmt.Errorf("trino: %v", err)
+	}
+	qr.data = data
+	qr.cachePage(qr.data)
+	if qr.nextURI == "" {
+		qr.complete()
+	}
+	return nil
+}
+
+// fetchPage fetches and decodes the page at uri, returning the response,
+// its HTTP status code and its size in bytes. The columns are used to decode
+// values when the response does not include them.
+func (qr *driverRows) fetchPage(ctx context.Context, uri string, columns []string) (*queryResponse, int, int64, error) {
+	qresp, h, status, size, err := qr.requestPage(ctx, uri, columns, qr.stmt.conn.httpHeaders, qr.cookies, nil)
+	if h != nil {
+		if err := qr.applyPageHeader(h); err != nil {
+			return nil, 0, 0, err
+		}
+	}
+	return qresp, status, size, err
+}
+
+// requestPage fetches and decodes the page at uri like fetchPage, sending
+// the given session headers and cookies, and also writing the response to w,
+// if not nil. The headers of the response are
+// returned without being applied to the session of the connection and the
+// cookies of the query, so that pages can be fetched by another goroutine
+// than the one using the connection, see applyPageHeader.
+func (qr *driverRows) requestPage(ctx context.Context, uri string, columns []string, session http.Header, cookies []*http.Cookie, w io.Writer) (*queryResponse, http.Header, int, int64, error) {
+	uri, err := qr.stmt.conn.rewriteNextURI(uri)
+	if err != nil {
+		return nil, nil, 0, 0, err
+	}
+	uri, err = qr.stmt.conn.addPageHints(uri)
+	if err != nil {
+		return nil, nil, 0, 0, err
+	}
+	hs := make(http.Header)
+	hs.Add(trinoUserHeader, qr.stmt.user)
+	req, err := qr.stmt.conn.newSessionRequest("GET", uri, nil, session, hs)
+	if err != nil {
+		return nil, nil, 0, 0, err
+	}
+	addCookies(req, cookies)
+	resp, err := qr.stmt.conn.sendRequest(ctx, req)
+	if err != nil {
+		return nil, nil, 0, 0, err
+	}
+	defer resp.Body.Close()
+	body := &countingReader{r: resp.Body, limit: qr.stmt.conn.maxResponseBytes}
+	var r io.Reader = body
+	if w != nil {
+		r = io.TeeReader(body, w)
+	}
+	var qresp queryResponse
+	err = qr.decodeQueryResponse(r, &qresp, columns)
+	if body.err != nil {
+		return nil, resp.Header, 0, 0, body.err
+	}
+	if err != nil {
+		return nil, resp.Header, 0, 0, fmt.Errorf("trino: %v", err)
+	}
+	return &qresp, resp.Header, resp.StatusCode, body.n, nil
+}
+
+// applyPageHeader applies the headers of the response to a page request to
+// the session of the connection, and adds the cookies they set to the ones
+// of the query.
+func (qr *driverRows) applyPageHeader(h http.Header) error {
+	if err := qr.stmt.conn.updateSession(h); err != nil {
+		return err
+	}
+	qr.cookies = mergeCookies(qr.cookies, responseCookies(h))
+	return nil
+}
+
+// complete runs the hooks for a query that finished or was closed, once.
+func (qr *driverRows) complete() {
+	if qr.completed {
+		return
+	}
+	qr.completed = true
+	if qr.nextURI == "" {
+		qr.fetchQueryInfo()
+	}
+	qr.logSlowQuery()
+	qr.reportQueryCost()
+}
+
 func (qr *driverRows) initColumns(qresp *queryResponse) {
+	qr.zeroCopyStrings = qr.zeroCopyStringsEnabled()
+	qr.maxRows = maxRowsFromContext(qr.ctx)
 	qr.columns = make([]string, len(qresp.Columns))
 	qr.coltype = make([]*typeConverter, len(qresp.Columns))
+	if !qr.stmt.conn.noTypedReaders {
+		qr.readers = make([]columnReader, len(qresp.Columns))
+	}
 	for i, col := range qresp.Columns {
 		qr.columns[i] = col.Name
 		qr.coltype[i] = newTypeConverter(col.Type)
+		qr.coltype[i].strictNumbers = qr.stmt.conn.strictNumbers
+		if qr.stmt.conn.location != nil {
+			qr.coltype[i].location = qr.stmt.conn.location
+		}
+		if qr.readers != nil {
+			qr.readers[i] = qr.coltype[i].reader()
+		}
 	}
 }
 
 type typeConverter struct {
 	typeName   string
 	parsedType []string // e.g. array, array, varchar, for [][]string
+	rowType    *typeSpec // only set for types containing rows
+
+	strictNumbers bool
+	location      *time.Location // for values without a time zone
 }
 
 func newTypeConverter(typeName string) *typeConverter {
-	return &typeConverter{
+	c := &typeConverter{
 		typeName:   typeName,
 		parsedType: parseType(typeName),
+		location:   time.Local,
 	}
+	if strings.Contains(strings.ToLower(typeName), "row(") {
+		if t, err := parseTypeSpec(typeName); err == nil && t.hasRow() {
+			c.rowType = t
+		}
+	}
+	return c
 }
 
 // parses Trino types, e.g. array(varchar(10)) to "array", "varchar"
@@ -957,13 +1826,16 @@ func (c *typeConverter) ConvertValue(v interface{}) (driver.Value, error) {
 			return nil, err
 		}
 		return vv.Bool, err
-	case "json", "char", "varchar", "varbinary", "interval year to month", "interval day to second", "decimal", "ipaddress", "unknown":
+	case "json", "char", "varchar", "varbinary", "interval year to month", "interval day to second", "decimal", "ipaddress", "uuid", "unknown":
 		vv, err := scanNullString(v)
 		if !vv.Valid {
 			return nil, err
 		}
 		return vv.String, err
 	case "tinyint", "smallint", "integer", "bigint":
+		if err := c.checkLossless(v); err != nil {
+			return nil, err
+		}
 		vv, err := scanNullInt64(v)
 		if !vv.Valid {
 			return nil, err
@@ -976,7 +1848,7 @@ func (c *typeConverter) ConvertValue(v interface{}) (driver.Value, error) {
 		}
 		return vv.Float64, err
 	case "date", "time", "time with time zone", "timestamp", "timestamp with time zone":
-		vv, err := scanNullTime(v)
+		vv, err := scanNullTimeInLocation(v, c.location)
 		if !vv.Valid {
 			return nil, err
 		}
@@ -985,14 +1857,21 @@ func (c *typeConverter) ConvertValue(v interface{}) (driver.Value, error) {
 		if err := validateMap(v); err != nil {
 			return nil, err
 		}
-		return v, nil
+		return convertRows(v, c.rowType)
 	case "array":
 		if err := validateSlice(v); err != nil {
 			return nil, err
 		}
-		return v, nil
+		return convertRows(v, c.rowType)
+	case "row":
+		if c.rowType == nil {
+			return nil, fmt.Errorf("type not supported: %q", c.typeName)
+		}
+		return convertRows(v, c.rowType)
 	default:
-		return nil, fmt.Errorf("type not supported: %q", c.typeName)
+		// pass values of other types, such as sketches and connector-specific
+		// types, as decoded from JSON, for custom sql.Scanner implementations
+		return v, nil
 	}
 }
 
@@ -1212,6 +2091,10 @@ func scanNullInt64(v interface{}) (sql.NullInt64, error) {
 	if v == nil {
 		return sql.NullInt64{}, nil
 	}
+	if vFloat, ok := v.(float64); ok {
+		// decoded by a JSONDecoder not using json.Number
+		return scanFloatInt64(vFloat)
+	}
 	vNumber, ok := v.(json.Number)
 	if !ok {
 		return sql.NullInt64{},
@@ -1321,6 +2204,10 @@ func scanNullFloat64(v interface{}) (sql.NullFloat64, error) {
 		}
 		return sql.NullFloat64{Valid: true, Float64: vFloat}, nil
 	}
+	if vFloat, ok := v.(float64); ok {
+		// decoded by a JSONDecoder not using json.Number
+		return sql.NullFloat64{Valid: true, Float64: vFloat}, nil
+	}
 	switch v {
 	case "NaN":
 		return sql.NullFloat64{Valid: true, Float64: math.NaN()}, nil
@@ -1417,13 +2304,26 @@ func (s *NullSlice3Float64) Scan(value interface{}) error {
 	return nil
 }
 
+// timeLayouts accept any precision, and truncate picoseconds to nanoseconds.
 var timeLayouts = []string{
 	"2006-01-02",
-	"15:04:05.000",
-	"2006-01-02 15:04:05.000",
+	"15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// timeOffsetLayouts parse times with a numeric offset, e.g. 01:02:03+01:00.
+var timeOffsetLayouts = []string{
+	"15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999-07:00",
 }
 
 func scanNullTime(v interface{}) (NullTime, error) {
+	return scanNullTimeInLocation(v, time.Local)
+}
+
+// scanNullTimeInLocation converts a time string, interpreting values
+// without a time zone in loc.
+func scanNullTimeInLocation(v interface{}, loc *time.Location) (NullTime, error) {
 	if v == nil {
 		return NullTime{}, nil
 	}
@@ -1435,14 +2335,19 @@ func scanNullTime(v interface{}) (NullTime, error) {
 	if len(vparts) > 1 && !unicode.IsDigit(rune(vparts[len(vparts)-1][0])) {
 		return parseNullTimeWithLocation(vv)
 	}
-	return parseNullTime(vv)
+	for _, layout := range timeOffsetLayouts {
+		if t, err := time.Parse(layout, vv); err == nil {
+			return NullTime{Valid: true, Time: t}, nil
+		}
+	}
+	return parseNullTime(vv, loc)
 }
 
-func parseNullTime(v string) (NullTime, error) {
+func parseNullTime(v string, loc *time.Location) (NullTime, error) {
 	var t time.Time
 	var err error
 	for _, layout := range timeLayouts {
-		t, err = time.ParseInLocation(layout, v, time.Local)
+		t, err = time.ParseInLocation(layout, v, loc)
 		if err == nil {
 			return NullTime{Valid: true, Time: t}, nil
 		}
@@ -1456,7 +2361,7 @@ func parseNullTimeWithLocation(v string) (NullTime, error) {
 		return NullTime{}, fmt.Errorf("cannot convert %v (%T) to time+zone", v, v)
 	}
 	stamp, location := v[:idx], v[idx+1:]
-	loc, err := time.LoadLocation(location)
+	loc, err := loadLocation(location)
 	if err != nil {
 		return NullTime{}, fmt.Errorf("cannot load timezone %q: %v", location, err)
 	}
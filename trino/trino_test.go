@@ -18,6 +18,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -130,9 +131,9 @@ func TestConnErrorDSN(t *testing.T) {
 	for _, tc := range testcases {
 		t.Run(tc.Name, func(t *testing.T) {
 			db, err := sql.Open("trino", tc.DSN)
-			require.NoError(t, err)
-
-			_, err = db.Query("SELECT 1")
+			if err == nil {
+				_, err = db.Query("SELECT 1")
+			}
 			assert.Errorf(t, err,"test dsn is supposed to fail: %s", tc.DSN)
 
 			if err == nil {
@@ -286,6 +287,239 @@ func TestQueryFailure(t *testing.T) {
 	assert.IsTypef(t, new(ErrQueryFailed), err, "unexpected error: %w", err)
 }
 
+func TestQueryFailureInfo(t *testing.T) {
+	ts := newResultTestServer(t, `
+		"error": {
+			"message": "line 1:8: Column 'foo' cannot be resolved",
+			"errorCode": 47,
+			"errorName": "COLUMN_NOT_FOUND",
+			"errorType": "USER_ERROR",
+			"errorLocation": {"lineNumber": 1, "columnNumber": 8},
+			"failureInfo": {
+				"type": "io.trino.spi.TrinoException",
+				"message": "line 1:8: Column 'foo' cannot be resolved",
+				"suppressed": [{"type": "java.lang.Exception", "message": "suppressed"}],
+				"stack": ["io.trino.sql.analyzer.SemanticExceptions.semanticException(SemanticExceptions.java:48)"],
+				"cause": {"type": "java.lang.IllegalStateException"},
+				"errorLocation": {"lineNumber": 1, "columnNumber": 8}
+			}
+		}`, nil)
+
+	db, err := sql.Open("trino", ts.URL)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	_, err = db.Query("SELECT foo")
+	qferr, ok := err.(*ErrQueryFailed)
+	require.Truef(t, ok, "unexpected error: %v", err)
+	assert.Equal(t, "COLUMN_NOT_FOUND", qferr.ErrorName)
+	assert.Equal(t, 47, qferr.ErrorCode)
+	assert.Equal(t, "USER_ERROR", qferr.ErrorType)
+	assert.Equal(t, &ErrorLocation{LineNumber: 1, ColumnNumber: 8}, qferr.Location)
+	require.NotNil(t, qferr.FailureInfo)
+	assert.Equal(t, "io.trino.spi.TrinoException", qferr.FailureInfo.Type)
+	assert.Len(t, qferr.FailureInfo.Stack, 1)
+	assert.Len(t, qferr.FailureInfo.Suppressed, 1)
+	require.NotNil(t, qferr.FailureInfo.Cause)
+	assert.Equal(t, "java.lang.IllegalStateException", qferr.FailureInfo.Cause.Type)
+}
+
+func TestQueryErrors(t *testing.T) {
+	for _, tc := range []struct {
+		ErrorName string
+		Expected  error
+	}{
+		{ErrorName: "USER_CANCELLED", Expected: ErrQueryCancelled},
+		{ErrorName: "ADMINISTRATIVELY_KILLED", Expected: ErrQueryKilled},
+		{ErrorName: "ADMINISTRATIVELY_PREEMPTED", Expected: ErrQueryKilled},
+		{ErrorName: "EXCEEDED_TIME_LIMIT", Expected: ErrQueryTimeout},
+	} {
+		t.Run(tc.ErrorName, func(t *testing.T) {
+			ts := newResultTestServer(t, `"error": {"errorName": "`+tc.ErrorName+`"}`, nil)
+			db, err := sql.Open("trino", ts.URL)
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				assert.NoError(t, db.Close())
+			})
+
+			_, err = db.Query("SELECT 1")
+			for _, sentinel := range []error{ErrQueryCancelled, ErrQueryKilled, ErrQueryTimeout, context.Canceled, context.DeadlineExceeded} {
+				assert.Equal(t, sentinel == tc.Expected, errors.Is(err, sentinel), "errors.Is(%v, %v)", err, sentinel)
+			}
+		})
+	}
+}
+
+func TestQueryContextErrors(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(ts.Close)
+	t.Cleanup(func() {
+		close(release)
+	})
+	db, err := sql.Open("trino", ts.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = db.QueryContext(ctx, "SELECT 1")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "unexpected error: %v", err)
+	assert.False(t, errors.Is(err, ErrQueryTimeout))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err = db.QueryContext(ctx, "SELECT 1")
+	assert.True(t, errors.Is(err, context.Canceled), "unexpected error: %v", err)
+	assert.False(t, errors.Is(err, ErrQueryCancelled))
+}
+
+func TestPartialResults(t *testing.T) {
+	ts := newPagedResultTestServer(t, []string{
+		`"columns": [{"name": "n", "type": "integer"}], "data": [[1], [2]]`,
+		`"data": [[3]], "error": {"message": "Query exceeded maximum time limit", "errorName": "EXCEEDED_TIME_LIMIT", "errorType": "INSUFFICIENT_RESOURCES"}`,
+	}, nil)
+
+	for _, tc := range []struct {
+		DSN      string
+		Expected []int
+	}{
+		{DSN: ts.URL, Expected: []int{1, 2}},
+		{DSN: ts.URL + "?partial_results=true", Expected: []int{1, 2, 3}},
+	} {
+		t.Run(tc.DSN, func(t *testing.T) {
+			db, err := sql.Open("trino", tc.DSN)
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				assert.NoError(t, db.Close())
+			})
+
+			rows, err := db.Query("SELECT n FROM t")
+			require.NoError(t, err)
+			defer rows.Close()
+			var got []int
+			for rows.Next() {
+				var n int
+				require.NoError(t, rows.Scan(&n))
+				got = append(got, n)
+			}
+			assert.Equal(t, tc.Expected, got)
+			var qerr *ErrQueryFailed
+			require.True(t, errors.As(rows.Err(), &qerr), "unexpected error: %v", rows.Err())
+			assert.Equal(t, "EXCEEDED_TIME_LIMIT", qerr.ErrorName)
+		})
+	}
+}
+
+// newFirstPageTestServer returns a server returning rows with the
+// submission of a query, and the last row in the next page, once release
+// is closed, if not nil.
+func newFirstPageTestServer(tb testing.TB, release <-chan struct{}) *httptest.Server {
+	hooks := []testServerHook{func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path != "/v1/statement" {
+			return false
+		}
+		writeTestPage(w, "http://"+r.Host, `
+			"columns": [{"name": "n", "type": "integer"}, {"name": "s", "type": "varchar"}],
+			"data": [[1, "a"], [2, "b"]],
+			"stats": {"state": "QUEUED"}`, 1)
+		return true
+	}}
+	if release != nil {
+		hooks = append(hooks, blockPage(1, release))
+	}
+	return newPagedResultTestServer(tb, []string{`"data": [[3, "c"]]`}, nil, hooks...)
+}
+
+func TestFirstPageData(t *testing.T) {
+	for _, dsn := range []string{"", "?max_buffered_rows=10"} {
+		t.Run(dsn, func(t *testing.T) {
+			release := make(chan struct{})
+			ts := newFirstPageTestServer(t, release)
+			t.Cleanup(func() {
+				select {
+				case <-release:
+				default:
+					close(release)
+				}
+			})
+			db, err := sql.Open("trino", ts.URL+dsn)
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				assert.NoError(t, db.Close())
+			})
+
+			ctx := WithScannedColumns(context.Background(), "n")
+			rows, err := db.QueryContext(ctx, "SELECT n, s FROM t")
+			require.NoError(t, err)
+			defer rows.Close()
+			var got []int
+			for len(got) < 2 && rows.Next() {
+				var n int
+				var s sql.NullString
+				require.NoError(t, rows.Scan(&n, &s))
+				assert.False(t, s.Valid)
+				got = append(got, n)
+			}
+			// the rows of the submission do not wait for the next page
+			assert.Equal(t, []int{1, 2}, got)
+
+			close(release)
+			for rows.Next() {
+				var n int
+				var s sql.NullString
+				require.NoError(t, rows.Scan(&n, &s))
+				assert.False(t, s.Valid)
+				got = append(got, n)
+			}
+			require.NoError(t, rows.Err())
+			assert.Equal(t, []int{1, 2, 3}, got)
+		})
+	}
+}
+
+func BenchmarkFirstRow(b *testing.B) {
+	ts := newFirstPageTestServer(b, nil)
+	db, err := sql.Open("trino", ts.URL)
+	require.NoError(b, err)
+	b.Cleanup(func() {
+		db.Close()
+	})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rows, err := db.Query("SELECT n, s FROM t")
+		if err != nil {
+			b.Fatal(err)
+		}
+		if !rows.Next() {
+			b.Fatal(rows.Err())
+		}
+		rows.Close()
+	}
+}
+
+func TestQueryWithoutColumns(t *testing.T) {
+	ts := newResultTestServer(t, `"updateType": "CREATE TABLE"`, nil)
+	db := openTestDB(t, ts)
+
+	rows, err := db.Query("CREATE TABLE t (n bigint)")
+	require.NoError(t, err)
+	assert.False(t, rows.Next())
+	assert.NoError(t, rows.Err())
+	assert.NoError(t, rows.Close())
+
+	var n int64
+	assert.Equal(t, sql.ErrNoRows, db.QueryRow("CREATE TABLE t (n bigint)").Scan(&n))
+}
+
 func TestUnsupportedHeader(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set(trinoSetRoleHeader, "foo")
@@ -0,0 +1,330 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// typeSpec is a parsed Trino type, such as array(row(x integer, y varchar)).
+type typeSpec struct {
+	name       string      // e.g. array, row, decimal, timestamp with time zone
+	args       []*typeSpec // type arguments, e.g. the element type of an array
+	literals   []string    // literal arguments, e.g. the precision of a decimal
+	fieldNames []string    // names of the fields of a row, empty if unnamed
+}
+
+// parseTypeSpec parses a Trino type name.
+func parseTypeSpec(s string) (*typeSpec, error) {
+	p := &typeParser{s: s}
+	t, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpaces(); p.pos != len(p.s) {
+		return nil, fmt.Errorf("trino: malformed type %q", s)
+	}
+	return t, nil
+}
+
+type typeParser struct {
+	s   string
+	pos int
+}
+
+func (p *typeParser) skipSpaces() {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// word reads a name, up to a parenthesis, comma or space, or a quoted name.
+func (p *typeParser) word() (string, error) {
+	p.skipSpaces()
+	if p.pos < len(p.s) && p.s[p.pos] == '"' {
+		var b strings.Builder
+		for p.pos++; p.pos < len(p.s); p.pos++ {
+			if p.s[p.pos] == '"' {
+				if p.pos+1 < len(p.s) && p.s[p.pos+1] == '"' {
+					b.WriteByte('"')
+					p.pos++
+					continue
+				}
+				p.pos++
+				return b.String(), nil
+			}
+			b.WriteByte(p.s[p.pos])
+		}
+		return "", fmt.Errorf("trino: malformed type %q", p.s)
+	}
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune("(), ", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	return p.s[start:p.pos], nil
+}
+
+func (p *typeParser) parse() (*typeSpec, error) {
+	name, err := p.word()
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, fmt.Errorf("trino: malformed type %q", p.s)
+	}
+	t := &typeSpec{name: strings.ToLower(name)}
+	if p.pos < len(p.s) && p.s[p.pos] == '(' {
+		p.pos++
+		if err := p.parseArgs(t); err != nil {
+			return nil, err
+		}
+	}
+	// multi-word types, e.g. timestamp(3) with time zone, or interval day to second
+	for {
+		save := p.pos
+		p.skipSpaces()
+		if p.pos == len(p.s) || strings.ContainsRune("),", rune(p.s[p.pos])) {
+			p.pos = save
+			return t, nil
+		}
+		word, err := p.word()
+		if err != nil {
+			return nil, err
+		}
+		t.name += " " + strings.ToLower(word)
+	}
+}
+
+func (p *typeParser) parseArgs(t *typeSpec) error {
+	for {
+		p.skipSpaces()
+		start := p.pos
+		if t.name == "row" {
+			// fields are either "name type" or just "type"
+			name, err := p.word()
+			if err != nil {
+				return err
+			}
+			p.skipSpaces()
+			if p.pos == len(p.s) || strings.ContainsRune("(),", rune(p.s[p.pos])) {
+				name, p.pos = "", start
+			}
+			field, err := p.parse()
+			if err != nil {
+				return err
+			}
+			t.fieldNames = append(t.fieldNames, name)
+			t.args = append(t.args, field)
+		} else if p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+			lit, err := p.word()
+			if err != nil {
+				return err
+			}
+			t.literals = append(t.literals, lit)
+		} else {
+			arg, err := p.parse()
+			if err != nil {
+				return err
+			}
+			t.args = append(t.args, arg)
+		}
+		p.skipSpaces()
+		if p.pos == len(p.s) {
+			return fmt.Errorf("trino: malformed type %q", p.s)
+		}
+		switch p.s[p.pos] {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			return nil
+		default:
+			return fmt.Errorf("trino: malformed type %q", p.s)
+		}
+	}
+}
+
+// fieldName returns the name of the i-th field of a row, naming unnamed
+// fields like the Trino CLI does.
+func (t *typeSpec) fieldName(i int) string {
+	if i < len(t.fieldNames) && t.fieldNames[i] != "" {
+		return t.fieldNames[i]
+	}
+	return fmt.Sprintf("field%d", i)
+}
+
+// hasRow returns whether the type is or contains a row.
+func (t *typeSpec) hasRow() bool {
+	if t.name == "row" {
+		return true
+	}
+	for _, arg := range t.args {
+		if arg.hasRow() {
+			return true
+		}
+	}
+	return false
+}
+
+// TypeSignature is a parsed Trino type, such as decimal(38,10),
+// array(row(x integer, y varchar)) or timestamp(6) with time zone.
+type TypeSignature struct {
+	Name       string           // Base type, e.g. decimal, array or timestamp with time zone
+	Parameters []int64          // Numeric parameters, e.g. the precision and scale of a decimal
+	Arguments  []*TypeSignature // Types of the elements of arrays, of the keys and values of maps, and of the fields of rows
+	FieldNames []string         // Names of the fields of rows, empty for unnamed fields
+}
+
+// ParseTypeSignature parses a Trino type name.
+func ParseTypeSignature(name string) (*TypeSignature, error) {
+	spec, err := parseTypeSpec(name)
+	if err != nil {
+		return nil, err
+	}
+	return spec.signature(name)
+}
+
+func (t *typeSpec) signature(raw string) (*TypeSignature, error) {
+	sig := &TypeSignature{Name: t.name}
+	for _, lit := range t.literals {
+		n, err := strconv.ParseInt(lit, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("trino: malformed type %q", raw)
+		}
+		sig.Parameters = append(sig.Parameters, n)
+	}
+	for _, arg := range t.args {
+		argSig, err := arg.signature(raw)
+		if err != nil {
+			return nil, err
+		}
+		sig.Arguments = append(sig.Arguments, argSig)
+	}
+	if t.name == "row" {
+		sig.FieldNames = t.fieldNames
+	}
+	return sig, nil
+}
+
+var plainFieldName = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// String returns the type as it is written in DDL statements.
+func (t *TypeSignature) String() string {
+	var params []string
+	for _, p := range t.Parameters {
+		params = append(params, strconv.FormatInt(p, 10))
+	}
+	for i, arg := range t.Arguments {
+		s := arg.String()
+		if i < len(t.FieldNames) && t.FieldNames[i] != "" {
+			name := t.FieldNames[i]
+			if !plainFieldName.MatchString(name) {
+				name = QuoteIdentifier(name)
+			}
+			s = name + " " + s
+		}
+		params = append(params, s)
+	}
+	if len(params) == 0 {
+		return t.Name
+	}
+	// parameters follow the first word, e.g. timestamp(3) with time zone
+	first, rest := t.Name, ""
+	if i := strings.IndexByte(t.Name, ' '); i >= 0 {
+		first, rest = t.Name[:i], t.Name[i:]
+	}
+	return first + "(" + strings.Join(params, ",") + ")" + rest
+}
+
+var _ driver.RowsColumnTypeLength = &driverRows{}
+var _ driver.RowsColumnTypePrecisionScale = &driverRows{}
+
+// ColumnTypeLength implements the driver.RowsColumnTypeLength interface,
+// returning the length of char and varchar columns, and math.MaxInt64 for
+// unbounded varchar and varbinary columns.
+func (qr *driverRows) ColumnTypeLength(index int) (int64, bool) {
+	sig, err := ParseTypeSignature(qr.coltype[index].typeName)
+	if err != nil {
+		return 0, false
+	}
+	switch sig.Name {
+	case "char", "varchar":
+		if len(sig.Parameters) == 1 {
+			return sig.Parameters[0], true
+		}
+		return math.MaxInt64, true
+	case "varbinary":
+		return math.MaxInt64, true
+	}
+	return 0, false
+}
+
+// ColumnTypePrecisionScale implements the driver.RowsColumnTypePrecisionScale
+// interface, returning the precision and scale of decimal columns, and the
+// precision of time and timestamp columns.
+func (qr *driverRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	sig, err := ParseTypeSignature(qr.coltype[index].typeName)
+	if err != nil {
+		return 0, 0, false
+	}
+	switch sig.Name {
+	case "decimal":
+		if len(sig.Parameters) == 2 {
+			return sig.Parameters[0], sig.Parameters[1], true
+		}
+		if len(sig.Parameters) == 1 {
+			return sig.Parameters[0], 0, true
+		}
+	case "time", "time with time zone", "timestamp", "timestamp with time zone":
+		if len(sig.Parameters) == 1 {
+			return sig.Parameters[0], 0, true
+		}
+	}
+	return 0, 0, false
+}
+
+// ColumnTypeSignature returns the full type signature of a column of a
+// Trino query, including the parameters that DatabaseTypeName omits, such
+// as the length of varchar(10), using its length or precision.
+func ColumnTypeSignature(ct *sql.ColumnType) (*TypeSignature, error) {
+	sig, err := ParseTypeSignature(ct.DatabaseTypeName())
+	if err != nil {
+		return nil, err
+	}
+	if len(sig.Parameters) > 0 || len(sig.Arguments) > 0 {
+		return sig, nil
+	}
+	switch sig.Name {
+	case "char", "varchar":
+		if n, ok := ct.Length(); ok && n != math.MaxInt64 {
+			sig.Parameters = []int64{n}
+		}
+	case "decimal":
+		if p, s, ok := ct.DecimalSize(); ok {
+			sig.Parameters = []int64{p, s}
+		}
+	case "time", "timestamp":
+		if p, _, ok := ct.DecimalSize(); ok {
+			sig.Parameters = []int64{p}
+		}
+	}
+	return sig, nil
+}
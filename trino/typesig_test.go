@@ -0,0 +1,104 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTypeSpec(t *testing.T) {
+	spec, err := parseTypeSpec(`array(row(x integer, "y z" varchar(10), ts timestamp(3) with time zone, map(varchar, decimal(38,10))))`)
+	require.NoError(t, err)
+	assert.Equal(t, &typeSpec{
+		name: "array",
+		args: []*typeSpec{{
+			name:       "row",
+			fieldNames: []string{"x", "y z", "ts", ""},
+			args: []*typeSpec{
+				{name: "integer"},
+				{name: "varchar", literals: []string{"10"}},
+				{name: "timestamp with time zone", literals: []string{"3"}},
+				{name: "map", args: []*typeSpec{
+					{name: "varchar"},
+					{name: "decimal", literals: []string{"38", "10"}},
+				}},
+			},
+		}},
+	}, spec)
+	assert.True(t, spec.hasRow())
+	assert.Equal(t, "field3", spec.args[0].fieldName(3))
+
+	for _, malformed := range []string{"", "array(integer", "row(x integer,)", "map(varchar, integer))"} {
+		_, err := parseTypeSpec(malformed)
+		assert.Error(t, err, malformed)
+	}
+}
+
+func TestTypeSignatureString(t *testing.T) {
+	for _, name := range []string{
+		"bigint",
+		"decimal(38,10)",
+		"varchar(10)",
+		"timestamp(6) with time zone",
+		"interval day to second",
+		"array(row(x integer,y varchar))",
+		`row("y z" varchar(10),map(varchar,array(bigint)),ts time(3) with time zone)`,
+	} {
+		sig, err := ParseTypeSignature(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, name, sig.String())
+	}
+
+	sig, err := ParseTypeSignature("timestamp(6) with time zone")
+	require.NoError(t, err)
+	assert.Equal(t, &TypeSignature{Name: "timestamp with time zone", Parameters: []int64{6}}, sig)
+
+	_, err = ParseTypeSignature("varchar(1.5)")
+	assert.Error(t, err)
+}
+
+func TestColumnTypeSignature(t *testing.T) {
+	ts := newResultTestServer(t, `
+		"columns": [
+			{"name": "s", "type": "varchar(10)"},
+			{"name": "d", "type": "decimal(10)"},
+			{"name": "ts", "type": "timestamp(6)"},
+			{"name": "r", "type": "array(row(x integer, y varchar))"},
+			{"name": "v", "type": "varchar"}
+		],
+		"data": []`, nil)
+	db := openTestDB(t, ts)
+
+	rows, err := db.Query("SELECT *")
+	require.NoError(t, err)
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	require.NoError(t, err)
+
+	var names []string
+	for _, ct := range types {
+		sig, err := ColumnTypeSignature(ct)
+		require.NoError(t, err)
+		names = append(names, sig.String())
+	}
+	assert.Equal(t, []string{"varchar(10)", "decimal(10,0)", "timestamp(6)", "array(row(x integer,y varchar))", "varchar"}, names)
+
+	precision, scale, ok := types[1].DecimalSize()
+	assert.True(t, ok)
+	assert.Equal(t, []int64{10, 0}, []int64{precision, scale})
+}
@@ -0,0 +1,138 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Execer is implemented by *sql.DB, *sql.Conn and *sql.Tx, and is used by
+// the helpers that run statements on behalf of the application.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// UpdateResult is the outcome of a write statement, as reported by Trino.
+type UpdateResult struct {
+	UpdateType string // e.g. "CREATE TABLE" or "MERGE"
+	RowCount   int64
+}
+
+type updateHandlerKey struct{}
+
+func withUpdateHandler(ctx context.Context, fn func(updateType string, count int64)) context.Context {
+	return context.WithValue(ctx, updateHandlerKey{}, fn)
+}
+
+func updateHandlerFromContext(ctx context.Context) func(string, int64) {
+	fn, _ := ctx.Value(updateHandlerKey{}).(func(string, int64))
+	return fn
+}
+
+// ExecUpdate executes a statement and returns its update type and the
+// number of affected rows.
+func ExecUpdate(ctx context.Context, e Execer, query string, args ...interface{}) (*UpdateResult, error) {
+	result := &UpdateResult{}
+	ctx = withUpdateHandler(ctx, func(updateType string, count int64) {
+		result.UpdateType = updateType
+		result.RowCount = count
+	})
+	if _, err := e.ExecContext(ctx, query, args...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// StmtExecer is implemented by *sql.Stmt.
+type StmtExecer interface {
+	ExecContext(ctx context.Context, args ...interface{}) (sql.Result, error)
+}
+
+// ExecBatch executes a prepared statement once for each set of arguments,
+// in order, and returns the total number of affected rows. Executions stop
+// at the first failure, returning the result of the previous ones along
+// with the error.
+func ExecBatch(ctx context.Context, stmt StmtExecer, batch [][]interface{}) (*UpdateResult, error) {
+	result := &UpdateResult{}
+	ctx = withUpdateHandler(ctx, func(updateType string, count int64) {
+		result.UpdateType = updateType
+		result.RowCount += count
+	})
+	for i, args := range batch {
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return result, fmt.Errorf("trino: execution %d of %d of the batch failed: %w", i+1, len(batch), err)
+		}
+	}
+	return result, nil
+}
+
+// CreateTableOptions are the options of CreateTableAs.
+type CreateTableOptions struct {
+	OrReplace   bool   // Replace the table if it exists
+	IfNotExists bool   // Do nothing if the table exists
+	Comment     string // Comment of the created table
+	Properties  string // Table properties, e.g. "format = 'ORC'"
+	NoData      bool   // Only create the table, without inserting the rows
+}
+
+// CreateTableAs creates a table from the results of a query, using CREATE
+// TABLE AS SELECT, and returns the number of inserted rows.
+//
+// The table name is used as is, so it must be quoted if needed, using
+// QuoteIdentifier or QuoteQualifiedName.
+func CreateTableAs(ctx context.Context, e Execer, table, query string, opts CreateTableOptions) (*UpdateResult, error) {
+	if opts.OrReplace && opts.IfNotExists {
+		return nil, errors.New("trino: OR REPLACE and IF NOT EXISTS cannot be used together")
+	}
+	var b strings.Builder
+	b.WriteString("CREATE ")
+	if opts.OrReplace {
+		b.WriteString("OR REPLACE ")
+	}
+	b.WriteString("TABLE ")
+	if opts.IfNotExists {
+		b.WriteString("IF NOT EXISTS ")
+	}
+	b.WriteString(table)
+	if opts.Comment != "" {
+		b.WriteString(" COMMENT " + QuoteLiteral(opts.Comment))
+	}
+	if opts.Properties != "" {
+		b.WriteString(" WITH (" + opts.Properties + ")")
+	}
+	b.WriteString(" AS " + query)
+	if opts.NoData {
+		b.WriteString(" WITH NO DATA")
+	}
+	return ExecUpdate(ctx, e, b.String())
+}
+
+// Merge updates a target table from a source table or query, using MERGE,
+// and returns the number of inserted, updated and deleted rows. The clauses
+// are the WHEN clauses of the statement, such as
+// "WHEN MATCHED THEN UPDATE SET v = s.v".
+//
+// The target and source are used as is, so they must be quoted if needed.
+func Merge(ctx context.Context, e Execer, target, source, on string, clauses ...string) (*UpdateResult, error) {
+	if len(clauses) == 0 {
+		return nil, errors.New("trino: MERGE requires at least one WHEN clause")
+	}
+	query := "MERGE INTO " + target + " USING " + source + " ON " + on + " " + strings.Join(clauses, " ")
+	return ExecUpdate(ctx, e, query)
+}
@@ -0,0 +1,89 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTableAs(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"updateType": "CREATE TABLE", "updateCount": 25`, &queries)
+	db := openTestDB(t, ts)
+
+	result, err := CreateTableAs(context.Background(), db, QuoteIdentifier("copy"), "SELECT * FROM nation", CreateTableOptions{
+		IfNotExists: true,
+		Properties:  "format = 'ORC'",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &UpdateResult{UpdateType: "CREATE TABLE", RowCount: 25}, result)
+	assert.Equal(t, []string{`CREATE TABLE IF NOT EXISTS "copy" WITH (format = 'ORC') AS SELECT * FROM nation`}, queries)
+
+	_, err = CreateTableAs(context.Background(), db, "copy", "SELECT 1", CreateTableOptions{OrReplace: true, IfNotExists: true})
+	assert.Error(t, err)
+}
+
+func TestMerge(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"updateType": "MERGE", "updateCount": 3`, &queries)
+	db := openTestDB(t, ts)
+
+	result, err := Merge(context.Background(), db, "accounts t", "updates s", "t.id = s.id",
+		"WHEN MATCHED THEN UPDATE SET balance = s.balance",
+		"WHEN NOT MATCHED THEN INSERT VALUES (s.id, s.balance)")
+	require.NoError(t, err)
+	assert.Equal(t, &UpdateResult{UpdateType: "MERGE", RowCount: 3}, result)
+	assert.Equal(t, []string{"MERGE INTO accounts t USING updates s ON t.id = s.id " +
+		"WHEN MATCHED THEN UPDATE SET balance = s.balance " +
+		"WHEN NOT MATCHED THEN INSERT VALUES (s.id, s.balance)"}, queries)
+}
+
+func TestQueryArguments(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"updateType": "INSERT", "updateCount": 1`, &queries)
+	db := openTestDB(t, ts)
+
+	id := "abc"
+	_, err := db.Exec("INSERT INTO t VALUES (?, ?, ?, ?, ?)", testUUID{0, 1}, Numeric("1.5"), []int{1, 2}, &id, int8(3))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"EXECUTE _trino_go USING '00000000000000000000000000000001', 1.5, ARRAY[1, 2], 'abc', 3"}, queries)
+}
+
+func TestExecBatch(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"updateType": "INSERT", "updateCount": 1`, &queries)
+	db := openTestDB(t, ts)
+
+	stmt, err := db.Prepare("INSERT INTO t VALUES (?, ?)")
+	require.NoError(t, err)
+	defer stmt.Close()
+
+	result, err := ExecBatch(context.Background(), stmt, [][]interface{}{{1, "a"}, {2, "b"}, {3, "c"}})
+	require.NoError(t, err)
+	assert.Equal(t, &UpdateResult{UpdateType: "INSERT", RowCount: 3}, result)
+	assert.Equal(t, []string{
+		"EXECUTE _trino_go USING 1, 'a'",
+		"EXECUTE _trino_go USING 2, 'b'",
+		"EXECUTE _trino_go USING 3, 'c'",
+	}, queries)
+
+	result, err = ExecBatch(context.Background(), stmt, [][]interface{}{{4, "d"}, {5, struct{}{}}})
+	assert.Error(t, err)
+	assert.Equal(t, int64(1), result.RowCount)
+}
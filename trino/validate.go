@@ -0,0 +1,52 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+)
+
+// validatePrefix is followed by a new line, so that the lines of the
+// validated query are only shifted by one.
+const validatePrefix = "EXPLAIN (TYPE VALIDATE)\n"
+
+// ValidateQuery checks the syntax and semantics of a query, such as the
+// existence of the referenced tables and columns, without running it.
+//
+// An invalid query is reported as an *ErrQueryFailed, with an ErrorType of
+// USER_ERROR. Its Location, if any, is relative to the validated query.
+func ValidateQuery(ctx context.Context, q Queryer, query string) error {
+	rows, err := q.QueryContext(ctx, validatePrefix+query)
+	if err != nil {
+		return adjustValidationError(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+	}
+	return adjustValidationError(rows.Err())
+}
+
+func adjustValidationError(err error) error {
+	qferr, ok := err.(*ErrQueryFailed)
+	if !ok || qferr.Location == nil || qferr.Location.LineNumber <= 1 {
+		return err
+	}
+	adjusted := *qferr
+	adjusted.Location = &ErrorLocation{
+		LineNumber:   qferr.Location.LineNumber - 1,
+		ColumnNumber: qferr.Location.ColumnNumber,
+	}
+	return &adjusted
+}
@@ -0,0 +1,49 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateQuery(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"columns": [{"name": "Valid", "type": "boolean"}], "data": [[true]]`, &queries)
+	db := openTestDB(t, ts)
+
+	require.NoError(t, ValidateQuery(context.Background(), db, "SELECT 1"))
+	assert.Equal(t, []string{"EXPLAIN (TYPE VALIDATE)\nSELECT 1"}, queries)
+}
+
+func TestValidateQueryError(t *testing.T) {
+	ts := newResultTestServer(t, `"error": {
+		"message": "line 2:8: Column 'foo' cannot be resolved",
+		"errorCode": 47,
+		"errorName": "COLUMN_NOT_FOUND",
+		"errorType": "USER_ERROR",
+		"errorLocation": {"lineNumber": 2, "columnNumber": 8}
+	}`, nil)
+	db := openTestDB(t, ts)
+
+	err := ValidateQuery(context.Background(), db, "SELECT foo")
+	qferr, ok := err.(*ErrQueryFailed)
+	require.Truef(t, ok, "unexpected error: %v", err)
+	assert.Equal(t, "COLUMN_NOT_FOUND", qferr.ErrorName)
+	assert.Equal(t, &ErrorLocation{LineNumber: 1, ColumnNumber: 8}, qferr.Location)
+}
@@ -0,0 +1,114 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Checks of OpenAndVerify, reported by ErrVerification.
+const (
+	CheckNetwork        = "network"        // the server cannot be reached
+	CheckServer         = "server"         // the server is a worker, or is starting
+	CheckAuthentication = "authentication" // the credentials are rejected
+	CheckCatalog        = "catalog"        // the catalog does not exist
+	CheckSchema         = "schema"         // the schema does not exist
+	CheckQuery          = "query"          // the query checking the catalog and schema failed otherwise
+)
+
+// ErrVerification is returned by OpenAndVerify when a check fails.
+type ErrVerification struct {
+	Check string // One of the Check constants
+	Err   error
+}
+
+// Error implements the error interface.
+func (e *ErrVerification) Error() string {
+	return fmt.Sprintf("trino: %s check failed: %v", e.Check, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ErrVerification) Unwrap() error {
+	return e.Err
+}
+
+// OpenAndVerify opens a database with the configuration, like NewConnector
+// and sql.OpenDB, then checks that the server can be reached, that it is a
+// coordinator ready to run queries, that the credentials are accepted, and
+// that the catalog and schema of the configuration, if any, exist.
+//
+// Failures are reported as an *ErrVerification naming the failed check, so
+// that misconfigurations are reported at startup rather than by the first
+// query. The database is closed when a check fails.
+func OpenAndVerify(ctx context.Context, config *Config) (*sql.DB, error) {
+	connector, err := NewConnector(config)
+	if err != nil {
+		return nil, err
+	}
+	db := sql.OpenDB(connector)
+	if err := verify(ctx, db, config); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func verify(ctx context.Context, db *sql.DB, config *Config) error {
+	info, err := GetServerInfo(ctx, db)
+	if err != nil {
+		return &ErrVerification{Check: checkOf(err, CheckNetwork), Err: err}
+	}
+	if !info.Coordinator {
+		return &ErrVerification{Check: CheckServer, Err: &ErrNotCoordinator{URL: redactDSN(config.ServerURI)}}
+	}
+	if info.Starting {
+		return &ErrVerification{Check: CheckServer, Err: errors.New("the server is starting")}
+	}
+
+	// the info endpoint does not require authentication, queries do
+	query := "SELECT 1"
+	if config.Catalog != "" {
+		query = "SELECT count(*) FROM " + QuoteIdentifier(config.Catalog) + ".information_schema.schemata"
+		if config.Schema != "" {
+			query += " WHERE schema_name = " + QuoteLiteral(config.Schema)
+		}
+	}
+	var n int64
+	if err := db.QueryRowContext(ctx, query).Scan(&n); err != nil {
+		check := CheckQuery
+		if IsNotFound(err) {
+			check = CheckCatalog
+		}
+		return &ErrVerification{Check: checkOf(err, check), Err: err}
+	}
+	if config.Catalog != "" && config.Schema != "" && n == 0 {
+		return &ErrVerification{Check: CheckSchema, Err: fmt.Errorf("schema %s.%s not found", config.Catalog, config.Schema)}
+	}
+	return nil
+}
+
+// checkOf returns CheckAuthentication for errors of rejected credentials,
+// and check for the others.
+func checkOf(err error, check string) string {
+	var qerr *ErrQueryFailed
+	if errors.As(err, &qerr) && (qerr.StatusCode == http.StatusUnauthorized || qerr.StatusCode == http.StatusForbidden) {
+		return CheckAuthentication
+	}
+	return check
+}
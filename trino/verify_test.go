@@ -0,0 +1,77 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newVerifyTestServer(t *testing.T, coordinator string, status int, result string) *httptest.Server {
+	return newPagedResultTestServer(t, []string{result}, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path == "/v1/info" {
+			w.Write([]byte(`{"nodeVersion": {"version": "400"}, "coordinator": ` + coordinator + `, "starting": false}`))
+			return true
+		}
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return true
+		}
+		return false
+	})
+}
+
+func TestOpenAndVerify(t *testing.T) {
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	count := func(n string) string {
+		return `"columns": [{"name": "_col0", "type": "bigint"}], "data": [[` + n + `]]`
+	}
+	for _, tc := range []struct {
+		name   string
+		server string
+		check  string
+	}{
+		{"ok", newVerifyTestServer(t, "true", http.StatusOK, count("1")).URL, ""},
+		{"unreachable", closed.URL, CheckNetwork},
+		{"worker", newVerifyTestServer(t, "false", http.StatusOK, count("1")).URL, CheckServer},
+		{"unauthorized", newVerifyTestServer(t, "true", http.StatusUnauthorized, "").URL, CheckAuthentication},
+		{"catalog", newVerifyTestServer(t, "true", http.StatusOK, `"error": {"errorName": "CATALOG_NOT_FOUND", "errorType": "USER_ERROR", "message": "Catalog 'hive' does not exist"}`).URL, CheckCatalog},
+		{"schema", newVerifyTestServer(t, "true", http.StatusOK, count("0")).URL, CheckSchema},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := OpenAndVerify(context.Background(), &Config{
+				ServerURI: tc.server,
+				Catalog:   "hive",
+				Schema:    "web",
+			})
+			if tc.check == "" {
+				require.NoError(t, err)
+				assert.NoError(t, db.Close())
+				return
+			}
+			var verr *ErrVerification
+			require.True(t, errors.As(err, &verr), "unexpected error: %v", err)
+			assert.Equal(t, tc.check, verr.Check)
+			assert.Nil(t, db)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"unsafe"
+)
+
+type zeroCopyStringsKey struct{}
+
+// WithZeroCopyStrings returns a context that makes queries executed with it
+// return the values of string columns, such as VARCHAR, as []byte pointing
+// into the data of the result page they were received in, instead of
+// allocating a string for each of them. Strings holding escape sequences in
+// the JSON of the page are still decoded into a new buffer.
+//
+// This is only useful when scanning into sql.RawBytes, which database/sql
+// does not copy either, and is unsafe: the scanned bytes must never be
+// modified. The option is ignored when Config.ResultCache is set, since
+// the rows of the page are then shared with the cache.
+func WithZeroCopyStrings(ctx context.Context) context.Context {
+	return context.WithValue(ctx, zeroCopyStringsKey{}, true)
+}
+
+func zeroCopyStringsFromContext(ctx context.Context) bool {
+	v, _ := ctx.Value(zeroCopyStringsKey{}).(bool)
+	return v
+}
+
+// zeroCopyStringsEnabled reports whether strings should be returned without
+// copying them out of the result pages.
+func (qr *driverRows) zeroCopyStringsEnabled() bool {
+	return qr.stmt.conn.resultCache == nil && zeroCopyStringsFromContext(qr.ctx)
+}
+
+// unsafeBytes returns the bytes of s without copying them.
+// The returned slice must not be modified.
+func unsafeBytes(s string) []byte {
+	if s == "" {
+		return []byte{}
+	}
+	return *(*[]byte)(unsafe.Pointer(&struct {
+		string
+		int
+	}{s, len(s)}))
+}
+
+// unsafeString returns b as a string without copying it.
+// b must not be modified afterwards.
+func unsafeString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return *(*string)(unsafe.Pointer(&b))
+}
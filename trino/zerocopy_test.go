@@ -0,0 +1,102 @@
+// Copyright (c) Facebook, Inc. and its affiliates. All Rights Reserved
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZeroCopyStrings(t *testing.T) {
+	ts := newResultTestServer(t, `
+		"columns": [{"name": "s", "type": "varchar"}, {"name": "n", "type": "integer"}],
+		"data": [["hello", 1], ["", 2]]`, nil)
+
+	db, err := sql.Open("trino", ts.URL)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	rows, err := db.QueryContext(WithZeroCopyStrings(context.Background()), "SELECT 1")
+	require.NoError(t, err)
+	var values []string
+	for rows.Next() {
+		var s sql.RawBytes
+		var n int64
+		require.NoError(t, rows.Scan(&s, &n))
+		values = append(values, string(s))
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{"hello", ""}, values)
+}
+
+func TestZeroCopyStringsAliasPage(t *testing.T) {
+	qr := &driverRows{
+		ctx:  WithZeroCopyStrings(context.Background()),
+		stmt: &driverStmt{conn: &Conn{}},
+	}
+	page := []byte(`[["hello", "a\tb"]]`)
+	data, err := qr.decodeRows(page, []string{"s", "e"})
+	require.NoError(t, err)
+	page[3] = 'j'
+	page[13] = 'x'
+	assert.Equal(t, []queryData{{"jello", "a\tb"}}, data)
+}
+
+func TestZeroCopyStringsResultCache(t *testing.T) {
+	var queries []string
+	ts := newResultTestServer(t, `"columns": [{"name": "s", "type": "varchar"}], "data": [["hello"]]`, &queries)
+	connector, err := NewConnector(&Config{
+		ServerURI:   ts.URL,
+		ResultCache: NewMemoryResultCache(10, time.Minute),
+	})
+	require.NoError(t, err)
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	ctx := WithZeroCopyStrings(context.Background())
+	for i := 0; i < 2; i++ {
+		rows, err := db.QueryContext(ctx, "SELECT s FROM t")
+		require.NoError(t, err)
+		for rows.Next() {
+			var s sql.RawBytes
+			require.NoError(t, rows.Scan(&s))
+			assert.Equal(t, "hello", string(s))
+			s[0] = 'j'
+		}
+		require.NoError(t, rows.Err())
+		require.NoError(t, rows.Close())
+	}
+	assert.Len(t, queries, 1)
+}
+
+func TestUnsafeBytes(t *testing.T) {
+	assert.Equal(t, []byte("hello"), unsafeBytes("hello"))
+	assert.Equal(t, []byte{}, unsafeBytes(""))
+}
+
+func TestUnsafeString(t *testing.T) {
+	assert.Equal(t, "hello", unsafeString([]byte("hello")))
+	assert.Equal(t, "", unsafeString(nil))
+}